@@ -0,0 +1,439 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package greptime
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/schema"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+)
+
+var (
+	defaultBatchMaxRows       = 5000
+	defaultBatchMaxBytes      = 4 * 1024 * 1024
+	defaultBatchFlushInterval = time.Second
+	defaultBatchQueueSize     = 16
+)
+
+// BatchResult is the outcome of one flush of the BatchWriter.
+type BatchResult struct {
+	Tables   []string // names of the tables in this flush
+	Rows     int      // number of rows in this flush
+	Response *gpb.GreptimeResponse
+	Err      error
+}
+
+// BatchConfig is to define how the BatchWriter buffers and flushes the data.
+//
+//   - MaxRows triggers a flush once the buffered rows reach it. 0 disables it.
+//   - MaxBytes triggers a flush once the buffered rows reach the size. 0 disables it.
+//   - FlushInterval triggers a flush periodically. 0 disables it.
+//   - QueueSize is how many flushes can be pending before Write blocks.
+//   - Callback is called with the result of every flush, see WithCallback.
+type BatchConfig struct {
+	MaxRows       int
+	MaxBytes      int
+	FlushInterval time.Duration
+	QueueSize     int
+	Callback      func(*BatchResult)
+}
+
+// NewBatchConfig helps to init BatchConfig with default values.
+func NewBatchConfig() *BatchConfig {
+	return &BatchConfig{
+		MaxRows:       defaultBatchMaxRows,
+		MaxBytes:      defaultBatchMaxBytes,
+		FlushInterval: defaultBatchFlushInterval,
+		QueueSize:     defaultBatchQueueSize,
+	}
+}
+
+// WithMaxRows set the MaxRows field.
+func (c *BatchConfig) WithMaxRows(rows int) *BatchConfig {
+	c.MaxRows = rows
+	return c
+}
+
+// WithMaxBytes set the MaxBytes field.
+func (c *BatchConfig) WithMaxBytes(bytes int) *BatchConfig {
+	c.MaxBytes = bytes
+	return c
+}
+
+// WithFlushInterval set the FlushInterval field.
+func (c *BatchConfig) WithFlushInterval(interval time.Duration) *BatchConfig {
+	c.FlushInterval = interval
+	return c
+}
+
+// WithQueueSize set the QueueSize field.
+func (c *BatchConfig) WithQueueSize(size int) *BatchConfig {
+	c.QueueSize = size
+	return c
+}
+
+// WithCallback set the Callback field. The callback is called in order from its own
+// goroutine, which does not block the writes, so it may call the methods of the same
+// BatchWriter. Close does not wait for the callbacks, the error of the last flush is
+// returned by Close.
+func (c *BatchConfig) WithCallback(callback func(*BatchResult)) *BatchConfig {
+	c.Callback = callback
+	return c
+}
+
+type batch struct {
+	tables []*table.Table
+	rows   int
+
+	// done is closed once the batch is sent, only set by Flush.
+	done chan struct{}
+	err  error
+}
+
+// BatchWriter buffers the data from many goroutines, merges rows of the same table
+// and writes them into GreptimeDB in the background. A BatchWriter is safe for
+// concurrent use by multiple goroutines.
+//
+// The tables passed to Write MUST NOT be modified afterwards.
+type BatchWriter struct {
+	client *Client
+	cfg    *BatchConfig
+	ctx    context.Context
+
+	mu      sync.Mutex
+	buffers map[string]*table.Table
+	names   []string
+	rows    int
+	bytes   int
+	closed  bool
+
+	// sendMu guards batches from being closed while sending
+	sendMu      sync.RWMutex
+	queueClosed bool
+	batches     chan *batch
+	done        chan struct{}
+
+	// the results to be passed to Callback, which are not bounded by the queue
+	resultsMu     sync.Mutex
+	results       []*BatchResult
+	resultsClosed bool
+	resultReady   chan struct{}
+}
+
+// NewBatchWriter creates a BatchWriter on top of the client. ctx is used for all the
+// background writes, it can carry hints, and cancelling it aborts the pending writes.
+// Call Close to flush the buffered data when the BatchWriter is no longer needed.
+//
+//	writer := client.NewBatchWriter(context.Background(), greptime.NewBatchConfig().
+//		WithMaxRows(1000).
+//		WithCallback(func(result *greptime.BatchResult) {
+//			if result.Err != nil {
+//				log.Printf("failed to write %d rows: %v", result.Rows, result.Err)
+//			}
+//		}))
+//	defer writer.Close(context.Background())
+//
+//	err := writer.Write(context.Background(), tbl)
+func (c *Client) NewBatchWriter(ctx context.Context, cfg *BatchConfig) *BatchWriter {
+	if cfg == nil {
+		cfg = NewBatchConfig()
+	}
+
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultBatchQueueSize
+	}
+
+	w := &BatchWriter{
+		client:      c,
+		cfg:         cfg,
+		ctx:         ctx,
+		buffers:     make(map[string]*table.Table),
+		batches:     make(chan *batch, queueSize),
+		done:        make(chan struct{}),
+		resultReady: make(chan struct{}, 1),
+	}
+	go w.run()
+	if cfg.Callback != nil {
+		go w.callback()
+	}
+	return w
+}
+
+// Write buffers the tables. It only blocks when too many flushes are pending, and
+// ctx bounds that waiting. Rows of the same table name are merged by column name.
+func (w *BatchWriter) Write(ctx context.Context, tables ...*table.Table) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return errs.ErrBatchWriterClosed
+	}
+
+	for _, tbl := range tables {
+		if err := w.merge(tbl); err != nil {
+			w.mu.Unlock()
+			return err
+		}
+	}
+
+	var b *batch
+	if (w.cfg.MaxRows > 0 && w.rows >= w.cfg.MaxRows) ||
+		(w.cfg.MaxBytes > 0 && w.bytes >= w.cfg.MaxBytes) {
+		b = w.cut()
+	}
+	w.mu.Unlock()
+
+	if b == nil {
+		return nil
+	}
+	return w.enqueue(ctx, b)
+}
+
+// WriteObject is like [Write] to buffer the data, but schema is defined in the struct tag.
+func (w *BatchWriter) WriteObject(ctx context.Context, obj any) error {
+	tbl, err := schema.Parse(obj)
+	if err != nil {
+		return err
+	}
+	return w.Write(ctx, tbl)
+}
+
+// Flush sends the buffered data and waits until it and all the pending flushes
+// before it are written. It returns the error of writing the buffered data.
+func (w *BatchWriter) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return errs.ErrBatchWriterClosed
+	}
+	b := w.cut()
+	w.mu.Unlock()
+
+	return w.flush(ctx, b)
+}
+
+// Close flushes the buffered data, waits for all the pending flushes, and stops the
+// background goroutine. Write can not be called after Close.
+func (w *BatchWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return errs.ErrBatchWriterClosed
+	}
+	w.closed = true
+	b := w.cut()
+	w.mu.Unlock()
+
+	err := w.flush(ctx, b)
+
+	w.sendMu.Lock()
+	w.queueClosed = true
+	close(w.batches)
+	w.sendMu.Unlock()
+
+	select {
+	case <-w.done:
+	case <-ctx.Done():
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+	return err
+}
+
+func (w *BatchWriter) flush(ctx context.Context, b *batch) error {
+	if b == nil {
+		b = &batch{}
+	}
+	b.done = make(chan struct{})
+
+	if err := w.enqueue(ctx, b); err != nil {
+		return err
+	}
+
+	select {
+	case <-b.done:
+		return b.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *BatchWriter) enqueue(ctx context.Context, b *batch) error {
+	w.sendMu.RLock()
+	defer w.sendMu.RUnlock()
+
+	if w.queueClosed {
+		return errs.ErrBatchWriterClosed
+	}
+
+	select {
+	case w.batches <- b:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// merge must be called with mu held.
+func (w *BatchWriter) merge(tbl *table.Table) error {
+	if tbl == nil || tbl.IsRowEmpty() {
+		return nil
+	}
+
+	name, err := tbl.GetName()
+	if err != nil {
+		return err
+	}
+
+	buffer, ok := w.buffers[name]
+	if !ok {
+		buffer, err = table.New(name)
+		if err != nil {
+			return err
+		}
+		buffer.WithSanitate(false)
+		w.buffers[name] = buffer
+		w.names = append(w.names, name)
+	}
+
+	if err := buffer.Merge(tbl); err != nil {
+		return err
+	}
+
+	w.rows += tbl.RowCount()
+	w.bytes += proto.Size(tbl.GetRows())
+	return nil
+}
+
+// cut takes the buffered data as a batch, it must be called with mu held.
+func (w *BatchWriter) cut() *batch {
+	if w.rows == 0 {
+		return nil
+	}
+
+	tables := make([]*table.Table, 0, len(w.names))
+	for _, name := range w.names {
+		tables = append(tables, w.buffers[name])
+	}
+	b := &batch{tables: tables, rows: w.rows}
+
+	w.buffers = make(map[string]*table.Table, len(w.names))
+	w.names = nil
+	w.rows = 0
+	w.bytes = 0
+	return b
+}
+
+func (w *BatchWriter) run() {
+	defer close(w.done)
+	defer w.closeResults()
+
+	var tick <-chan time.Time
+	if w.cfg.FlushInterval > 0 {
+		ticker := time.NewTicker(w.cfg.FlushInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case b, ok := <-w.batches:
+			if !ok {
+				return
+			}
+			w.send(b)
+		case <-tick:
+			w.mu.Lock()
+			b := w.cut()
+			w.mu.Unlock()
+			if b != nil {
+				w.send(b)
+			}
+		}
+	}
+}
+
+func (w *BatchWriter) send(b *batch) {
+	if len(b.tables) > 0 {
+		resp, err := w.client.Write(w.ctx, b.tables...)
+		b.err = err
+
+		if w.cfg.Callback != nil {
+			names := make([]string, 0, len(b.tables))
+			for _, tbl := range b.tables {
+				name, _ := tbl.GetName()
+				names = append(names, name)
+			}
+			w.pushResult(&BatchResult{Tables: names, Rows: b.rows, Response: resp, Err: err})
+		}
+	}
+
+	if b.done != nil {
+		close(b.done)
+	}
+}
+
+func (w *BatchWriter) pushResult(result *BatchResult) {
+	w.resultsMu.Lock()
+	w.results = append(w.results, result)
+	w.resultsMu.Unlock()
+	w.notifyResults()
+}
+
+func (w *BatchWriter) closeResults() {
+	w.resultsMu.Lock()
+	w.resultsClosed = true
+	w.resultsMu.Unlock()
+	w.notifyResults()
+}
+
+func (w *BatchWriter) notifyResults() {
+	select {
+	case w.resultReady <- struct{}{}:
+	default:
+	}
+}
+
+// callback calls Callback with the results in order, until the background
+// goroutine is stopped and all the results are passed.
+func (w *BatchWriter) callback() {
+	for {
+		w.resultsMu.Lock()
+		results, closed := w.results, w.resultsClosed
+		w.results = nil
+		w.resultsMu.Unlock()
+
+		if len(results) == 0 {
+			if closed {
+				return
+			}
+			<-w.resultReady
+			continue
+		}
+		for _, result := range results {
+			w.cfg.Callback(result)
+		}
+	}
+}
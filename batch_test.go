@@ -0,0 +1,242 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package greptime
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+func TestBatchWriteObjMonitors(t *testing.T) {
+	loc, err := time.LoadLocation(timezone)
+	assert.Nil(t, err)
+
+	monitors := make([]monitor, 0, 10)
+	for i := 0; i < 10; i++ {
+		ts := time.Now().Add(-time.Duration(i) * time.Minute).UnixMilli()
+		monitors = append(monitors, monitor{
+			ID:          randomId(),
+			Host:        fmt.Sprintf("127.0.0.%d", i),
+			Memory:      uint64(i),
+			Cpu:         float64(i),
+			Temperature: int64(-i),
+			Ts:          time.UnixMilli(ts).In(loc),
+			Running:     true,
+		})
+	}
+
+	var mu sync.Mutex
+	results := make([]*BatchResult, 0)
+	cfg := NewBatchConfig().
+		WithMaxRows(4).
+		WithFlushInterval(0).
+		WithCallback(func(result *BatchResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			results = append(results, result)
+		})
+
+	writer := cli.NewBatchWriter(context.Background(), cfg)
+
+	var wg sync.WaitGroup
+	for _, m := range monitors {
+		wg.Add(1)
+		go func(m monitor) {
+			defer wg.Done()
+			assert.Nil(t, writer.WriteObject(context.Background(), m))
+		}(m)
+	}
+	wg.Wait()
+
+	assert.Nil(t, writer.Close(context.Background()))
+	assert.ErrorIs(t, writer.WriteObject(context.Background(), monitors[0]), errs.ErrBatchWriterClosed)
+
+	// the callbacks are not waited by Close
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		rows := 0
+		for _, result := range results {
+			rows += result.Rows
+		}
+		return rows == len(monitors)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, result := range results {
+		assert.Nil(t, result.Err)
+		assert.Equal(t, []string{monitorTableName}, result.Tables)
+		assert.EqualValues(t, result.Rows, result.Response.GetAffectedRows().GetValue())
+	}
+
+	monitors_, err := db.Query(fmt.Sprintf("select * from %s where id in %s", monitorTableName, getMonitorsIds(monitors)))
+	assert.Nil(t, err)
+	assert.Equal(t, len(monitors), len(monitors_))
+}
+
+// fakeBatchClient records the inserts of every request.
+type fakeBatchClient struct {
+	gpb.GreptimeDatabaseClient
+
+	mu       sync.Mutex
+	requests [][]*gpb.RowInsertRequest
+}
+
+func (c *fakeBatchClient) Handle(_ context.Context, req *gpb.GreptimeRequest, _ ...grpc.CallOption) (*gpb.GreptimeResponse, error) {
+	inserts := req.GetRowInserts().GetInserts()
+	c.mu.Lock()
+	c.requests = append(c.requests, inserts)
+	c.mu.Unlock()
+
+	rows := 0
+	for _, insert := range inserts {
+		rows += len(insert.GetRows().GetRows())
+	}
+	affected := &gpb.AffectedRows{Value: uint32(rows)}
+	return &gpb.GreptimeResponse{Response: &gpb.GreptimeResponse_AffectedRows{AffectedRows: affected}}, nil
+}
+
+func (c *fakeBatchClient) Requests() [][]*gpb.RowInsertRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][]*gpb.RowInsertRequest(nil), c.requests...)
+}
+
+func newBatchWriter(cfg *BatchConfig) (*BatchWriter, *fakeBatchClient) {
+	fake := &fakeBatchClient{}
+	client := &Client{cfg: NewConfig("127.0.0.1").WithDatabase("public"), client: fake}
+	return client.NewBatchWriter(context.Background(), cfg), fake
+}
+
+func TestBatchWriterMaxRows(t *testing.T) {
+	writer, fake := newBatchWriter(NewBatchConfig().WithMaxRows(3).WithFlushInterval(0))
+	ctx := context.Background()
+
+	assert.Nil(t, writer.Write(ctx, newStreamTable(t, 2)))
+	assert.Nil(t, writer.Flush(ctx))
+	assert.Len(t, fake.Requests(), 1)
+
+	// the rows reach MaxRows without Flush
+	assert.Nil(t, writer.Write(ctx, newStreamTable(t, 2)))
+	assert.Nil(t, writer.Write(ctx, newStreamTable(t, 1)))
+	assert.Eventually(t, func() bool { return len(fake.Requests()) == 2 }, 5*time.Second, time.Millisecond)
+	assert.Len(t, fake.Requests()[1][0].GetRows().GetRows(), 3)
+
+	// nothing is buffered
+	assert.Nil(t, writer.Close(ctx))
+	assert.Len(t, fake.Requests(), 2)
+}
+
+func TestBatchWriterMaxBytes(t *testing.T) {
+	writer, fake := newBatchWriter(NewBatchConfig().WithMaxRows(0).WithMaxBytes(1).WithFlushInterval(0))
+	ctx := context.Background()
+
+	assert.Nil(t, writer.Write(ctx, newStreamTable(t, 1)))
+	assert.Eventually(t, func() bool { return len(fake.Requests()) == 1 }, 5*time.Second, time.Millisecond)
+	assert.Nil(t, writer.Close(ctx))
+}
+
+func TestBatchWriterFlushInterval(t *testing.T) {
+	writer, fake := newBatchWriter(NewBatchConfig().WithMaxRows(0).WithMaxBytes(0).WithFlushInterval(10 * time.Millisecond))
+	ctx := context.Background()
+
+	assert.Nil(t, writer.Write(ctx, newStreamTable(t, 1)))
+	assert.Nil(t, writer.Write(ctx, newStreamTable(t, 2)))
+	assert.Eventually(t, func() bool { return len(fake.Requests()) == 1 }, 5*time.Second, time.Millisecond)
+	assert.Len(t, fake.Requests()[0][0].GetRows().GetRows(), 3)
+	assert.Nil(t, writer.Close(ctx))
+}
+
+func TestBatchWriterMerge(t *testing.T) {
+	writer, fake := newBatchWriter(NewBatchConfig().WithMaxRows(0).WithFlushInterval(0))
+	ctx := context.Background()
+
+	memory, err := table.New("monitor")
+	assert.Nil(t, err)
+	assert.Nil(t, memory.AddTimestampColumn("ts", types.TIMESTAMP_MILLISECOND))
+	assert.Nil(t, memory.AddFieldColumn("memory", types.UINT64))
+	assert.Nil(t, memory.AddRow(int64(3), uint64(1)))
+
+	assert.Nil(t, writer.Write(ctx, newStreamTable(t, 2), newStreamTable(t, 1)))
+	assert.Nil(t, writer.Write(ctx, memory))
+	assert.Nil(t, writer.Flush(ctx))
+
+	requests := fake.Requests()
+	assert.Len(t, requests, 1)
+	assert.Len(t, requests[0], 1)
+
+	insert := requests[0][0]
+	assert.Equal(t, "monitor", insert.GetTableName())
+	columns := make([]string, 0)
+	for _, column := range insert.GetRows().GetSchema() {
+		columns = append(columns, column.GetColumnName())
+	}
+	assert.Equal(t, []string{"cpu", "ts", "memory"}, columns)
+
+	rows := insert.GetRows().GetRows()
+	assert.Len(t, rows, 4)
+	assert.Nil(t, rows[0].GetValues()[2].GetValueData())
+	assert.Nil(t, rows[3].GetValues()[0].GetValueData())
+	assert.Equal(t, uint64(1), rows[3].GetValues()[2].GetU64Value())
+	assert.Nil(t, writer.Close(ctx))
+}
+
+func TestBatchWriterCallbackReentry(t *testing.T) {
+	ctx := context.Background()
+	done := make(chan struct{})
+
+	var writer *BatchWriter
+	var fake *fakeBatchClient
+	var results []*BatchResult
+	writer, fake = newBatchWriter(NewBatchConfig().WithMaxRows(1).WithFlushInterval(0).WithQueueSize(1).
+		WithCallback(func(result *BatchResult) {
+			results = append(results, result)
+			if len(results) > 1 {
+				return
+			}
+
+			// the callback does not block the writes of the same writer
+			assert.Nil(t, writer.Write(ctx, newStreamTable(t, 2)))
+			assert.Nil(t, writer.Write(ctx, newStreamTable(t, 3)))
+			assert.Nil(t, writer.Flush(ctx))
+			assert.Nil(t, writer.Close(ctx))
+			close(done)
+		}))
+
+	assert.Nil(t, writer.Write(ctx, newStreamTable(t, 1)))
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback is blocked by the writer")
+	}
+
+	assert.Len(t, fake.Requests(), 3)
+	assert.ErrorIs(t, writer.Write(ctx, newStreamTable(t, 1)), errs.ErrBatchWriterClosed)
+}
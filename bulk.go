@@ -0,0 +1,234 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package greptime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/request/bulk"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+)
+
+// BulkStream writes the rows of one table into GreptimeDB via the DoPut endpoint of
+// Arrow Flight. Every Write is sent as one Arrow record batch, which is much cheaper
+// to encode and decode than the row based requests of [Client.Write] for large batches.
+//
+// The table MUST exist before writing, and all the tables written into the same
+// BulkStream MUST have the same columns. A BulkStream is NOT safe for concurrent use.
+type BulkStream struct {
+	table  string
+	cancel context.CancelFunc
+	stream flight.FlightService_DoPutClient
+	mem    memory.Allocator
+
+	writer *flight.Writer
+	schema *arrow.Schema
+	nextID int64
+
+	// affectedRows and err are set by the receiving goroutine before done is closed
+	done         chan struct{}
+	affectedRows uint64
+	err          error
+}
+
+// NewBulkStream opens a DoPut stream to write the rows into the table.
+// Call Close to wait for all the rows to be acknowledged.
+//
+//	stream, err := client.NewBulkStream(context.Background(), "monitor")
+//
+//	err = stream.Write(tbl1)
+//	err = stream.Write(tbl2)
+//
+//	affectedRows, err := stream.Close()
+func (c *Client) NewBulkStream(ctx context.Context, tableName string) (*BulkStream, error) {
+	if tableName == "" {
+		return nil, errs.ErrEmptyTableName
+	}
+
+	md, err := c.newHeader().BuildMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	// keep the metadata of ctx, like the hints
+	if outgoing, ok := metadata.FromOutgoingContext(ctx); ok {
+		for key, values := range md {
+			outgoing[key] = values
+		}
+		md = outgoing
+	}
+	ctx, cancel := context.WithCancel(metadata.NewOutgoingContext(ctx, md))
+	stream, err := c.flightClient.DoPut(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	s := &BulkStream{
+		table:  tableName,
+		cancel: cancel,
+		stream: stream,
+		mem:    memory.DefaultAllocator,
+		nextID: 1,
+		done:   make(chan struct{}),
+	}
+	go s.recv()
+	return s, nil
+}
+
+// Write sends the rows of the table as one record batch.
+func (s *BulkStream) Write(tbl *table.Table) error {
+	schema, err := bulk.Schema(tbl.GetColumnsSchema())
+	if err != nil {
+		return err
+	}
+
+	if s.writer == nil {
+		s.schema = schema
+		s.writer = flight.NewRecordWriter(s.stream, ipc.WithSchema(schema), ipc.WithAllocator(s.mem))
+		s.writer.SetFlightDescriptor(&flight.FlightDescriptor{
+			Type: flight.DescriptorPATH,
+			Path: []string{s.table},
+		})
+	} else if !schema.Equal(s.schema) {
+		return fmt.Errorf("columns of the table do not match the bulk stream of %q", s.table)
+	}
+
+	record, err := bulk.NewRecord(s.mem, schema, tbl)
+	if err != nil {
+		return err
+	}
+	defer record.Release()
+
+	appMetadata, err := bulk.BuildMetadata(s.nextID)
+	if err != nil {
+		return err
+	}
+	s.nextID++
+
+	if err := s.writer.WriteWithAppMetadata(record, appMetadata); err != nil {
+		// the real error of a broken stream is returned by Recv
+		if errors.Is(err, io.EOF) {
+			<-s.done
+			if s.err != nil {
+				return s.err
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// Close closes the sending side of the stream, and waits for GreptimeDB to
+// acknowledge the record batches. It returns the total affected rows.
+func (s *BulkStream) Close() (uint64, error) {
+	defer s.cancel()
+
+	var err error
+	if s.writer != nil {
+		err = s.writer.Close()
+	}
+	if closeErr := s.stream.CloseSend(); err == nil {
+		err = closeErr
+	}
+
+	<-s.done
+	if s.err != nil {
+		return s.affectedRows, s.err
+	}
+	return s.affectedRows, err
+}
+
+func (s *BulkStream) recv() {
+	defer close(s.done)
+
+	for {
+		putResult, err := s.stream.Recv()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				s.err = err
+			}
+			return
+		}
+
+		result, err := bulk.ParseResult(putResult.GetAppMetadata())
+		if err != nil {
+			s.err = err
+			return
+		}
+		s.affectedRows += result.AffectedRows
+	}
+}
+
+// BulkWrite writes the tables into GreptimeDB via the DoPut endpoint of Arrow Flight,
+// the tables of the same name are sent in the same stream. It is preferred over
+// [Client.Write] for high-volume writes, like backfills. The tables MUST exist.
+//
+//	affectedRows, err := client.BulkWrite(context.Background(), tbl)
+func (c *Client) BulkWrite(ctx context.Context, tables ...*table.Table) (uint64, error) {
+	if len(tables) == 0 {
+		return 0, errs.ErrEmptyTable
+	}
+
+	names := make([]string, 0, len(tables))
+	groups := make(map[string][]*table.Table)
+	for _, tbl := range tables {
+		name, err := tbl.GetName()
+		if err != nil {
+			return 0, err
+		}
+		if _, ok := groups[name]; !ok {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], tbl)
+	}
+
+	var affectedRows uint64
+	for _, name := range names {
+		rows, err := c.bulkWrite(ctx, name, groups[name])
+		affectedRows += rows
+		if err != nil {
+			return affectedRows, err
+		}
+	}
+	return affectedRows, nil
+}
+
+func (c *Client) bulkWrite(ctx context.Context, name string, tables []*table.Table) (uint64, error) {
+	stream, err := c.NewBulkStream(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, tbl := range tables {
+		if err := stream.Write(tbl); err != nil {
+			affectedRows, _ := stream.Close()
+			return affectedRows, err
+		}
+	}
+	return stream.Close()
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package greptime
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+func newMonitorTable(t testing.TB, tableName string, monitors []monitor) *table.Table {
+	tbl, err := table.New(tableName)
+	assert.Nil(t, err)
+
+	assert.Nil(t, tbl.AddTagColumn("id", types.INT64))
+	assert.Nil(t, tbl.AddTagColumn("host", types.STRING))
+	assert.Nil(t, tbl.AddFieldColumn("memory", types.UINT64))
+	assert.Nil(t, tbl.AddFieldColumn("cpu", types.FLOAT64))
+	assert.Nil(t, tbl.AddFieldColumn("temperature", types.INT64))
+	assert.Nil(t, tbl.AddFieldColumn("running", types.BOOLEAN))
+	assert.Nil(t, tbl.AddTimestampColumn("ts", types.TIMESTAMP_MILLISECOND))
+
+	for _, m := range monitors {
+		assert.Nil(t, tbl.AddRow(m.ID, m.Host, m.Memory, m.Cpu, m.Temperature, m.Running, m.Ts))
+	}
+	return tbl
+}
+
+func newMonitors(n int) []monitor {
+	now := time.Now()
+	monitors := make([]monitor, 0, n)
+	for i := 0; i < n; i++ {
+		monitors = append(monitors, monitor{
+			ID:          randomId(),
+			Host:        fmt.Sprintf("127.0.0.%d", i%255),
+			Memory:      uint64(i),
+			Cpu:         float64(i),
+			Temperature: int64(-i),
+			Running:     i%2 == 0,
+			Ts:          time.UnixMilli(now.Add(-time.Duration(i) * time.Millisecond).UnixMilli()),
+		})
+	}
+	return monitors
+}
+
+func TestBulkWrite(t *testing.T) {
+	loc, err := time.LoadLocation(timezone)
+	assert.Nil(t, err)
+
+	monitors := newMonitors(10)
+	for i := range monitors {
+		monitors[i].Ts = monitors[i].Ts.In(loc)
+	}
+
+	// bulk write requires the table to exist
+	_, err = cli.WriteObject(context.Background(), monitors[:1])
+	assert.Nil(t, err)
+
+	affected, err := cli.BulkWrite(context.Background(), newMonitorTable(t, monitorTableName, monitors[1:5]),
+		newMonitorTable(t, monitorTableName, monitors[5:]))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(len(monitors)-1), affected)
+
+	monitors_, err := db.Query(fmt.Sprintf("select * from %s where id in %s order by ts desc", monitorTableName, getMonitorsIds(monitors)))
+	assert.Nil(t, err)
+	assert.Equal(t, monitors, monitors_)
+}
@@ -18,8 +18,12 @@ package greptime
 
 import (
 	"context"
+	"fmt"
+	"sync"
 
 	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"go.opentelemetry.io/otel/metric"
 	"google.golang.org/grpc"
 
 	"github.com/GreptimeTeam/greptimedb-ingester-go/request"
@@ -31,17 +35,32 @@ import (
 
 // Client helps to write data into GreptimeDB. A Client is safe for concurrent
 // use by multiple goroutines,you can have one Client instance in your application.
+//
+// The stream methods of Client share one stream among all goroutines, see [Client.CloseStream].
 type Client struct {
 	cfg               *Config
 	conn              *grpc.ClientConn
 	client            gpb.GreptimeDatabaseClient
-	stream            gpb.GreptimeDatabase_HandleRequestsClient
 	healthCheckClient gpb.HealthCheckClient
+	flightClient      flight.FlightServiceClient
+	promClient        gpb.PrometheusGatewayClient
+
+	// streamMu guards stream, which is shared by all the goroutines calling StreamWrite,
+	// StreamDelete, StreamWriteObject, StreamDeleteObject and CloseStream.
+	streamMu sync.Mutex
+	stream   gpb.GreptimeDatabase_HandleRequestsClient
+
+	retries metric.Int64Counter
 }
 
 // NewClient helps to create the greptimedb client, which will be responsible write data into GreptimeDB.
 func NewClient(cfg *Config) (*Client, error) {
-	conn, err := grpc.NewClient(cfg.endpoint(), cfg.build()...)
+	opts, err := cfg.build()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.NewClient(cfg.endpoint(), opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -54,6 +73,9 @@ func NewClient(cfg *Config) (*Client, error) {
 		client:            client,
 		conn:              conn,
 		healthCheckClient: healthCheckClient,
+		flightClient:      flight.NewFlightServiceClient(conn),
+		promClient:        gpb.NewPrometheusGatewayClient(conn),
+		retries:           newRetriesCounter(cfg.telemetry.Meter(meterName)),
 	}, nil
 }
 
@@ -62,12 +84,19 @@ func NewClient(cfg *Config) (*Client, error) {
 //   - INSERT
 //   - DELETE
 func (c *Client) submit(ctx context.Context, operation types.Operation, tables ...*table.Table) (*gpb.GreptimeResponse, error) {
-	header_ := header.New(c.cfg.Database).WithAuth(c.cfg.Username, c.cfg.Password)
-	request_, err := request.New(header_, operation, tables...).Build()
+	request_, err := c.buildRequest(operation, tables...)
 	if err != nil {
 		return nil, err
 	}
-	return c.client.Handle(ctx, request_)
+	return c.handleWithRetry(ctx, request_)
+}
+
+func (c *Client) buildRequest(operation types.Operation, tables ...*table.Table) (*gpb.GreptimeRequest, error) {
+	return request.New(c.newHeader(), operation, tables...).Build()
+}
+
+func (c *Client) newHeader() *header.Header {
+	return header.New(c.cfg.Database).WithAuth(c.cfg.Username, c.cfg.Password)
 }
 
 // Write is to write the data into GreptimeDB via explicit schema.
@@ -168,7 +197,21 @@ func (c *Client) DeleteObject(ctx context.Context, obj any) (*gpb.GreptimeRespon
 // The operations can be set:
 //   - INSERT
 //   - DELETE
+//
+// The stream is opened by the first call with its ctx, and shared by all the
+// goroutines until CloseStream is called. Requests are sent one at a time.
+// If the stream is broken, its error is returned and the next call opens a new one.
+//
+// Use [Client.NewStreamWriter] to have a stream with its own context and reopen.
 func (c *Client) streamSubmit(ctx context.Context, operation types.Operation, tables ...*table.Table) error {
+	request_, err := c.buildRequest(operation, tables...)
+	if err != nil {
+		return err
+	}
+
+	c.streamMu.Lock()
+	defer c.streamMu.Unlock()
+
 	if c.stream == nil {
 		stream, err := c.client.HandleRequests(ctx)
 		if err != nil {
@@ -177,12 +220,16 @@ func (c *Client) streamSubmit(ctx context.Context, operation types.Operation, ta
 		c.stream = stream
 	}
 
-	header_ := header.New(c.cfg.Database).WithAuth(c.cfg.Username, c.cfg.Password)
-	request_, err := request.New(header_, operation, tables...).Build()
-	if err != nil {
+	if err := c.stream.Send(request_); err != nil {
+		// the real error of a broken stream is returned by CloseAndRecv
+		stream := c.stream
+		c.stream = nil
+		if _, closeErr := stream.CloseAndRecv(); closeErr != nil {
+			return fmt.Errorf("%w: %w", err, closeErr)
+		}
 		return err
 	}
-	return c.stream.Send(request_)
+	return nil
 }
 
 // StreamWrite is to send the data into GreptimeDB via explicit schema.
@@ -280,17 +327,25 @@ func (c *Client) StreamDeleteObject(ctx context.Context, body any) error {
 // CloseStream closes the stream. Once we’ve finished writing our client’s requests to the stream
 // using client.StreamWrite or client.StreamWriteObject, we need to call client.CloseStream to let
 // GreptimeDB know that we’ve finished writing and are expecting to receive a response.
+//
+// The stream is shared by all the goroutines of the client, so the affected rows include the
+// requests sent by all of them before CloseStream. The stream is closed even if an error is
+// returned, and the following stream calls from any goroutine open a new stream.
 func (c *Client) CloseStream(ctx context.Context) (*gpb.AffectedRows, error) {
+	c.streamMu.Lock()
+	defer c.streamMu.Unlock()
+
 	if c.stream == nil {
 		return &gpb.AffectedRows{}, nil
 	}
 
-	resp, err := c.stream.CloseAndRecv()
+	stream := c.stream
+	c.stream = nil
+
+	resp, err := stream.CloseAndRecv()
 	if err != nil {
 		return nil, err
 	}
-
-	c.stream = nil
 	return resp.GetAffectedRows(), nil
 }
 
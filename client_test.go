@@ -23,6 +23,7 @@ import (
 	"math/rand"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -1228,3 +1229,43 @@ func TestStreamDeleteObj(t *testing.T) {
 		assert.Equal(t, monitors[i], monitor_)
 	}
 }
+
+func TestStreamWriteObjConcurrently(t *testing.T) {
+	loc, err := time.LoadLocation(timezone)
+	assert.Nil(t, err)
+
+	lc := newClient()
+	defer lc.Close()
+
+	monitors := make([]monitor, 0, 20)
+	for i := 0; i < 20; i++ {
+		ts := time.Now().Add(-time.Duration(i) * time.Minute).UnixMilli()
+		monitors = append(monitors, monitor{
+			ID:          randomId(),
+			Host:        fmt.Sprintf("127.0.0.%d", i),
+			Memory:      uint64(i),
+			Cpu:         float64(i),
+			Temperature: int64(-i),
+			Ts:          time.UnixMilli(ts).In(loc),
+			Running:     true,
+		})
+	}
+
+	var wg sync.WaitGroup
+	for _, m := range monitors {
+		wg.Add(1)
+		go func(m monitor) {
+			defer wg.Done()
+			assert.Nil(t, lc.StreamWriteObject(context.Background(), m))
+		}(m)
+	}
+	wg.Wait()
+
+	affected, err := lc.CloseStream(context.Background())
+	assert.Nil(t, err)
+	assert.EqualValues(t, uint32(len(monitors)), affected.GetValue())
+
+	monitors_, err := db.Query(fmt.Sprintf("select * from %s where id in %s", monitorTableName, getMonitorsIds(monitors)))
+	assert.Nil(t, err)
+	assert.Equal(t, len(monitors), len(monitors_))
+}
@@ -0,0 +1,652 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/schema"
+	gtypes "github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+const typesPkgPath = "github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+
+// column is the struct field of the column, which may be in the embedded structs.
+type column struct {
+	field     *schema.Field
+	path      string   // like Resource.Region
+	expr      string   // like v.Resource.Region
+	guards    []string // the embedded pointers which MUST not be nil
+	isPointer bool
+	typ       types.Type // dereferenced type of the struct field
+	typeVar   string     // the variable of the datatype inferred at runtime, see errRuntimeType
+}
+
+// errRuntimeType is returned by inferType for types.Valuer and driver.Valuer, whose
+// datatype is inferred from the zero value at runtime by cell.CustomType, the same as
+// schema.Parse.
+var errRuntimeType = errors.New("the datatype is inferred at runtime")
+
+// generator writes the Encoder methods of the structs in one package.
+type generator struct {
+	pkg     *types.Package
+	buf     bytes.Buffer
+	useCell bool
+	imports map[string]string // path to name of the packages of the custom types
+}
+
+// generate returns the formatted source of the Encoder methods of the named structs.
+func generate(pkg *types.Package, typeNames []string, header string) ([]byte, error) {
+	g := &generator{pkg: pkg, imports: make(map[string]string)}
+	for _, name := range typeNames {
+		columns, err := parseStruct(pkg, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := g.writeType(name, columns); err != nil {
+			return nil, fmt.Errorf("type %s: %w", name, err)
+		}
+	}
+
+	var out bytes.Buffer
+	if header != "" {
+		out.WriteString("/*\n")
+		for _, line := range strings.Split(strings.TrimRight(header, "\n"), "\n") {
+			out.WriteString(strings.TrimRight(" * "+line, " ") + "\n")
+		}
+		out.WriteString(" */\n\n")
+	}
+	out.WriteString("// Code generated by greptime-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", pkg.Name())
+	out.WriteString("import (\n")
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(&out, "\t%s %q\n", g.imports[path], path)
+	}
+	if len(paths) > 0 {
+		out.WriteString("\n")
+	}
+	out.WriteString("\tgpb \"github.com/GreptimeTeam/greptime-proto/go/greptime/v1\"\n\n")
+	out.WriteString("\t\"github.com/GreptimeTeam/greptimedb-ingester-go/errs\"\n")
+	if g.useCell {
+		out.WriteString("\t\"github.com/GreptimeTeam/greptimedb-ingester-go/table/cell\"\n")
+	}
+	out.WriteString(")\n")
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return src, nil
+}
+
+// parseStruct parses the columns of the struct like schema.Parse, the embedded structs
+// are flattened.
+func parseStruct(pkg *types.Package, name string) ([]*column, error) {
+	obj := pkg.Scope().Lookup(name)
+	if obj == nil {
+		return nil, fmt.Errorf("type %s not found in package %s", name, pkg.Path())
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok || named.TypeParams().Len() > 0 {
+		return nil, fmt.Errorf("type %s is not a struct without type parameters", name)
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not a struct", name)
+	}
+
+	columns := make([]*column, 0, st.NumFields())
+	parents := map[types.Type]bool{named: true}
+	if err := flattenStruct(st, &column{expr: "v"}, "", parents, &columns); err != nil {
+		return nil, fmt.Errorf("type %s: %w", name, err)
+	}
+
+	paths := make(map[string]string, len(columns))
+	for _, c := range columns {
+		if path, ok := paths[c.field.Name]; ok {
+			return nil, fmt.Errorf("type %s: %w %q of field %s and %s", name, errs.ErrDuplicateColumn, c.field.Name, path, c.path)
+		}
+		paths[c.field.Name] = c.path
+	}
+	return columns, nil
+}
+
+// flattenStruct appends the columns of the struct, parent is the embedded struct field.
+func flattenStruct(st *types.Struct, parent *column, prefix string, parents map[types.Type]bool, columns *[]*column) error {
+	for i := 0; i < st.NumFields(); i++ {
+		structField := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i))
+
+		typ, isPointer := structField.Type(), false
+		if ptr, ok := types.Unalias(typ).(*types.Pointer); ok {
+			typ, isPointer = ptr.Elem(), true
+		}
+
+		c := &column{
+			path:      strings.TrimPrefix(parent.path+"."+structField.Name(), "."),
+			expr:      parent.expr + "." + structField.Name(),
+			guards:    parent.guards,
+			isPointer: isPointer,
+			typ:       typ,
+		}
+
+		embedPrefix, embed, err := isEmbedded(structField, tag, typ)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", c.path, err)
+		}
+		if embed {
+			if parents[typ] {
+				return fmt.Errorf("field %s: recursive embedded struct %s", c.path, typ)
+			}
+			parents[typ] = true
+			if isPointer {
+				c.guards = append(append([]string{}, c.guards...), c.expr+" != nil")
+			}
+			err := flattenStruct(typ.Underlying().(*types.Struct), c, prefix+embedPrefix, parents, columns)
+			delete(parents, typ)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !structField.Exported() {
+			continue
+		}
+		typeVar := ""
+		field, err := schema.ParseFieldTag(structField.Name(), tag, func() (gpb.ColumnDataType, error) {
+			datatype, err := inferType(typ)
+			if errors.Is(err, errRuntimeType) {
+				// the placeholder, the column uses typeVar instead
+				typeVar = "Type"
+				return gpb.ColumnDataType_STRING, nil
+			}
+			return datatype, err
+		})
+		if err != nil {
+			return fmt.Errorf("field %s: %w", c.path, err)
+		}
+		if field == nil {
+			continue
+		}
+		field.Name = prefix + field.Name
+		c.field = field
+		c.typeVar = typeVar
+		*columns = append(*columns, c)
+	}
+	return nil
+}
+
+// isEmbedded is the same as the embedded struct field of schema, which is tagged by embed
+// or prefix, or the anonymous struct without the greptime tag.
+func isEmbedded(structField *types.Var, tag reflect.StructTag, typ types.Type) (string, bool, error) {
+	_, isStruct := typ.Underlying().(*types.Struct)
+
+	prefix, embed := schema.ParseEmbedTag(tag)
+	if embed {
+		if !structField.Exported() && !structField.Embedded() {
+			return "", false, fmt.Errorf("unexported field can not be embedded")
+		}
+		if !isStruct {
+			return "", false, fmt.Errorf("embedded type %s is not a struct", structField.Type())
+		}
+		return prefix, true, nil
+	}
+
+	if _, ok := tag.Lookup(schema.GreptimeFieldTagKey); ok || !structField.Embedded() || !isStruct {
+		return "", false, nil
+	}
+	datatype, err := inferType(typ)
+	return "", err == nil && datatype == gpb.ColumnDataType_JSON, nil
+}
+
+// inferType is the same as the datatype inferred from the reflect.Type by schema.Parse.
+func inferType(typ types.Type) (gpb.ColumnDataType, error) {
+	switch {
+	case isNamed(typ, "time", "Time"):
+		return gpb.ColumnDataType_TIMESTAMP_MILLISECOND, nil
+	case isNamed(typ, "encoding/json", "RawMessage"):
+		return gpb.ColumnDataType_JSON, nil
+	case isNamed(typ, typesPkgPath, "Interval"):
+		return gpb.ColumnDataType_INTERVAL_MONTH_DAY_NANO, nil
+	case isDecimal(typ):
+		return gpb.ColumnDataType_DECIMAL128, nil
+	case isSQLNull(typ):
+		return inferType(typ.Underlying().(*types.Struct).Field(0).Type())
+	case hasMethod(typ, "GreptimeValue"), hasMethod(typ, "Value"):
+		return -1, errRuntimeType
+	case hasMethod(typ, "MarshalText"):
+		switch typ.Underlying().(type) {
+		case *types.Struct, *types.Array, *types.Slice, *types.Map:
+			return gpb.ColumnDataType_STRING, nil
+		}
+	}
+
+	switch u := typ.Underlying().(type) {
+	case *types.Basic:
+		switch u.Kind() {
+		case types.Bool:
+			return gpb.ColumnDataType_BOOLEAN, nil
+		case types.Int, types.Int64:
+			return gpb.ColumnDataType_INT64, nil
+		case types.Int8:
+			return gpb.ColumnDataType_INT8, nil
+		case types.Int16:
+			return gpb.ColumnDataType_INT16, nil
+		case types.Int32:
+			return gpb.ColumnDataType_INT32, nil
+		case types.Uint, types.Uint64:
+			return gpb.ColumnDataType_UINT64, nil
+		case types.Uint8:
+			return gpb.ColumnDataType_UINT8, nil
+		case types.Uint16:
+			return gpb.ColumnDataType_UINT16, nil
+		case types.Uint32:
+			return gpb.ColumnDataType_UINT32, nil
+		case types.Float32:
+			return gpb.ColumnDataType_FLOAT32, nil
+		case types.Float64:
+			return gpb.ColumnDataType_FLOAT64, nil
+		case types.String:
+			return gpb.ColumnDataType_STRING, nil
+		}
+	case *types.Pointer:
+		return inferType(u.Elem())
+	case *types.Slice:
+		if isByte(u.Elem()) {
+			return gpb.ColumnDataType_BINARY, nil
+		}
+		return gpb.ColumnDataType_JSON, nil
+	case *types.Array:
+		if isByte(u.Elem()) {
+			return gpb.ColumnDataType_BINARY, nil
+		}
+		return gpb.ColumnDataType_JSON, nil
+	case *types.Map, *types.Struct:
+		return gpb.ColumnDataType_JSON, nil
+	}
+	return -1, fmt.Errorf("unsupported type %q", typ.String())
+}
+
+// isNamed reports whether typ is the named type or the alias, like json.RawMessage which
+// is an alias of jsontext.Value since Go 1.25.
+func isNamed(typ types.Type, pkgPath, name string) bool {
+	for {
+		var obj *types.TypeName
+		switch t := typ.(type) {
+		case *types.Alias:
+			obj = t.Obj()
+		case *types.Named:
+			obj = t.Obj()
+		default:
+			return false
+		}
+		if obj.Pkg() != nil && obj.Pkg().Path() == pkgPath && obj.Name() == name {
+			return true
+		}
+
+		alias, ok := typ.(*types.Alias)
+		if !ok {
+			return false
+		}
+		typ = alias.Rhs()
+	}
+}
+
+// isDecimal is like the decimal types of schema, which are big.Int, big.Rat or the types
+// with Coefficient() and Exponent() methods.
+func isDecimal(typ types.Type) bool {
+	if _, ok := typ.Underlying().(*types.Struct); !ok {
+		return false
+	}
+	if isNamed(typ, "math/big", "Int") || isNamed(typ, "math/big", "Rat") {
+		return true
+	}
+	methods := types.NewMethodSet(types.NewPointer(typ))
+	return methods.Lookup(nil, "Coefficient") != nil && methods.Lookup(nil, "Exponent") != nil
+}
+
+// hasMethod reports whether the pointer of typ has the method.
+func hasMethod(typ types.Type, name string) bool {
+	return types.NewMethodSet(types.NewPointer(typ)).Lookup(nil, name) != nil
+}
+
+// isSQLNull is like sql.NullString or sql.Null[T], whose first field is the value.
+func isSQLNull(typ types.Type) bool {
+	named, ok := types.Unalias(typ).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != "database/sql" ||
+		!strings.HasPrefix(named.Obj().Name(), "Null") {
+		return false
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	return ok && st.NumFields() == 2 && st.Field(1).Name() == "Valid"
+}
+
+// isCustom is the same as cell.IsCustomType, the value is built by cell.Build.
+func isCustom(typ types.Type, datatype gpb.ColumnDataType) bool {
+	if hasMethod(typ, "GreptimeValue") {
+		return true
+	}
+	if hasMethod(typ, "Value") && !(datatype == gpb.ColumnDataType_DECIMAL128 && isDecimal(typ)) {
+		return true
+	}
+	return datatype == gpb.ColumnDataType_STRING && (hasMethod(typ, "MarshalText") || hasMethod(typ, "String"))
+}
+
+func isByte(typ types.Type) bool {
+	basic, ok := typ.Underlying().(*types.Basic)
+	return ok && basic.Kind() == types.Uint8
+}
+
+func (g *generator) writeType(name string, columns []*column) error {
+	runtimeTypes := &bytes.Buffer{}
+	for _, c := range columns {
+		if c.typeVar == "" {
+			continue
+		}
+		g.useCell = true
+		c.typeVar = "greptime" + name + strings.ReplaceAll(c.path, ".", "") + c.typeVar
+		fmt.Fprintf(runtimeTypes, "%s, %sErr = cell.CustomType(new(%s))\n",
+			c.typeVar, c.typeVar, types.TypeString(c.typ, g.qualifier))
+	}
+	if runtimeTypes.Len() > 0 {
+		fmt.Fprintf(&g.buf, "\n// The datatypes of the custom types of %s are inferred like schema.Parse.\n", name)
+		fmt.Fprintf(&g.buf, "var (\n%s)\n", runtimeTypes.Bytes())
+	}
+
+	fmt.Fprintf(&g.buf, "\n// GreptimeColumns implements schema.Encoder.\n")
+	fmt.Fprintf(&g.buf, "func (*%s) GreptimeColumns() []*gpb.ColumnSchema {\n", name)
+	fmt.Fprintf(&g.buf, "return []*gpb.ColumnSchema{\n")
+	for _, c := range columns {
+		fmt.Fprintf(&g.buf, "{ColumnName: %q, SemanticType: gpb.SemanticType_%s, Datatype: %s",
+			c.field.Name, c.field.SemanticType, c.datatypeCode())
+		if c.field.DatatypeExtension != nil {
+			fmt.Fprintf(&g.buf, ", DatatypeExtension: %s", extensionCode(c.field.DatatypeExtension))
+		}
+		fmt.Fprintf(&g.buf, "},\n")
+	}
+	fmt.Fprintf(&g.buf, "}\n}\n")
+
+	body := &bytes.Buffer{}
+	useErr := false
+	for i, c := range columns {
+		code, fallible, err := g.valueCode(c, fmt.Sprintf("values[%d]", i))
+		if err != nil {
+			return fmt.Errorf("field %s: %w", c.path, err)
+		}
+		useErr = useErr || fallible
+		guards := c.guards
+		if c.isPointer || c.isNilable() {
+			guards = append(append([]string{}, guards...), c.expr+" != nil")
+		}
+		if len(guards) > 0 {
+			fmt.Fprintf(body, "if %s {\n%s}\n", strings.Join(guards, " && "), code)
+		} else {
+			body.WriteString(code)
+		}
+	}
+
+	fmt.Fprintf(&g.buf, "\n// GreptimeRow implements schema.Encoder.\n")
+	fmt.Fprintf(&g.buf, "func (v *%s) GreptimeRow() (*gpb.Row, error) {\n", name)
+	fmt.Fprintf(&g.buf, "if v == nil {\nreturn nil, errs.ErrNilObject\n}\n")
+	for _, c := range columns {
+		if c.typeVar != "" {
+			fmt.Fprintf(&g.buf, "if %sErr != nil {\nreturn nil, %sErr\n}\n", c.typeVar, c.typeVar)
+		}
+	}
+	fmt.Fprintf(&g.buf, "\n")
+	fmt.Fprintf(&g.buf, "values := make([]*gpb.Value, %d)\n", len(columns))
+	if useErr {
+		fmt.Fprintf(&g.buf, "var err error\n")
+	}
+	g.buf.Write(body.Bytes())
+	fmt.Fprintf(&g.buf, "return &gpb.Row{Values: values}, nil\n}\n")
+	return nil
+}
+
+// datatypeCode returns the expression of the datatype of the column.
+func (c *column) datatypeCode() string {
+	if c.typeVar != "" {
+		return c.typeVar
+	}
+	return "gpb.ColumnDataType_" + c.field.Datatype.String()
+}
+
+// qualifier qualifies the types of the other packages by their names in the imports.
+func (g *generator) qualifier(pkg *types.Package) string {
+	if pkg.Path() == g.pkg.Path() {
+		return ""
+	}
+	if name, ok := g.imports[pkg.Path()]; ok {
+		return name
+	}
+
+	name := pkg.Name()
+	for i := 2; g.isImported(name); i++ {
+		name = fmt.Sprintf("%s%d", pkg.Name(), i)
+	}
+	g.imports[pkg.Path()] = name
+	return name
+}
+
+func (g *generator) isImported(name string) bool {
+	if name == "gpb" || name == "errs" || name == "cell" {
+		return true
+	}
+	for _, imported := range g.imports {
+		if imported == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isNilable reports whether the nil value of the field is null, like the nil map, slice
+// and json.RawMessage of the JSON column.
+func (c *column) isNilable() bool {
+	if c.field.Datatype != gpb.ColumnDataType_JSON && !isJSONBinary(c.field) {
+		return false
+	}
+	switch c.typ.Underlying().(type) {
+	case *types.Map, *types.Slice, *types.Interface:
+		return true
+	}
+	return false
+}
+
+func isJSONBinary(field *schema.Field) bool {
+	return gtypes.IsJSONBinary(field.DatatypeExtension)
+}
+
+func extensionCode(ext *gpb.ColumnDataTypeExtension) string {
+	if decimal := ext.GetDecimalType(); decimal != nil {
+		return fmt.Sprintf("&gpb.ColumnDataTypeExtension{TypeExt: &gpb.ColumnDataTypeExtension_DecimalType{"+
+			"DecimalType: &gpb.DecimalTypeExtension{Precision: %d, Scale: %d}}}", decimal.Precision, decimal.Scale)
+	}
+	return "&gpb.ColumnDataTypeExtension{TypeExt: &gpb.ColumnDataTypeExtension_JsonType{" +
+		"JsonType: gpb.JsonTypeExtension_JSON_BINARY}}"
+}
+
+// valueCode returns the statement assigning the value of the column to dst. The fallible
+// statement returns the error of cell.Build.
+func (g *generator) valueCode(c *column, dst string) (string, bool, error) {
+	// the methods and the slice expression of the pointer are the same as the value
+	val, ref, addr := c.expr, c.expr, "&"+c.expr
+	if c.isPointer {
+		val, addr = "*"+c.expr, c.expr
+	}
+
+	datatype := c.field.Datatype
+	literal := func(wrapper, expr string) (string, bool, error) {
+		return fmt.Sprintf("%s = &gpb.Value{ValueData: &gpb.Value_%s{%s: %s}}\n", dst, wrapper, wrapper, expr), false, nil
+	}
+	build := func(expr string) (string, bool, error) {
+		g.useCell = true
+		ext := ""
+		if c.field.DatatypeExtension != nil {
+			ext = ".WithExtension(" + extensionCode(c.field.DatatypeExtension) + ")"
+		}
+		return fmt.Sprintf("if %s, err = cell.New(%s, %s)%s.Build(); err != nil {\nreturn nil, err\n}\n",
+			dst, expr, c.datatypeCode(), ext), true, nil
+	}
+	// the integer for the time columns
+	buildInt := func() (string, bool, error) {
+		switch {
+		case isKind(c.typ, types.IsInteger|types.IsUnsigned):
+			return build("uint64(" + val + ")")
+		case isKind(c.typ, types.IsInteger):
+			return build("int64(" + val + ")")
+		}
+		return "", false, fmt.Errorf("type %s is not compatible with %s", c.typ, datatype)
+	}
+
+	if c.typeVar != "" || isCustom(c.typ, datatype) {
+		// the methods may have pointer receivers
+		return build(addr)
+	}
+
+	if isJSONBinary(c.field) || datatype == gpb.ColumnDataType_JSON {
+		if isKind(c.typ, types.IsString) {
+			return build("string(" + val + ")")
+		}
+		return build(val)
+	}
+
+	switch datatype {
+	case gpb.ColumnDataType_INT8, gpb.ColumnDataType_INT16, gpb.ColumnDataType_INT32, gpb.ColumnDataType_INT64:
+		if !isKind(c.typ, types.IsInteger) || isKind(c.typ, types.IsInteger|types.IsUnsigned) {
+			return "", false, fmt.Errorf("type %s is not compatible with Int", c.typ)
+		}
+		wrapper := map[gpb.ColumnDataType]string{
+			gpb.ColumnDataType_INT8: "I8Value", gpb.ColumnDataType_INT16: "I16Value",
+			gpb.ColumnDataType_INT32: "I32Value", gpb.ColumnDataType_INT64: "I64Value",
+		}[datatype]
+		if datatype == gpb.ColumnDataType_INT64 {
+			return literal(wrapper, "int64("+val+")")
+		}
+		return literal(wrapper, "int32("+val+")")
+
+	case gpb.ColumnDataType_UINT8, gpb.ColumnDataType_UINT16, gpb.ColumnDataType_UINT32, gpb.ColumnDataType_UINT64:
+		if !isKind(c.typ, types.IsInteger|types.IsUnsigned) {
+			return "", false, fmt.Errorf("type %s is not compatible with Unsigned Int", c.typ)
+		}
+		wrapper := map[gpb.ColumnDataType]string{
+			gpb.ColumnDataType_UINT8: "U8Value", gpb.ColumnDataType_UINT16: "U16Value",
+			gpb.ColumnDataType_UINT32: "U32Value", gpb.ColumnDataType_UINT64: "U64Value",
+		}[datatype]
+		if datatype == gpb.ColumnDataType_UINT64 {
+			return literal(wrapper, "uint64("+val+")")
+		}
+		return literal(wrapper, "uint32("+val+")")
+
+	case gpb.ColumnDataType_FLOAT32:
+		if !isKind(c.typ, types.IsFloat) {
+			return "", false, fmt.Errorf("type %s is not compatible with Float", c.typ)
+		}
+		return literal("F32Value", "float32("+val+")")
+	case gpb.ColumnDataType_FLOAT64:
+		if !isKind(c.typ, types.IsFloat) {
+			return "", false, fmt.Errorf("type %s is not compatible with Float", c.typ)
+		}
+		return literal("F64Value", "float64("+val+")")
+
+	case gpb.ColumnDataType_BOOLEAN:
+		if !isKind(c.typ, types.IsBoolean) {
+			return "", false, fmt.Errorf("type %s is not compatible with Bool", c.typ)
+		}
+		return literal("BoolValue", "bool("+val+")")
+
+	case gpb.ColumnDataType_STRING:
+		if !isKind(c.typ, types.IsString) {
+			return "", false, fmt.Errorf("type %s is not compatible with String", c.typ)
+		}
+		return literal("StringValue", "string("+val+")")
+
+	case gpb.ColumnDataType_BINARY:
+		switch u := c.typ.Underlying().(type) {
+		case *types.Slice:
+			if isByte(u.Elem()) {
+				return literal("BinaryValue", "[]byte("+val+")")
+			}
+		case *types.Array:
+			if types.Identical(u.Elem(), types.Typ[types.Uint8]) {
+				return literal("BinaryValue", ref+"[:]")
+			}
+		}
+		return "", false, fmt.Errorf("type %s is not compatible with Bytes", c.typ)
+
+	case gpb.ColumnDataType_TIMESTAMP_SECOND, gpb.ColumnDataType_TIMESTAMP_MILLISECOND,
+		gpb.ColumnDataType_TIMESTAMP_MICROSECOND, gpb.ColumnDataType_TIMESTAMP_NANOSECOND:
+		if isNamed(c.typ, "time", "Time") {
+			unix := map[gpb.ColumnDataType][2]string{
+				gpb.ColumnDataType_TIMESTAMP_SECOND:      {"TimestampSecondValue", "Unix"},
+				gpb.ColumnDataType_TIMESTAMP_MILLISECOND: {"TimestampMillisecondValue", "UnixMilli"},
+				gpb.ColumnDataType_TIMESTAMP_MICROSECOND: {"TimestampMicrosecondValue", "UnixMicro"},
+				gpb.ColumnDataType_TIMESTAMP_NANOSECOND:  {"TimestampNanosecondValue", "UnixNano"},
+			}[datatype]
+			return literal(unix[0], ref+"."+unix[1]+"()")
+		}
+		return buildInt()
+
+	case gpb.ColumnDataType_DATE, gpb.ColumnDataType_DATETIME:
+		if isNamed(c.typ, "time", "Time") {
+			return build(val)
+		}
+		return buildInt()
+
+	case gpb.ColumnDataType_TIME_SECOND, gpb.ColumnDataType_TIME_MILLISECOND,
+		gpb.ColumnDataType_TIME_MICROSECOND, gpb.ColumnDataType_TIME_NANOSECOND:
+		if isNamed(c.typ, "time", "Duration") || isNamed(c.typ, "time", "Time") {
+			return build(val)
+		}
+		return buildInt()
+
+	case gpb.ColumnDataType_INTERVAL_YEAR_MONTH, gpb.ColumnDataType_INTERVAL_DAY_TIME,
+		gpb.ColumnDataType_INTERVAL_MONTH_DAY_NANO:
+		if isNamed(c.typ, typesPkgPath, "Interval") || isNamed(c.typ, "time", "Duration") {
+			return build(val)
+		}
+		return buildInt()
+
+	case gpb.ColumnDataType_DECIMAL128:
+		// the methods of the decimal types may have pointer receivers
+		return build(addr)
+	}
+	return "", false, fmt.Errorf("unsupported column data type %s", datatype)
+}
+
+// isKind reports whether the underlying type of typ is the basic type of all the info.
+func isKind(typ types.Type, info types.BasicInfo) bool {
+	basic, ok := typ.Underlying().(*types.Basic)
+	return ok && basic.Info()&info == info
+}
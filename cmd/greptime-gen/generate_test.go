@@ -0,0 +1,147 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"go/token"
+	"go/types"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+)
+
+func TestGenerateUpToDate(t *testing.T) {
+	pkg, dir, err := loadPackage("internal/example")
+	assert.Nil(t, err)
+
+	header, err := os.ReadFile(dir + "/header.txt")
+	assert.Nil(t, err)
+
+	src, err := generate(pkg, []string{"Monitor", "Event", "Metric"}, string(header))
+	assert.Nil(t, err)
+
+	expected, err := os.ReadFile(dir + "/monitor_greptime.go")
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), string(src), "run go generate ./cmd/greptime-gen/internal/example")
+}
+
+// newPackage returns the package with the struct of the fields.
+func newPackage(fields []*types.Var, tags []string) *types.Package {
+	pkg := types.NewPackage("example.com/test", "test")
+	obj := types.NewTypeName(token.NoPos, pkg, "Test", nil)
+	types.NewNamed(obj, types.NewStruct(fields, tags), nil)
+	pkg.Scope().Insert(obj)
+	return pkg
+}
+
+func newField(name string, typ types.Type) *types.Var {
+	return types.NewField(token.NoPos, nil, name, typ, false)
+}
+
+func TestGenerateInvalid(t *testing.T) {
+	_, err := generate(newPackage(nil, nil), []string{"Unknown"}, "")
+	assert.ErrorContains(t, err, "type Unknown not found")
+
+	pkg := newPackage([]*types.Var{newField("Ch", types.NewChan(types.SendRecv, types.Typ[types.Int]))}, []string{""})
+	_, err = generate(pkg, []string{"Test"}, "")
+	assert.ErrorContains(t, err, "field Ch")
+	assert.ErrorContains(t, err, "unsupported type")
+
+	pkg = newPackage([]*types.Var{newField("Host", types.Typ[types.String])}, []string{`greptime:"tag;type:int64"`})
+	_, err = generate(pkg, []string{"Test"}, "")
+	assert.ErrorContains(t, err, "not compatible with Int")
+
+	pkg = newPackage([]*types.Var{newField("Host", types.Typ[types.String])}, []string{`greptime:"tag;type:unknown"`})
+	_, err = generate(pkg, []string{"Test"}, "")
+	assert.ErrorContains(t, err, `unsupported column type "unknown"`)
+
+	labels := types.NewStruct([]*types.Var{newField("Host", types.Typ[types.String])}, []string{`greptime:"tag"`})
+	pkg = newPackage([]*types.Var{
+		types.NewField(token.NoPos, nil, "Labels", labels, true),
+		newField("Host", types.Typ[types.String]),
+	}, []string{"", `greptime:"field"`})
+	_, err = generate(pkg, []string{"Test"}, "")
+	assert.ErrorIs(t, err, errs.ErrDuplicateColumn)
+	assert.ErrorContains(t, err, `"host" of field Labels.Host and Host`)
+
+	pkg = newPackage([]*types.Var{newField("Host", types.Typ[types.String])}, []string{`greptime:"embed"`})
+	_, err = generate(pkg, []string{"Test"}, "")
+	assert.ErrorContains(t, err, "embedded type string is not a struct")
+
+	pkg = types.NewPackage("example.com/test", "test")
+	obj := types.NewTypeName(token.NoPos, pkg, "Test", nil)
+	named := types.NewNamed(obj, nil, nil)
+	named.SetUnderlying(types.NewStruct([]*types.Var{newField("Next", types.NewPointer(named))}, []string{`greptime:"embed"`}))
+	pkg.Scope().Insert(obj)
+	_, err = generate(pkg, []string{"Test"}, "")
+	assert.ErrorContains(t, err, "recursive embedded struct")
+}
+
+// newValuer returns the named type implementing types.Valuer in pkg.
+func newValuer(pkg *types.Package, name string) *types.Named {
+	named := types.NewNamed(types.NewTypeName(token.NoPos, pkg, name, nil), types.Typ[types.Int64], nil)
+	recv := types.NewVar(token.NoPos, pkg, "v", types.NewPointer(named))
+	named.AddMethod(types.NewFunc(token.NoPos, pkg, "GreptimeValue", types.NewSignatureType(recv, nil, nil, nil, nil, false)))
+	return named
+}
+
+func TestGenerateValuer(t *testing.T) {
+	pkg := newPackage(nil, nil)
+	other := types.NewPackage("example.com/other", "other")
+	st := types.NewStruct([]*types.Var{
+		newField("User", newValuer(pkg, "UserID")),
+		newField("Owner", types.NewPointer(newValuer(other, "ID"))),
+		newField("Tagged", newValuer(pkg, "UserID")),
+	}, []string{`greptime:"tag"`, `greptime:"field"`, `greptime:"field;type:uint32"`})
+	pkg.Scope().Lookup("Test").Type().(*types.Named).SetUnderlying(st)
+
+	src, err := generate(pkg, []string{"Test"}, "")
+	assert.Nil(t, err)
+	code := string(src)
+	// the datatypes without the type in the tag are inferred at runtime
+	assert.Contains(t, code, `other "example.com/other"`)
+	assert.Contains(t, code, "= cell.CustomType(new(UserID))")
+	assert.Contains(t, code, "= cell.CustomType(new(other.ID))")
+	assert.Contains(t, code, `{ColumnName: "user", SemanticType: gpb.SemanticType_TAG, Datatype: greptimeTestUserType}`)
+	assert.Contains(t, code, "cell.New(&v.User, greptimeTestUserType)")
+	assert.Contains(t, code, "cell.New(v.Owner, greptimeTestOwnerType)")
+	assert.Contains(t, code, "cell.New(&v.Tagged, gpb.ColumnDataType_UINT32)")
+	assert.NotContains(t, code, "greptimeTestTagged")
+}
+
+func TestGenerate(t *testing.T) {
+	pkg := newPackage([]*types.Var{
+		newField("Host", types.Typ[types.String]),
+		newField("Cpu", types.NewPointer(types.Typ[types.Float64])),
+		newField("Ignored", types.Typ[types.Int]),
+		newField("unexported", types.Typ[types.Int]),
+	}, []string{`greptime:"tag"`, `greptime:"field;column:cpu_usage"`, `greptime:"-"`, ""})
+
+	src, err := generate(pkg, []string{"Test"}, "")
+	assert.Nil(t, err)
+	code := string(src)
+	assert.Contains(t, code, "// Code generated by greptime-gen. DO NOT EDIT.")
+	assert.Contains(t, code, `{ColumnName: "host", SemanticType: gpb.SemanticType_TAG, Datatype: gpb.ColumnDataType_STRING}`)
+	assert.Contains(t, code, `{ColumnName: "cpu_usage", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_FLOAT64}`)
+	assert.Contains(t, code, "F64Value: float64(*v.Cpu)")
+	assert.NotContains(t, code, "ignored")
+	assert.NotContains(t, code, "unexported")
+	assert.NotContains(t, code, "table/cell")
+}
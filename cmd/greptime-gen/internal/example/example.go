@@ -0,0 +1,102 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package example has the structs to test the code generated by greptime-gen.
+package example
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math/big"
+	"net/netip"
+	"time"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+//go:generate go run ../.. -type=Monitor,Event,Metric -header=header.txt
+
+type Status string
+
+type Level int
+
+func (l Level) String() string {
+	return [...]string{"debug", "info", "warn"}[l]
+}
+
+type UserID struct {
+	ID int64
+}
+
+func (u *UserID) GreptimeValue() (any, types.ColumnType) {
+	return u.ID, types.UINT32
+}
+
+type Monitor struct {
+	ID          int64     `greptime:"tag;column:id;type:int64"`
+	Host        string    `greptime:"tag;column:host;type:string"`
+	Memory      uint64    `greptime:"field;column:memory;type:uint64"`
+	Cpu         float64   `greptime:"field;column:cpu;type:float64"`
+	Temperature int64     `greptime:"field;column:temperature;type:int64"`
+	Running     bool      `greptime:"field;column:running;type:boolean"`
+	Ts          time.Time `greptime:"timestamp;column:ts;type:timestamp;precision:millisecond"`
+
+	ignored string //nolint:unused
+}
+
+func (Monitor) TableName() string {
+	return "monitor"
+}
+
+type Event struct {
+	Status   Status            `greptime:"tag"`
+	Count    *int32            `greptime:"field;type:int16"`
+	Ratio    *float32          `greptime:"field"`
+	Payload  []byte            `greptime:"field"`
+	Digest   [4]byte           `greptime:"field"`
+	Labels   map[string]string `greptime:"field;type:json"`
+	Attrs    map[string]any    `greptime:"field;type:jsonb"`
+	Raw      json.RawMessage   `greptime:"field"`
+	Price    big.Rat           `greptime:"field;type:decimal128;precision:10;scale:2"`
+	Elapsed  time.Duration     `greptime:"field;type:time;precision:millisecond"`
+	Interval types.Interval    `greptime:"field"`
+	Day      time.Time         `greptime:"field;type:date"`
+	Created  int64             `greptime:"field;type:timestamp;precision:second"`
+	Skipped  string            `greptime:"-"`
+	Level    Level             `greptime:"field;type:string"`
+	User     *UserID           `greptime:"field;type:uint32"`
+	Owner    UserID            `greptime:"field"`
+	Name     sql.NullString    `greptime:"field"`
+	Addr     netip.Addr        `greptime:"field"`
+	Ts       *time.Time        `greptime:"timestamp;type:timestamp;precision:nanosecond"`
+}
+
+type Labels struct {
+	Region string  `greptime:"tag"`
+	Zone   *string `greptime:"tag"`
+}
+
+type Resource struct {
+	Host    string `greptime:"tag"`
+	Version string
+}
+
+type Metric struct {
+	Labels
+	Resource *Resource `greptime:"embed;prefix:res_"`
+	Value    float64   `greptime:"field"`
+	Ts       time.Time `greptime:"timestamp"`
+}
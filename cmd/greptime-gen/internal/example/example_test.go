@@ -0,0 +1,193 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package example
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math/big"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/schema"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+// the types without the generated methods are parsed by reflection
+type (
+	reflectMonitor Monitor
+	reflectEvent   Event
+)
+
+func newMonitors(n int) []Monitor {
+	ts := time.UnixMilli(1700000000000)
+	monitors := make([]Monitor, n)
+	for i := range monitors {
+		monitors[i] = Monitor{
+			ID:          int64(i),
+			Host:        "127.0.0.1",
+			Memory:      uint64(i),
+			Cpu:         float64(i) / 10,
+			Temperature: -int64(i),
+			Running:     i%2 == 0,
+			Ts:          ts.Add(time.Duration(i) * time.Millisecond),
+		}
+	}
+	return monitors
+}
+
+func newEvents() []Event {
+	count, ratio := int32(3), float32(0.5)
+	ts := time.Unix(1700000000, 123456789)
+	return []Event{
+		{
+			Status:   "ok",
+			Count:    &count,
+			Ratio:    &ratio,
+			Payload:  []byte("payload"),
+			Digest:   [4]byte{1, 2, 3, 4},
+			Labels:   map[string]string{"a": "b"},
+			Attrs:    map[string]any{"x": 1},
+			Raw:      json.RawMessage(`{"k":"v"}`),
+			Price:    *big.NewRat(12345, 100),
+			Elapsed:  1500 * time.Millisecond,
+			Interval: types.Interval{Months: 1, Days: 2, Nanoseconds: 3},
+			Day:      ts,
+			Created:  1700000000,
+			Skipped:  "skipped",
+			Level:    2,
+			User:     &UserID{ID: 42},
+			Owner:    UserID{ID: 7},
+			Name:     sql.NullString{String: "name", Valid: true},
+			Addr:     netip.MustParseAddr("127.0.0.1"),
+			Ts:       &ts,
+		},
+		{Status: "empty", Day: ts},
+	}
+}
+
+func assertTableEqual(t *testing.T, expected, actual *table.Table) {
+	expectedColumns, actualColumns := expected.GetColumnsSchema(), actual.GetColumnsSchema()
+	assert.Len(t, actualColumns, len(expectedColumns))
+	for i := range expectedColumns {
+		assert.True(t, proto.Equal(expectedColumns[i], actualColumns[i]), "column %d", i)
+	}
+	assert.True(t, proto.Equal(expected.GetRows(), actual.GetRows()))
+}
+
+func TestGeneratedMonitor(t *testing.T) {
+	monitors := newMonitors(10)
+	reflectMonitors := make([]reflectMonitor, len(monitors))
+	for i, monitor := range monitors {
+		reflectMonitors[i] = reflectMonitor(monitor)
+	}
+
+	expected, err := schema.Parse(reflectMonitors)
+	assert.Nil(t, err)
+	actual, err := schema.Parse(monitors)
+	assert.Nil(t, err)
+	assertTableEqual(t, expected, actual)
+
+	// the Tabler is still used by the generated types
+	name, err := actual.GetName()
+	assert.Nil(t, err)
+	assert.Equal(t, "monitor", name)
+}
+
+func TestGeneratedEvent(t *testing.T) {
+	events := newEvents()
+	reflectEvents := make([]*reflectEvent, len(events))
+	for i := range events {
+		reflectEvents[i] = (*reflectEvent)(&events[i])
+	}
+
+	expected, err := schema.Parse(reflectEvents)
+	assert.Nil(t, err)
+	actual, err := schema.Parse(events)
+	assert.Nil(t, err)
+	assertTableEqual(t, expected, actual)
+
+	// not addressable
+	actual, err = schema.Parse(events[0])
+	assert.Nil(t, err)
+	assert.True(t, proto.Equal(expected.GetRows().Rows[0], actual.GetRows().Rows[0]))
+
+	_, err = (*Event)(nil).GreptimeRow()
+	assert.ErrorIs(t, err, errs.ErrNilObject)
+}
+
+func TestGeneratedMetric(t *testing.T) {
+	type reflectMetric Metric
+
+	zone := "a"
+	ts := time.UnixMilli(1700000000000)
+	metrics := []Metric{
+		{
+			Labels:   Labels{Region: "us", Zone: &zone},
+			Resource: &Resource{Host: "h1", Version: "v1"},
+			Value:    1,
+			Ts:       ts,
+		},
+		{Labels: Labels{Region: "eu"}, Ts: ts},
+	}
+	reflectMetrics := make([]reflectMetric, len(metrics))
+	for i, metric := range metrics {
+		reflectMetrics[i] = reflectMetric(metric)
+	}
+
+	expected, err := schema.Parse(reflectMetrics)
+	assert.Nil(t, err)
+	actual, err := schema.Parse(metrics)
+	assert.Nil(t, err)
+	assertTableEqual(t, expected, actual)
+	assert.Equal(t, "res_host", actual.GetColumnsSchema()[2].ColumnName)
+	assert.Nil(t, actual.GetRows().Rows[1].Values[2])
+}
+
+func BenchmarkParseReflect(b *testing.B) {
+	monitors := newMonitors(10000)
+	reflectMonitors := make([]reflectMonitor, len(monitors))
+	for i, monitor := range monitors {
+		reflectMonitors[i] = reflectMonitor(monitor)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := schema.Parse(reflectMonitors); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseGenerated(b *testing.B) {
+	monitors := newMonitors(10000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := schema.Parse(monitors); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
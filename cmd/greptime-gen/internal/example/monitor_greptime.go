@@ -0,0 +1,193 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Code generated by greptime-gen. DO NOT EDIT.
+
+package example
+
+import (
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/cell"
+)
+
+// GreptimeColumns implements schema.Encoder.
+func (*Monitor) GreptimeColumns() []*gpb.ColumnSchema {
+	return []*gpb.ColumnSchema{
+		{ColumnName: "id", SemanticType: gpb.SemanticType_TAG, Datatype: gpb.ColumnDataType_INT64},
+		{ColumnName: "host", SemanticType: gpb.SemanticType_TAG, Datatype: gpb.ColumnDataType_STRING},
+		{ColumnName: "memory", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_UINT64},
+		{ColumnName: "cpu", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_FLOAT64},
+		{ColumnName: "temperature", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_INT64},
+		{ColumnName: "running", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_BOOLEAN},
+		{ColumnName: "ts", SemanticType: gpb.SemanticType_TIMESTAMP, Datatype: gpb.ColumnDataType_TIMESTAMP_MILLISECOND},
+	}
+}
+
+// GreptimeRow implements schema.Encoder.
+func (v *Monitor) GreptimeRow() (*gpb.Row, error) {
+	if v == nil {
+		return nil, errs.ErrNilObject
+	}
+
+	values := make([]*gpb.Value, 7)
+	values[0] = &gpb.Value{ValueData: &gpb.Value_I64Value{I64Value: int64(v.ID)}}
+	values[1] = &gpb.Value{ValueData: &gpb.Value_StringValue{StringValue: string(v.Host)}}
+	values[2] = &gpb.Value{ValueData: &gpb.Value_U64Value{U64Value: uint64(v.Memory)}}
+	values[3] = &gpb.Value{ValueData: &gpb.Value_F64Value{F64Value: float64(v.Cpu)}}
+	values[4] = &gpb.Value{ValueData: &gpb.Value_I64Value{I64Value: int64(v.Temperature)}}
+	values[5] = &gpb.Value{ValueData: &gpb.Value_BoolValue{BoolValue: bool(v.Running)}}
+	values[6] = &gpb.Value{ValueData: &gpb.Value_TimestampMillisecondValue{TimestampMillisecondValue: v.Ts.UnixMilli()}}
+	return &gpb.Row{Values: values}, nil
+}
+
+// The datatypes of the custom types of Event are inferred like schema.Parse.
+var (
+	greptimeEventOwnerType, greptimeEventOwnerTypeErr = cell.CustomType(new(UserID))
+)
+
+// GreptimeColumns implements schema.Encoder.
+func (*Event) GreptimeColumns() []*gpb.ColumnSchema {
+	return []*gpb.ColumnSchema{
+		{ColumnName: "status", SemanticType: gpb.SemanticType_TAG, Datatype: gpb.ColumnDataType_STRING},
+		{ColumnName: "count", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_INT16},
+		{ColumnName: "ratio", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_FLOAT32},
+		{ColumnName: "payload", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_BINARY},
+		{ColumnName: "digest", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_BINARY},
+		{ColumnName: "labels", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_JSON},
+		{ColumnName: "attrs", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_BINARY, DatatypeExtension: &gpb.ColumnDataTypeExtension{TypeExt: &gpb.ColumnDataTypeExtension_JsonType{JsonType: gpb.JsonTypeExtension_JSON_BINARY}}},
+		{ColumnName: "raw", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_JSON},
+		{ColumnName: "price", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_DECIMAL128, DatatypeExtension: &gpb.ColumnDataTypeExtension{TypeExt: &gpb.ColumnDataTypeExtension_DecimalType{DecimalType: &gpb.DecimalTypeExtension{Precision: 10, Scale: 2}}}},
+		{ColumnName: "elapsed", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_TIME_MILLISECOND},
+		{ColumnName: "interval", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_INTERVAL_MONTH_DAY_NANO},
+		{ColumnName: "day", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_DATE},
+		{ColumnName: "created", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_TIMESTAMP_SECOND},
+		{ColumnName: "level", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_STRING},
+		{ColumnName: "user", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_UINT32},
+		{ColumnName: "owner", SemanticType: gpb.SemanticType_FIELD, Datatype: greptimeEventOwnerType},
+		{ColumnName: "name", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_STRING},
+		{ColumnName: "addr", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_STRING},
+		{ColumnName: "ts", SemanticType: gpb.SemanticType_TIMESTAMP, Datatype: gpb.ColumnDataType_TIMESTAMP_NANOSECOND},
+	}
+}
+
+// GreptimeRow implements schema.Encoder.
+func (v *Event) GreptimeRow() (*gpb.Row, error) {
+	if v == nil {
+		return nil, errs.ErrNilObject
+	}
+	if greptimeEventOwnerTypeErr != nil {
+		return nil, greptimeEventOwnerTypeErr
+	}
+
+	values := make([]*gpb.Value, 19)
+	var err error
+	values[0] = &gpb.Value{ValueData: &gpb.Value_StringValue{StringValue: string(v.Status)}}
+	if v.Count != nil {
+		values[1] = &gpb.Value{ValueData: &gpb.Value_I16Value{I16Value: int32(*v.Count)}}
+	}
+	if v.Ratio != nil {
+		values[2] = &gpb.Value{ValueData: &gpb.Value_F32Value{F32Value: float32(*v.Ratio)}}
+	}
+	values[3] = &gpb.Value{ValueData: &gpb.Value_BinaryValue{BinaryValue: []byte(v.Payload)}}
+	values[4] = &gpb.Value{ValueData: &gpb.Value_BinaryValue{BinaryValue: v.Digest[:]}}
+	if v.Labels != nil {
+		if values[5], err = cell.New(v.Labels, gpb.ColumnDataType_JSON).Build(); err != nil {
+			return nil, err
+		}
+	}
+	if v.Attrs != nil {
+		if values[6], err = cell.New(v.Attrs, gpb.ColumnDataType_BINARY).WithExtension(&gpb.ColumnDataTypeExtension{TypeExt: &gpb.ColumnDataTypeExtension_JsonType{JsonType: gpb.JsonTypeExtension_JSON_BINARY}}).Build(); err != nil {
+			return nil, err
+		}
+	}
+	if v.Raw != nil {
+		if values[7], err = cell.New(v.Raw, gpb.ColumnDataType_JSON).Build(); err != nil {
+			return nil, err
+		}
+	}
+	if values[8], err = cell.New(&v.Price, gpb.ColumnDataType_DECIMAL128).WithExtension(&gpb.ColumnDataTypeExtension{TypeExt: &gpb.ColumnDataTypeExtension_DecimalType{DecimalType: &gpb.DecimalTypeExtension{Precision: 10, Scale: 2}}}).Build(); err != nil {
+		return nil, err
+	}
+	if values[9], err = cell.New(v.Elapsed, gpb.ColumnDataType_TIME_MILLISECOND).Build(); err != nil {
+		return nil, err
+	}
+	if values[10], err = cell.New(v.Interval, gpb.ColumnDataType_INTERVAL_MONTH_DAY_NANO).Build(); err != nil {
+		return nil, err
+	}
+	if values[11], err = cell.New(v.Day, gpb.ColumnDataType_DATE).Build(); err != nil {
+		return nil, err
+	}
+	if values[12], err = cell.New(int64(v.Created), gpb.ColumnDataType_TIMESTAMP_SECOND).Build(); err != nil {
+		return nil, err
+	}
+	if values[13], err = cell.New(&v.Level, gpb.ColumnDataType_STRING).Build(); err != nil {
+		return nil, err
+	}
+	if v.User != nil {
+		if values[14], err = cell.New(v.User, gpb.ColumnDataType_UINT32).Build(); err != nil {
+			return nil, err
+		}
+	}
+	if values[15], err = cell.New(&v.Owner, greptimeEventOwnerType).Build(); err != nil {
+		return nil, err
+	}
+	if values[16], err = cell.New(&v.Name, gpb.ColumnDataType_STRING).Build(); err != nil {
+		return nil, err
+	}
+	if values[17], err = cell.New(&v.Addr, gpb.ColumnDataType_STRING).Build(); err != nil {
+		return nil, err
+	}
+	if v.Ts != nil {
+		values[18] = &gpb.Value{ValueData: &gpb.Value_TimestampNanosecondValue{TimestampNanosecondValue: v.Ts.UnixNano()}}
+	}
+	return &gpb.Row{Values: values}, nil
+}
+
+// GreptimeColumns implements schema.Encoder.
+func (*Metric) GreptimeColumns() []*gpb.ColumnSchema {
+	return []*gpb.ColumnSchema{
+		{ColumnName: "region", SemanticType: gpb.SemanticType_TAG, Datatype: gpb.ColumnDataType_STRING},
+		{ColumnName: "zone", SemanticType: gpb.SemanticType_TAG, Datatype: gpb.ColumnDataType_STRING},
+		{ColumnName: "res_host", SemanticType: gpb.SemanticType_TAG, Datatype: gpb.ColumnDataType_STRING},
+		{ColumnName: "res_version", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_STRING},
+		{ColumnName: "value", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_FLOAT64},
+		{ColumnName: "ts", SemanticType: gpb.SemanticType_TIMESTAMP, Datatype: gpb.ColumnDataType_TIMESTAMP_MILLISECOND},
+	}
+}
+
+// GreptimeRow implements schema.Encoder.
+func (v *Metric) GreptimeRow() (*gpb.Row, error) {
+	if v == nil {
+		return nil, errs.ErrNilObject
+	}
+
+	values := make([]*gpb.Value, 6)
+	values[0] = &gpb.Value{ValueData: &gpb.Value_StringValue{StringValue: string(v.Labels.Region)}}
+	if v.Labels.Zone != nil {
+		values[1] = &gpb.Value{ValueData: &gpb.Value_StringValue{StringValue: string(*v.Labels.Zone)}}
+	}
+	if v.Resource != nil {
+		values[2] = &gpb.Value{ValueData: &gpb.Value_StringValue{StringValue: string(v.Resource.Host)}}
+	}
+	if v.Resource != nil {
+		values[3] = &gpb.Value{ValueData: &gpb.Value_StringValue{StringValue: string(v.Resource.Version)}}
+	}
+	values[4] = &gpb.Value{ValueData: &gpb.Value_F64Value{F64Value: float64(v.Value)}}
+	values[5] = &gpb.Value{ValueData: &gpb.Value_TimestampMillisecondValue{TimestampMillisecondValue: v.Ts.UnixMilli()}}
+	return &gpb.Row{Values: values}, nil
+}
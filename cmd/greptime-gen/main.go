@@ -0,0 +1,152 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Greptime-gen generates the schema.Encoder methods of the structs with greptime tags, so
+// the rows are built without reflection. The tags are the same as the ORM style of the
+// client, see the README for the grammar.
+//
+// Add the directive in the package of the structs, and run go generate:
+//
+//	//go:generate go run github.com/GreptimeTeam/greptimedb-ingester-go/cmd/greptime-gen -type=Monitor,Cpu
+//
+// The methods are written into <type>_greptime.go by default, which is the lower case of
+// the first type.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	typeNames = flag.String("type", "", "comma-separated list of struct names, required")
+	output    = flag.String("output", "", "output file name, default is <type>_greptime.go")
+	header    = flag.String("header", "", "file of the header comment, like the license")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage of greptime-gen:\n")
+	fmt.Fprintf(os.Stderr, "\tgreptime-gen [flags] -type T [package]\n")
+	fmt.Fprintf(os.Stderr, "Flags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("greptime-gen: ")
+	flag.Usage = usage
+	flag.Parse()
+
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	names := strings.Split(*typeNames, ",")
+
+	dir := "."
+	if args := flag.Args(); len(args) == 1 {
+		dir = args[0]
+	} else if len(args) > 1 {
+		log.Fatal("only one package directory is supported")
+	}
+
+	headerText := ""
+	if *header != "" {
+		data, err := os.ReadFile(*header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		headerText = string(data)
+	}
+
+	pkg, pkgDir, err := loadPackage(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := generate(pkg, names, headerText)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	name := *output
+	if name == "" {
+		name = strings.ToLower(names[0]) + "_greptime.go"
+	}
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(pkgDir, name)
+	}
+	if err := os.WriteFile(name, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// loadPackage type-checks the package in the directory, the imported packages are loaded
+// from the export data of the compiler.
+func loadPackage(dir string) (*types.Package, string, error) {
+	buildPkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, "", err
+	}
+
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(buildPkg.GoFiles))
+	for _, name := range buildPkg.GoFiles {
+		file, err := parser.ParseFile(fset, filepath.Join(buildPkg.Dir, name), nil, 0)
+		if err != nil {
+			return nil, "", err
+		}
+		files = append(files, file)
+	}
+
+	cfg := &types.Config{
+		Importer: importer.ForCompiler(fset, "gc", exportLookup(buildPkg.Dir)),
+		// the methods generated before may be invalid
+		Error: func(error) {},
+	}
+	pkg, err := cfg.Check(buildPkg.ImportPath, fset, files, nil)
+	if pkg == nil {
+		return nil, "", err
+	}
+	return pkg, buildPkg.Dir, nil
+}
+
+// exportLookup finds the export data of the imported package by go list, which knows the
+// modules of the directory.
+func exportLookup(dir string) importer.Lookup {
+	return func(path string) (io.ReadCloser, error) {
+		cmd := exec.Command("go", "list", "-export", "-f", "{{.Export}}", path)
+		cmd.Dir = dir
+		cmd.Stderr = os.Stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("go list %s: %w", path, err)
+		}
+		return os.Open(strings.TrimSpace(string(out)))
+	}
+}
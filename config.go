@@ -17,6 +17,7 @@
 package greptime
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -25,6 +26,7 @@ import (
 
 	"google.golang.org/grpc"
 
+	ingesterContext "github.com/GreptimeTeam/greptimedb-ingester-go/context"
 	"github.com/GreptimeTeam/greptimedb-ingester-go/options"
 )
 
@@ -44,6 +46,8 @@ type Config struct {
 	Database string // the default database
 
 	tls     *options.TlsOption
+	retry   *options.RetryOption
+	hints   []ingesterContext.Option
 	options []grpc.DialOption
 
 	telemetry *options.TelemetryOptions
@@ -94,13 +98,44 @@ func (c *Config) WithKeepalive(time, timeout time.Duration) *Config {
 	return c
 }
 
-// TODO(yuanbohan): support more tls options
+// WithInsecure helps to connect without TLS if insecure is true, otherwise TLS with
+// the system CAs is used. Use WithTls for CAs, client certificates, etc.
 func (c *Config) WithInsecure(insecure bool) *Config {
 	opt := options.NewTlsOption(insecure)
 	c.tls = &opt
 	return c
 }
 
+// WithTls helps to specify the TLS settings, like the private CAs and client
+// certificate for mTLS. The files are loaded when the client is created.
+//
+//	cfg.WithTls(options.NewTlsOption(false).
+//		WithCaFiles("/path/to/ca.pem").
+//		WithClientCertFiles("/path/to/client.pem", "/path/to/client.key").
+//		WithReloadInterval(time.Minute))
+func (c *Config) WithTls(opt options.TlsOption) *Config {
+	c.tls = &opt
+	return c
+}
+
+// WithRetry helps to retry Write, Delete, WriteObject and DeleteObject when they fail
+// with retriable gRPC codes. Requests are not retried by default.
+//
+//	cfg.WithRetry(options.NewRetryOption())
+func (c *Config) WithRetry(opt options.RetryOption) *Config {
+	c.retry = &opt
+	return c
+}
+
+// WithDefaultHints helps to set the hints for all the requests of the client. The hints
+// set in the context of a request take precedence over the default ones.
+//
+//	cfg.WithDefaultHints(ingesterContext.WithTTL(7*24*time.Hour), ingesterContext.WithAppendMode(true))
+func (c *Config) WithDefaultHints(opts ...ingesterContext.Option) *Config {
+	c.hints = append(c.hints, opts...)
+	return c
+}
+
 // WithMetricsEnabled enables/disables collection of SDK's metrics. Disabled by default.
 func (c *Config) WithMetricsEnabled(b bool) *Config {
 	c.telemetry.Metrics.Enabled = b
@@ -142,12 +177,26 @@ func (c *Config) endpoint() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
 
-func (c *Config) build() []grpc.DialOption {
+func (c *Config) build() ([]grpc.DialOption, error) {
 	if c.tls == nil {
 		opt := options.NewTlsOption(true)
 		c.tls = &opt
 	}
 
-	c.options = append(c.options, c.tls.Build(), c.telemetry.Build())
-	return c.options
+	creds, err := c.tls.BuildCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ingesterContext.ValidateHints(ingesterContext.New(context.Background(), c.hints...)); err != nil {
+		return nil, err
+	}
+
+	opts := append([]grpc.DialOption(nil), c.options...)
+	return append(opts,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(ingesterContext.UnaryClientInterceptor(c.hints...)),
+		grpc.WithChainStreamInterceptor(ingesterContext.StreamClientInterceptor(c.hints...)),
+		c.telemetry.Build(),
+	), nil
 }
@@ -18,6 +18,7 @@ package context
 
 import (
 	"context"
+	"strings"
 
 	"google.golang.org/grpc/metadata"
 )
@@ -43,21 +44,114 @@ func New(parent context.Context, opts ...Option) context.Context {
 }
 
 // WithHint formats hints as: 'x-greptime-hint-key: value'.
+//
+// The hints are merged into the outgoing metadata of ctx, and the other metadata is kept.
+// If a hint is set more than once, by WithHint, WithHints or the typed options, the last
+// one wins. Use Hints to inspect the effective hints.
 func WithHint(hints []*Hint) Option {
 	return func(ctx context.Context) context.Context {
-		md := metadata.New(nil)
+		md := outgoingMetadata(ctx)
+		combined := parseHints(md.Get(hintsPrefix))
 		for _, hint := range hints {
-			md.Append(hintKeyPrefix+hint.Key, hint.Value)
+			key := normalizeHintKey(hint.Key)
+			md.Set(hintKeyPrefix+key, hint.Value)
+			combined = combined.without(key)
 		}
+		combined.setTo(md)
 		return metadata.NewOutgoingContext(ctx, md)
 	}
 }
 
 // WithHints formats hints as: 'x-greptime-hints: key1=value1,key2=value2'.
+//
+// The hints are merged into the existing 'x-greptime-hints' of ctx, see WithHint
+// for the precedence.
 func WithHints(hints string) Option {
 	return func(ctx context.Context) context.Context {
-		md := metadata.New(nil)
-		md.Append(hintsPrefix, hints)
+		md := outgoingMetadata(ctx)
+		combined := parseHints(md.Get(hintsPrefix))
+		for _, hint := range parseHints([]string{hints}) {
+			md.Delete(hintKeyPrefix + hint.Key)
+			combined = append(combined.without(hint.Key), hint)
+		}
+		combined.setTo(md)
 		return metadata.NewOutgoingContext(ctx, md)
 	}
 }
+
+// Hints returns the effective hints in the outgoing metadata of ctx, which is for
+// debugging. The default hints of Config are not included, since they are only
+// applied when the request is sent.
+//
+// GreptimeDB reads 'x-greptime-hints' first, and then 'x-greptime-hint-key'
+// overrides the same key.
+func Hints(ctx context.Context) map[string]string {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	return effectiveHints(md)
+}
+
+func effectiveHints(md metadata.MD) map[string]string {
+	hints := make(map[string]string)
+	for _, hint := range parseHints(md.Get(hintsPrefix)) {
+		hints[hint.Key] = hint.Value
+	}
+	for key, values := range md {
+		if strings.HasPrefix(key, hintKeyPrefix) && len(values) > 0 {
+			hints[strings.TrimPrefix(key, hintKeyPrefix)] = values[len(values)-1]
+		}
+	}
+	return hints
+}
+
+func outgoingMetadata(ctx context.Context) metadata.MD {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		md = metadata.New(nil)
+	}
+	return md
+}
+
+func normalizeHintKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+type hintList []Hint
+
+// parseHints parses the values of 'x-greptime-hints', the later key wins.
+func parseHints(values []string) hintList {
+	var hints hintList
+	for _, value := range values {
+		for _, pair := range strings.Split(value, ",") {
+			key, val, ok := strings.Cut(pair, "=")
+			key = normalizeHintKey(key)
+			if !ok || key == "" {
+				continue
+			}
+			hints = append(hints.without(key), Hint{Key: key, Value: strings.TrimSpace(val)})
+		}
+	}
+	return hints
+}
+
+func (l hintList) without(key string) hintList {
+	out := l[:0:0]
+	for _, hint := range l {
+		if hint.Key != key {
+			out = append(out, hint)
+		}
+	}
+	return out
+}
+
+func (l hintList) setTo(md metadata.MD) {
+	if len(l) == 0 {
+		md.Delete(hintsPrefix)
+		return
+	}
+
+	pairs := make([]string, 0, len(l))
+	for _, hint := range l {
+		pairs = append(pairs, hint.Key+"="+hint.Value)
+	}
+	md.Set(hintsPrefix, strings.Join(pairs, ","))
+}
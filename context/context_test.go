@@ -0,0 +1,61 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package context
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestHintsMergeWithMetadata(t *testing.T) {
+	parent := metadata.AppendToOutgoingContext(context.Background(), "traceparent", "00-abc-01", "x-tenant", "a")
+
+	ctx := New(parent,
+		WithHint([]*Hint{{Key: "ttl", Value: "1d"}, {Key: "merge_mode", Value: "last_row"}}),
+		WithHints("append_mode=false, ttl=3d"),
+	)
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	assert.Equal(t, []string{"00-abc-01"}, md.Get("traceparent"))
+	assert.Equal(t, []string{"a"}, md.Get("x-tenant"))
+
+	// the later WithHints wins for ttl
+	assert.Empty(t, md.Get("x-greptime-hint-ttl"))
+	assert.Equal(t, []string{"append_mode=false,ttl=3d"}, md.Get("x-greptime-hints"))
+	assert.Equal(t, map[string]string{"ttl": "3d", "merge_mode": "last_row", "append_mode": "false"}, Hints(ctx))
+
+	// the later WithHint wins for append_mode
+	ctx = New(ctx, WithHint([]*Hint{{Key: "Append_Mode", Value: "true"}}), WithHints("skip_wal=true"))
+	md, _ = metadata.FromOutgoingContext(ctx)
+	assert.Equal(t, []string{"ttl=3d,skip_wal=true"}, md.Get("x-greptime-hints"))
+	assert.Equal(t, []string{"true"}, md.Get("x-greptime-hint-append_mode"))
+	assert.Equal(t, map[string]string{"ttl": "3d", "merge_mode": "last_row", "append_mode": "true", "skip_wal": "true"}, Hints(ctx))
+}
+
+func TestHintsOfExternalMetadata(t *testing.T) {
+	// hints appended by other code, the hint of the key overrides x-greptime-hints like GreptimeDB
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"x-greptime-hints", "ttl=1d,append_mode=true",
+		"x-greptime-hints", "ttl=2d",
+		"x-greptime-hint-append_mode", "false",
+	)
+	assert.Equal(t, map[string]string{"ttl": "2d", "append_mode": "false"}, Hints(ctx))
+	assert.Empty(t, Hints(context.Background()))
+}
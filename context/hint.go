@@ -0,0 +1,146 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package context
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HintTTL             = "ttl"
+	HintAppendMode      = "append_mode"
+	HintMergeMode       = "merge_mode"
+	HintPhysicalTable   = "physical_table"
+	HintSkipWAL         = "skip_wal"
+	HintAutoCreateTable = "auto_create_table"
+)
+
+// MergeMode is how the rows of the same primary key and timestamp are merged.
+type MergeMode string
+
+const (
+	// MergeModeLastRow keeps the last row.
+	MergeModeLastRow MergeMode = "last_row"
+	// MergeModeLastNonNull keeps the last non-null value of each field.
+	MergeModeLastNonNull MergeMode = "last_non_null"
+)
+
+type invalidHintsKey struct{}
+
+// WithTTL sets the time to live of the auto created table, ttl MUST be positive
+// and is truncated to milliseconds.
+func WithTTL(ttl time.Duration) Option {
+	if ttl < time.Millisecond {
+		return withInvalidHint(fmt.Errorf("invalid hint %s: %s, must be at least 1ms", HintTTL, ttl))
+	}
+	return withHintValue(HintTTL, formatTTL(ttl))
+}
+
+// WithAppendMode sets if the auto created table is append-only, which
+// does not deduplicate the rows.
+func WithAppendMode(appendMode bool) Option {
+	return withHintValue(HintAppendMode, strconv.FormatBool(appendMode))
+}
+
+// WithMergeMode sets the merge mode of the auto created table.
+// It can not be used with WithAppendMode(true).
+func WithMergeMode(mode MergeMode) Option {
+	switch mode {
+	case MergeModeLastRow, MergeModeLastNonNull:
+		return withHintValue(HintMergeMode, string(mode))
+	default:
+		return withInvalidHint(fmt.Errorf("invalid hint %s: %q", HintMergeMode, mode))
+	}
+}
+
+// WithPhysicalTable writes the data into the logical tables of the physical table
+// of the metric engine, the physical table is created if not exists.
+func WithPhysicalTable(name string) Option {
+	if strings.TrimSpace(name) == "" {
+		return withInvalidHint(fmt.Errorf("invalid hint %s: name should not be empty", HintPhysicalTable))
+	}
+	return withHintValue(HintPhysicalTable, name)
+}
+
+// WithSkipWAL sets if the auto created table skips the write-ahead log, which is
+// faster but the data not flushed yet is lost after crash.
+func WithSkipWAL(skip bool) Option {
+	return withHintValue(HintSkipWAL, strconv.FormatBool(skip))
+}
+
+// WithAutoCreateTable sets if the table is created automatically on the first insert.
+func WithAutoCreateTable(auto bool) Option {
+	return withHintValue(HintAutoCreateTable, strconv.FormatBool(auto))
+}
+
+// ValidateHints returns the errors of the invalid hints in ctx, and the hints
+// which conflict with each other. The Client validates them before sending.
+func ValidateHints(ctx context.Context) error {
+	errs, _ := ctx.Value(invalidHintsKey{}).([]error)
+
+	hints := Hints(ctx)
+	if _, ok := hints[HintMergeMode]; ok && hints[HintAppendMode] == "true" {
+		errs = append(errs, fmt.Errorf("hint %s can not be used with %s=true", HintMergeMode, HintAppendMode))
+	}
+	return errors.Join(errs...)
+}
+
+// withHintValue sets the hint like WithHint. The value MUST NOT contain ',' or '=',
+// which separate the hints in x-greptime-hints.
+func withHintValue(key, value string) Option {
+	if strings.ContainsAny(value, ",=") {
+		return withInvalidHint(fmt.Errorf("invalid hint %s: %q, must not contain ',' or '='", key, value))
+	}
+	return WithHint([]*Hint{{Key: key, Value: value}})
+}
+
+func withInvalidHint(err error) Option {
+	return func(ctx context.Context) context.Context {
+		errs, _ := ctx.Value(invalidHintsKey{}).([]error)
+		errs = append(errs[:len(errs):len(errs)], err)
+		return context.WithValue(ctx, invalidHintsKey{}, errs)
+	}
+}
+
+// formatTTL formats ttl as the human readable duration GreptimeDB accepts, like 1d12h30m.
+func formatTTL(ttl time.Duration) string {
+	units := []struct {
+		unit   time.Duration
+		suffix string
+	}{
+		{24 * time.Hour, "d"},
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+		{time.Second, "s"},
+		{time.Millisecond, "ms"},
+	}
+
+	var sb strings.Builder
+	for _, u := range units {
+		if n := ttl / u.unit; n > 0 {
+			sb.WriteString(strconv.FormatInt(int64(n), 10))
+			sb.WriteString(u.suffix)
+			ttl -= n * u.unit
+		}
+	}
+	return sb.String()
+}
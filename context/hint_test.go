@@ -0,0 +1,102 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package context
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestTypedHints(t *testing.T) {
+	parent := metadata.AppendToOutgoingContext(context.Background(), "x-tenant", "a")
+	ctx := New(parent,
+		WithTTL(36*time.Hour+30*time.Minute),
+		WithAppendMode(false),
+		WithMergeMode(MergeModeLastNonNull),
+		WithPhysicalTable("greptime_physical_table"),
+		WithSkipWAL(true),
+		WithAutoCreateTable(true),
+		WithTTL(7*24*time.Hour),
+	)
+	assert.Nil(t, ValidateHints(ctx))
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, metadata.Pairs(
+		"x-tenant", "a",
+		"x-greptime-hint-ttl", "7d",
+		"x-greptime-hint-append_mode", "false",
+		"x-greptime-hint-merge_mode", "last_non_null",
+		"x-greptime-hint-physical_table", "greptime_physical_table",
+		"x-greptime-hint-skip_wal", "true",
+		"x-greptime-hint-auto_create_table", "true",
+	), md)
+}
+
+func TestInvalidHints(t *testing.T) {
+	ctx := New(context.Background(), WithTTL(0), WithMergeMode("last"), WithPhysicalTable(" "))
+	err := ValidateHints(ctx)
+	assert.ErrorContains(t, err, "ttl")
+	assert.ErrorContains(t, err, "merge_mode")
+	assert.ErrorContains(t, err, "physical_table")
+
+	ctx = New(context.Background(), WithAppendMode(true), WithMergeMode(MergeModeLastRow))
+	assert.ErrorContains(t, ValidateHints(ctx), "can not be used with append_mode=true")
+
+	ctx = New(context.Background(), WithPhysicalTable("a,ttl=1d"))
+	assert.ErrorContains(t, ValidateHints(ctx), "must not contain")
+	_, ok := Hints(ctx)[HintPhysicalTable]
+	assert.False(t, ok)
+}
+
+func TestFormatTTL(t *testing.T) {
+	assert.Equal(t, "1d12h30m", formatTTL(36*time.Hour+30*time.Minute))
+	assert.Equal(t, "1s500ms", formatTTL(1500*time.Millisecond+time.Microsecond))
+	assert.Equal(t, "90d", formatTTL(90*24*time.Hour))
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	interceptor := UnaryClientInterceptor(WithTTL(24*time.Hour), WithAppendMode(true))
+
+	var got metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		got, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+
+	ctx := New(context.Background(), WithTTL(time.Hour))
+	assert.Nil(t, interceptor(ctx, "", nil, nil, nil, invoker))
+	assert.Equal(t, []string{"1h"}, got.Get("x-greptime-hint-ttl"))
+	assert.Equal(t, []string{"true"}, got.Get("x-greptime-hint-append_mode"))
+
+	// the default hint does not override the hint of x-greptime-hints
+	ctx = New(context.Background(), WithHints("ttl=2h"))
+	assert.Nil(t, interceptor(ctx, "", nil, nil, nil, invoker))
+	assert.Empty(t, got.Get("x-greptime-hint-ttl"))
+	assert.Equal(t, "2h", effectiveHints(got)["ttl"])
+
+	ctx = New(context.Background(), WithMergeMode(MergeModeLastRow))
+	assert.NotNil(t, interceptor(ctx, "", nil, nil, nil, invoker))
+
+	invalid := UnaryClientInterceptor(WithTTL(-time.Hour))
+	assert.NotNil(t, invalid(context.Background(), "", nil, nil, nil, invoker))
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package context
+
+import (
+	"context"
+	"sort"
+
+	"google.golang.org/grpc"
+)
+
+// UnaryClientInterceptor validates the hints of every request, see ValidateHints,
+// and sets the default hints which are not in the effective hints of the request.
+func UnaryClientInterceptor(defaults ...Option) grpc.UnaryClientInterceptor {
+	h := newDefaultHints(defaults)
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, err := h.apply(ctx)
+		if err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor is like UnaryClientInterceptor for the streams.
+func StreamClientInterceptor(defaults ...Option) grpc.StreamClientInterceptor {
+	h := newDefaultHints(defaults)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, err := h.apply(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+type defaultHints struct {
+	hints []Hint
+	err   error
+}
+
+func newDefaultHints(defaults []Option) *defaultHints {
+	ctx := New(context.Background(), defaults...)
+
+	h := &defaultHints{err: ValidateHints(ctx)}
+	for key, value := range Hints(ctx) {
+		h.hints = append(h.hints, Hint{Key: key, Value: value})
+	}
+	sort.Slice(h.hints, func(i, j int) bool { return h.hints[i].Key < h.hints[j].Key })
+	return h
+}
+
+func (h *defaultHints) apply(ctx context.Context) (context.Context, error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	if len(h.hints) > 0 {
+		hints := Hints(ctx)
+		defaults := make([]*Hint, 0, len(h.hints))
+		for _, hint := range h.hints {
+			if _, ok := hints[hint.Key]; !ok {
+				defaults = append(defaults, &Hint{Key: hint.Key, Value: hint.Value})
+			}
+		}
+		if len(defaults) > 0 {
+			ctx = WithHint(defaults)(ctx)
+		}
+	}
+
+	if err := ValidateHints(ctx); err != nil {
+		return nil, err
+	}
+	return ctx, nil
+}
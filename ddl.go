@@ -0,0 +1,309 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package greptime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/request"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/schema"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+)
+
+// CreateTableOptions is to define how the table is created.
+//
+//   - IfNotExists is to skip creating if the table exists. Default is true.
+//   - Engine is the table engine, empty means the default engine of GreptimeDB.
+//   - Comment is the comment of the table.
+//   - TableOptions are the [table options], like ttl, append_mode, etc.
+//   - FulltextIndexes are the full-text indexes of the string columns by name.
+//
+// PARTITION ON COLUMNS and the INVERTED and SKIPPING indexes are not supported yet,
+// since the CreateTableExpr of GreptimeDB protocol has no place for them. Create the
+// table by SQL if they are needed.
+//
+// [table options]: https://docs.greptime.com/reference/sql/create#table-options
+type CreateTableOptions struct {
+	IfNotExists     bool
+	Engine          string
+	Comment         string
+	TableOptions    map[string]string
+	FulltextIndexes map[string]*FulltextOptions
+}
+
+// FulltextOptions is to define the full-text index of a string column.
+//
+//   - Analyzer is the text analyzer, "English" or "Chinese". Empty means "English".
+//   - CaseSensitive is whether the full-text search is case-sensitive.
+type FulltextOptions struct {
+	Analyzer      string
+	CaseSensitive bool
+}
+
+// NewCreateTableOptions helps to init CreateTableOptions with default values.
+func NewCreateTableOptions() *CreateTableOptions {
+	return &CreateTableOptions{IfNotExists: true}
+}
+
+// WithIfNotExists set the IfNotExists field.
+func (o *CreateTableOptions) WithIfNotExists(ifNotExists bool) *CreateTableOptions {
+	o.IfNotExists = ifNotExists
+	return o
+}
+
+// WithEngine set the Engine field.
+func (o *CreateTableOptions) WithEngine(engine string) *CreateTableOptions {
+	o.Engine = engine
+	return o
+}
+
+// WithComment set the Comment field.
+func (o *CreateTableOptions) WithComment(comment string) *CreateTableOptions {
+	o.Comment = comment
+	return o
+}
+
+// WithTableOption adds a table option, like WithTableOption("ttl", "7d").
+func (o *CreateTableOptions) WithTableOption(key, value string) *CreateTableOptions {
+	if o.TableOptions == nil {
+		o.TableOptions = make(map[string]string)
+	}
+	o.TableOptions[key] = value
+	return o
+}
+
+// WithFulltextIndex adds the full-text index of the string column. opts can be nil
+// to use the default options.
+func (o *CreateTableOptions) WithFulltextIndex(column string, opts *FulltextOptions) *CreateTableOptions {
+	if o.FulltextIndexes == nil {
+		o.FulltextIndexes = make(map[string]*FulltextOptions)
+	}
+	if opts == nil {
+		opts = &FulltextOptions{}
+	}
+	o.FulltextIndexes[column] = opts
+	return o
+}
+
+// applyFulltextIndexes sets the fulltext option of the columns in expr.
+func (o *CreateTableOptions) applyFulltextIndexes(expr *gpb.CreateTableExpr) error {
+	for column, opts := range o.FulltextIndexes {
+		var def *gpb.ColumnDef
+		for _, def_ := range expr.ColumnDefs {
+			if def_.Name == column {
+				def = def_
+				break
+			}
+		}
+		if def == nil {
+			return fmt.Errorf("fulltext index on %q: %w", column, errs.ErrUnknownColumn)
+		}
+		if def.DataType != gpb.ColumnDataType_STRING {
+			return fmt.Errorf("fulltext index on %q: column of %v is not supported", column, def.DataType)
+		}
+
+		analyzer := opts.Analyzer
+		if analyzer == "" {
+			analyzer = "English"
+		}
+		fulltext, err := json.Marshal(map[string]any{
+			"enable":         true,
+			"analyzer":       analyzer,
+			"case-sensitive": opts.CaseSensitive,
+		})
+		if err != nil {
+			return err
+		}
+
+		// the options of the column may be shared with the table
+		options := make(map[string]string, len(def.GetOptions().GetOptions())+1)
+		for key, value := range def.GetOptions().GetOptions() {
+			options[key] = value
+		}
+		options["fulltext"] = string(fulltext)
+		def.Options = &gpb.ColumnOptions{Options: options}
+	}
+	return nil
+}
+
+// AlterTableOptions is to define how the table is altered.
+//
+//   - TableOptions are the table options to change, like ttl.
+type AlterTableOptions struct {
+	TableOptions map[string]string
+}
+
+// NewAlterTableOptions helps to init AlterTableOptions.
+func NewAlterTableOptions() *AlterTableOptions {
+	return &AlterTableOptions{}
+}
+
+// WithTableOption adds a table option to change, like WithTableOption("ttl", "30d").
+func (o *AlterTableOptions) WithTableOption(key, value string) *AlterTableOptions {
+	if o.TableOptions == nil {
+		o.TableOptions = make(map[string]string)
+	}
+	o.TableOptions[key] = value
+	return o
+}
+
+// CreateTable creates the table with the columns of tbl, rows of tbl are ignored.
+// The tag columns are the primary keys, and the timestamp column is the time index.
+//
+//	tbl, err := table.New("monitor")
+//	tbl.AddTagColumn("host", types.STRING)
+//	tbl.AddFieldColumn("cpu", types.FLOAT64)
+//	tbl.AddTimestampColumn("ts", types.TIMESTAMP_MILLISECOND)
+//
+//	resp, err := client.CreateTable(context.Background(), tbl,
+//		greptime.NewCreateTableOptions().WithTableOption("ttl", "7d"))
+func (c *Client) CreateTable(ctx context.Context, tbl *table.Table, opts *CreateTableOptions) (*gpb.GreptimeResponse, error) {
+	if opts == nil {
+		opts = NewCreateTableOptions()
+	}
+
+	expr, err := tbl.ToCreateTableExpr()
+	if err != nil {
+		return nil, err
+	}
+	expr.CreateIfNotExists = opts.IfNotExists
+	expr.Engine = opts.Engine
+	expr.Desc = opts.Comment
+	expr.TableOptions = opts.TableOptions
+	if err := opts.applyFulltextIndexes(expr); err != nil {
+		return nil, err
+	}
+
+	request_, err := request.BuildCreateTable(c.newHeader(), expr)
+	if err != nil {
+		return nil, err
+	}
+	return c.client.Handle(ctx, request_)
+}
+
+// CreateTableObject is like [CreateTable] to create the table, but schema is defined in the struct tag.
+// obj can be a zero value or a nil pointer of the struct.
+//
+//	resp, err := client.CreateTableObject(context.Background(), Monitor{}, nil)
+func (c *Client) CreateTableObject(ctx context.Context, obj any, opts *CreateTableOptions) (*gpb.GreptimeResponse, error) {
+	tbl, err := schema.ParseSchema(obj)
+	if err != nil {
+		return nil, err
+	}
+	return c.CreateTable(ctx, tbl, opts)
+}
+
+// AlterTable adds the columns of tbl which do not exist in the table yet, and then
+// changes the table options if any. The existing columns are queried first, and the
+// timestamp column can not be added. It returns the response of the last request,
+// or nil if there is nothing to alter.
+//
+//	// tbl has a new field column "memory"
+//	resp, err := client.AlterTable(context.Background(), tbl, nil)
+func (c *Client) AlterTable(ctx context.Context, tbl *table.Table, opts *AlterTableOptions) (*gpb.GreptimeResponse, error) {
+	name, err := tbl.GetName()
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := c.tableColumns(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) == 0 {
+		return nil, fmt.Errorf("table %q does not exist", name)
+	}
+
+	var addColumns []*gpb.AddColumn
+	for _, def := range tbl.ToColumnDefs() {
+		if existing[def.Name] {
+			continue
+		}
+		if def.SemanticType == gpb.SemanticType_TIMESTAMP {
+			return nil, fmt.Errorf("timestamp column %q can not be added to table %q", def.Name, name)
+		}
+		addColumns = append(addColumns, &gpb.AddColumn{ColumnDef: def})
+	}
+
+	var resp *gpb.GreptimeResponse
+	if len(addColumns) > 0 {
+		resp, err = c.alter(ctx, &gpb.AlterExpr{
+			TableName: name,
+			Kind:      &gpb.AlterExpr_AddColumns{AddColumns: &gpb.AddColumns{AddColumns: addColumns}},
+		})
+		if err != nil {
+			return resp, err
+		}
+	}
+
+	if opts != nil && len(opts.TableOptions) > 0 {
+		options := make([]*gpb.ChangeTableOption, 0, len(opts.TableOptions))
+		for key, value := range opts.TableOptions {
+			options = append(options, &gpb.ChangeTableOption{Key: key, Value: value})
+		}
+		resp, err = c.alter(ctx, &gpb.AlterExpr{
+			TableName: name,
+			Kind:      &gpb.AlterExpr_ChangeTableOptions{ChangeTableOptions: &gpb.ChangeTableOptions{ChangeTableOptions: options}},
+		})
+	}
+	return resp, err
+}
+
+// AlterTableObject is like [AlterTable] to alter the table, but schema is defined in the struct tag.
+func (c *Client) AlterTableObject(ctx context.Context, obj any, opts *AlterTableOptions) (*gpb.GreptimeResponse, error) {
+	tbl, err := schema.ParseSchema(obj)
+	if err != nil {
+		return nil, err
+	}
+	return c.AlterTable(ctx, tbl, opts)
+}
+
+func (c *Client) alter(ctx context.Context, expr *gpb.AlterExpr) (*gpb.GreptimeResponse, error) {
+	request_, err := request.BuildAlterTable(c.newHeader(), expr)
+	if err != nil {
+		return nil, err
+	}
+	return c.client.Handle(ctx, request_)
+}
+
+// tableColumns returns the names of the columns of the table in the default database.
+func (c *Client) tableColumns(ctx context.Context, tableName string) (map[string]bool, error) {
+	sql := fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_schema = %s AND table_name = %s",
+		quoteString(c.cfg.Database), quoteString(tableName))
+	result, err := c.Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]bool, len(result.Rows))
+	for _, row := range result.Rows {
+		if name, ok := row[0].(string); ok {
+			columns[name] = true
+		}
+	}
+	return columns, nil
+}
+
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
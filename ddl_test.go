@@ -0,0 +1,125 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package greptime
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+func TestCreateAndAlterTable(t *testing.T) {
+	tableName := fmt.Sprintf("ddl_monitor_%d", randomId())
+
+	tbl, err := table.New(tableName)
+	assert.Nil(t, err)
+	assert.Nil(t, tbl.AddTagColumn("host", types.STRING))
+	assert.Nil(t, tbl.AddFieldColumn("cpu", types.FLOAT64))
+	assert.Nil(t, tbl.AddTimestampColumn("ts", types.TIMESTAMP_MILLISECOND))
+
+	opts := NewCreateTableOptions().WithTableOption("ttl", "7d").WithComment("created by ingester")
+	resp, err := cli.CreateTable(context.Background(), tbl, opts)
+	assert.Nil(t, err)
+	assert.Zero(t, resp.GetHeader().GetStatus().GetStatusCode())
+
+	// IF NOT EXISTS by default
+	_, err = cli.CreateTable(context.Background(), tbl, nil)
+	assert.Nil(t, err)
+
+	resp, err = cli.CreateTable(context.Background(), tbl, NewCreateTableOptions().WithIfNotExists(false))
+	assert.True(t, err != nil || resp.GetHeader().GetStatus().GetStatusCode() != 0)
+
+	// nothing to alter
+	resp, err = cli.AlterTable(context.Background(), tbl, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, resp)
+
+	assert.Nil(t, tbl.AddFieldColumn("memory", types.UINT64))
+	_, err = cli.AlterTable(context.Background(), tbl, NewAlterTableOptions().WithTableOption("ttl", "30d"))
+	assert.Nil(t, err)
+
+	assert.Nil(t, tbl.AddRow("127.0.0.1", 1.0, time.Now(), 1024))
+	resp, err = cli.Write(context.Background(), tbl)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), resp.GetAffectedRows().GetValue())
+
+	columns, err := cli.tableColumns(context.Background(), tableName)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]bool{"host": true, "cpu": true, "ts": true, "memory": true}, columns)
+}
+
+func TestCreateTableObject(t *testing.T) {
+	resp, err := cli.CreateTableObject(context.Background(), (*monitor)(nil), nil)
+	assert.Nil(t, err)
+	assert.Zero(t, resp.GetHeader().GetStatus().GetStatusCode())
+
+	resp, err = cli.AlterTableObject(context.Background(), monitor{}, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, resp)
+}
+
+// fakeDDLClient records the expr of the table created.
+type fakeDDLClient struct {
+	gpb.GreptimeDatabaseClient
+
+	expr *gpb.CreateTableExpr
+}
+
+func (c *fakeDDLClient) Handle(_ context.Context, req *gpb.GreptimeRequest, _ ...grpc.CallOption) (*gpb.GreptimeResponse, error) {
+	c.expr = req.GetDdl().GetCreateTable()
+	return &gpb.GreptimeResponse{}, nil
+}
+
+func TestCreateTableFulltextIndex(t *testing.T) {
+	fake := &fakeDDLClient{}
+	client := &Client{cfg: NewConfig("127.0.0.1").WithDatabase("public"), client: fake}
+
+	tbl, err := table.New("logs")
+	assert.Nil(t, err)
+	assert.Nil(t, tbl.AddTagColumn("host", types.STRING))
+	assert.Nil(t, tbl.AddFieldColumn("message", types.STRING))
+	assert.Nil(t, tbl.AddFieldColumn("level", types.INT32))
+	assert.Nil(t, tbl.AddTimestampColumn("ts", types.TIMESTAMP_MILLISECOND))
+
+	opts := NewCreateTableOptions().WithFulltextIndex("message", &FulltextOptions{Analyzer: "Chinese", CaseSensitive: true})
+	_, err = client.CreateTable(context.Background(), tbl, opts)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"enable":true,"analyzer":"Chinese","case-sensitive":true}`,
+		fake.expr.GetColumnDefs()[1].GetOptions().GetOptions()["fulltext"])
+	assert.Nil(t, fake.expr.GetColumnDefs()[0].GetOptions())
+	assert.Nil(t, tbl.GetColumnsSchema()[1].GetOptions())
+
+	_, err = client.CreateTable(context.Background(), tbl, NewCreateTableOptions().WithFulltextIndex("message", nil))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"enable":true,"analyzer":"English","case-sensitive":false}`,
+		fake.expr.GetColumnDefs()[1].GetOptions().GetOptions()["fulltext"])
+
+	_, err = client.CreateTable(context.Background(), tbl, NewCreateTableOptions().WithFulltextIndex("unknown", nil))
+	assert.ErrorIs(t, err, errs.ErrUnknownColumn)
+
+	_, err = client.CreateTable(context.Background(), tbl, NewCreateTableOptions().WithFulltextIndex("level", nil))
+	assert.NotNil(t, err)
+}
@@ -18,13 +18,57 @@ package errs
 
 import (
 	"errors"
+	"fmt"
 )
 
 var (
-	ErrEmptyName         = errors.New("name should not be empty")
-	ErrEmptyDatabaseName = errors.New("name of database should not be empty")
-	ErrEmptyTableName    = errors.New("name of table should not be empty")
-	ErrEmptyTable        = errors.New("please add at least one row")
-	ErrEmptyColumn       = errors.New("column not set, please call AddColumn first")
-	ErrInvalidOperation  = errors.New("invalid operation")
+	ErrEmptyName          = errors.New("name should not be empty")
+	ErrEmptyDatabaseName  = errors.New("name of database should not be empty")
+	ErrEmptyTableName     = errors.New("name of table should not be empty")
+	ErrEmptyTable         = errors.New("please add at least one row")
+	ErrEmptyColumn        = errors.New("column not set, please call AddColumn first")
+	ErrInvalidOperation   = errors.New("invalid operation")
+	ErrBatchWriterClosed  = errors.New("batch writer is closed")
+	ErrStreamWriterClosed = errors.New("stream writer is closed")
+	ErrEmptyQuery         = errors.New("query should not be empty")
+	ErrEmptyTimeIndex     = errors.New("timestamp column not set, please call AddTimestampColumn first")
+	ErrMultipleTimeIndex  = errors.New("a table can only have one timestamp column")
+	ErrUnknownColumn      = errors.New("unknown column")
+	ErrExporterShutdown   = errors.New("exporter is shut down")
+	ErrNilObject          = errors.New("unable to encode nil object")
+	ErrNilPointer         = errors.New("unable to parse value from nil pointer")
+	ErrDuplicateColumn    = errors.New("duplicate column")
+	ErrRequestTooLarge    = errors.New("request is too large")
 )
+
+// RetryError is returned when a request still fails after retries,
+// Attempts is how many times the request has been sent.
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("failed after %d attempt(s): %v", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// StreamRowsLostError is returned by the StreamWriter if its stream failed. The Rows
+// sent earlier on the failed stream were not acknowledged by GreptimeDB and may be
+// lost, even if the request itself is resent on a reopened stream. Err is the error
+// of the failed stream.
+type StreamRowsLostError struct {
+	Rows int
+	Err  error
+}
+
+func (e *StreamRowsLostError) Error() string {
+	return fmt.Sprintf("stream failed, %d row(s) sent may be lost: %v", e.Rows, e.Err)
+}
+
+func (e *StreamRowsLostError) Unwrap() error {
+	return e.Err
+}
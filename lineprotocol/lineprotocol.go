@@ -0,0 +1,274 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package lineprotocol parses the InfluxDB line protocol into tables, which can be
+// written by the client directly.
+//
+//	measurement[,tag=value...] field=value[,field=value...] [timestamp]
+package lineprotocol
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+// DefaultTimestampColumn is the name of the timestamp column, which is the same as the
+// InfluxDB line protocol endpoint of GreptimeDB.
+const DefaultTimestampColumn = "greptime_timestamp"
+
+// LineError is the error of an invalid line. Line starts from 1.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Errors is the errors of all the invalid lines.
+type Errors []*LineError
+
+func (e Errors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "; ")
+}
+
+// Parser parses the line protocol. Call NewParser() to create it.
+type Parser struct {
+	precision       types.TimestampPrecision
+	timestampColumn string
+	now             func() time.Time
+}
+
+// NewParser creates the parser of the nanosecond precision, which is the default of
+// InfluxDB.
+func NewParser() *Parser {
+	return &Parser{
+		precision:       types.NANOSECOND,
+		timestampColumn: DefaultTimestampColumn,
+		now:             time.Now,
+	}
+}
+
+// WithPrecision sets the precision of the timestamps, which is also the precision of
+// the timestamp columns.
+func (p *Parser) WithPrecision(precision types.TimestampPrecision) *Parser {
+	p.precision = precision
+	return p
+}
+
+// WithTimestampColumn sets the name of the timestamp column.
+func (p *Parser) WithTimestampColumn(name string) *Parser {
+	p.timestampColumn = name
+	return p
+}
+
+// Parse is like ParseReader, but parses the lines in data.
+func (p *Parser) Parse(data []byte) ([]*table.Table, error) {
+	return p.ParseReader(bytes.NewReader(data))
+}
+
+// ParseReader parses the lines in r into the tables, one table for each measurement
+// in the order they first appear. Tags are the tag columns of STRING, and fields are
+// the field columns of INT64, UINT64, FLOAT64, STRING or BOOLEAN. The lines without
+// timestamp are of the current time.
+//
+// The invalid lines, like the field of different types in different lines, are skipped,
+// and reported by the returned error of Errors. The tables of the valid lines are
+// returned in any case, unless r fails to be read.
+func (p *Parser) ParseReader(r io.Reader) ([]*table.Table, error) {
+	var (
+		measurements []*measurement
+		indexes      = make(map[string]int)
+		lineErrors   Errors
+	)
+
+	reader := bufio.NewReader(r)
+	for number := 1; ; number++ {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, readErr
+		}
+
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			if err := p.parseLine(line, &measurements, indexes); err != nil {
+				lineErrors = append(lineErrors, &LineError{Line: number, Err: err})
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	tables := make([]*table.Table, 0, len(measurements))
+	for _, m := range measurements {
+		tbl, err := m.toTable()
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, tbl)
+	}
+
+	if len(lineErrors) > 0 {
+		return tables, lineErrors
+	}
+	return tables, nil
+}
+
+func (p *Parser) parseLine(line string, measurements *[]*measurement, indexes map[string]int) error {
+	point, err := parsePoint(line)
+	if err != nil {
+		return err
+	}
+
+	row := make(map[string]any, len(point.tags)+len(point.fields)+1)
+	columns := make([]column, 0, len(point.tags)+len(point.fields)+1)
+	for _, tag := range point.tags {
+		row[tag.key] = tag.value
+		columns = append(columns, column{name: tag.key, semanticType: gpb.SemanticType_TAG, type_: types.STRING})
+	}
+	for _, field := range point.fields {
+		if _, ok := row[field.key]; ok {
+			return fmt.Errorf("%q is both tag and field", field.key)
+		}
+		row[field.key] = field.value
+		columns = append(columns, column{name: field.key, semanticType: gpb.SemanticType_FIELD, type_: field.type_})
+	}
+
+	if _, ok := row[p.timestampColumn]; ok {
+		return fmt.Errorf("%q conflicts with the timestamp column", p.timestampColumn)
+	}
+	if point.timestamp != nil {
+		row[p.timestampColumn] = *point.timestamp
+	} else {
+		row[p.timestampColumn] = p.now()
+	}
+	columns = append(columns, column{
+		name:         p.timestampColumn,
+		semanticType: gpb.SemanticType_TIMESTAMP,
+		type_:        timestampType(p.precision),
+	})
+
+	idx, ok := indexes[point.measurement]
+	if !ok {
+		idx = len(*measurements)
+		indexes[point.measurement] = idx
+		*measurements = append(*measurements, newMeasurement(point.measurement))
+	}
+	return (*measurements)[idx].addRow(columns, row)
+}
+
+// timestampType returns the timestamp column type of the precision, which is
+// millisecond for the unknown precisions like types.ParseTimestampPrecision.
+func timestampType(precision types.TimestampPrecision) types.ColumnType {
+	switch precision {
+	case types.SECOND:
+		return types.TIMESTAMP_SECOND
+	case types.MICROSECOND:
+		return types.TIMESTAMP_MICROSECOND
+	case types.NANOSECOND:
+		return types.TIMESTAMP_NANOSECOND
+	default:
+		return types.TIMESTAMP_MILLISECOND
+	}
+}
+
+type column struct {
+	name         string
+	semanticType gpb.SemanticType
+	type_        types.ColumnType
+}
+
+// measurement buffers the rows, since the columns are unknown until all lines are parsed.
+type measurement struct {
+	name    string
+	columns []column
+	indexes map[string]int
+	rows    []map[string]any
+}
+
+func newMeasurement(name string) *measurement {
+	return &measurement{name: name, indexes: make(map[string]int)}
+}
+
+func (m *measurement) addRow(columns []column, row map[string]any) error {
+	var added []column
+	for _, c := range columns {
+		if idx, ok := m.indexes[c.name]; ok {
+			if existing := m.columns[idx]; existing != c {
+				return fmt.Errorf("%q of %v %v conflicts with %v %v in the former lines",
+					c.name, c.semanticType, c.type_, existing.semanticType, existing.type_)
+			}
+			continue
+		}
+		added = append(added, c)
+	}
+
+	for _, c := range added {
+		m.indexes[c.name] = len(m.columns)
+		m.columns = append(m.columns, c)
+	}
+	m.rows = append(m.rows, row)
+	return nil
+}
+
+func (m *measurement) toTable() (*table.Table, error) {
+	tbl, err := table.New(m.name)
+	if err != nil {
+		return nil, err
+	}
+	tbl.WithSanitate(false)
+
+	for _, c := range m.columns {
+		switch c.semanticType {
+		case gpb.SemanticType_TAG:
+			err = tbl.AddTagColumn(c.name, c.type_)
+		case gpb.SemanticType_TIMESTAMP:
+			err = tbl.AddTimestampColumn(c.name, c.type_)
+		default:
+			err = tbl.AddFieldColumn(c.name, c.type_)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, row := range m.rows {
+		if err := tbl.AddRowMap(row); err != nil {
+			return nil, err
+		}
+	}
+	return tbl, nil
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lineprotocol
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+func TestParse(t *testing.T) {
+	data := `
+# comment
+cpu,host=127.0.0.1,region=us usage=0.5,cores=4i,running=true 1700000000000
+cpu,host=127.0.0.2 usage=1,load=2u 1700000001000
+weather\,daily,city=New\ York temp=-1.5e1,note="say \"hi\", \\o/" 1700000002000
+`
+	now := time.UnixMilli(1700000003000)
+	parser := NewParser().WithPrecision(types.MILLISECOND)
+	parser.now = func() time.Time { return now }
+
+	tables, err := parser.Parse([]byte(data + "cpu,host=127.0.0.3 usage=2"))
+	assert.Nil(t, err)
+	assert.Len(t, tables, 2)
+
+	cpu := tables[0]
+	name, err := cpu.GetName()
+	assert.Nil(t, err)
+	assert.Equal(t, "cpu", name)
+
+	expected := []*gpb.ColumnSchema{
+		{ColumnName: "host", SemanticType: gpb.SemanticType_TAG, Datatype: gpb.ColumnDataType_STRING},
+		{ColumnName: "region", SemanticType: gpb.SemanticType_TAG, Datatype: gpb.ColumnDataType_STRING},
+		{ColumnName: "usage", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_FLOAT64},
+		{ColumnName: "cores", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_INT64},
+		{ColumnName: "running", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_BOOLEAN},
+		{ColumnName: "greptime_timestamp", SemanticType: gpb.SemanticType_TIMESTAMP, Datatype: gpb.ColumnDataType_TIMESTAMP_MILLISECOND},
+		{ColumnName: "load", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_UINT64},
+	}
+	assert.Equal(t, expected, cpu.GetColumnsSchema())
+
+	rows := cpu.GetRows().Rows
+	assert.Len(t, rows, 3)
+	assert.Equal(t, "us", rows[0].Values[1].GetStringValue())
+	assert.Equal(t, int64(4), rows[0].Values[3].GetI64Value())
+	assert.Equal(t, int64(1700000000000), rows[0].Values[5].GetTimestampMillisecondValue())
+	assert.Nil(t, rows[1].Values[1].GetValueData())
+	assert.Equal(t, 1.0, rows[1].Values[2].GetF64Value())
+	assert.Equal(t, uint64(2), rows[1].Values[6].GetU64Value())
+	assert.Equal(t, now.UnixMilli(), rows[2].Values[5].GetTimestampMillisecondValue())
+
+	weather := tables[1]
+	name, err = weather.GetName()
+	assert.Nil(t, err)
+	assert.Equal(t, "weather,daily", name)
+	values := weather.GetRows().Rows[0].Values
+	assert.Equal(t, "New York", values[0].GetStringValue())
+	assert.Equal(t, -15.0, values[1].GetF64Value())
+	assert.Equal(t, `say "hi", \o/`, values[2].GetStringValue())
+}
+
+func TestParsePrecision(t *testing.T) {
+	expected := map[types.TimestampPrecision]gpb.ColumnDataType{
+		types.SECOND:      gpb.ColumnDataType_TIMESTAMP_SECOND,
+		types.MILLISECOND: gpb.ColumnDataType_TIMESTAMP_MILLISECOND,
+		types.MICROSECOND: gpb.ColumnDataType_TIMESTAMP_MICROSECOND,
+		types.NANOSECOND:  gpb.ColumnDataType_TIMESTAMP_NANOSECOND,
+	}
+	for precision, datatype := range expected {
+		tables, err := NewParser().WithPrecision(precision).Parse([]byte("cpu usage=0.5 1700000000"))
+		assert.Nil(t, err)
+		columns := tables[0].GetColumnsSchema()
+		assert.Equal(t, datatype, columns[len(columns)-1].Datatype)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	data := `cpu,host=a usage=1 1
+cpu usage=1i 2
+cpu,host=b usage=2 3
+cpu,host usage=1 4
+cpu
+cpu usage="unterminated
+cpu usage=1 now
+cpu usage=NaN
+cpu,usage=a usage=1
+,host=a usage=1
+cpu greptime_timestamp=1
+`
+	tables, err := NewParser().Parse([]byte(data))
+	assert.Len(t, tables, 1)
+	assert.Equal(t, 2, tables[0].RowCount())
+
+	var lineErrors Errors
+	assert.True(t, errors.As(err, &lineErrors))
+	lines := make([]int, len(lineErrors))
+	for i, lineErr := range lineErrors {
+		lines[i] = lineErr.Line
+	}
+	assert.Equal(t, []int{2, 4, 5, 6, 7, 8, 9, 10, 11}, lines)
+	assert.ErrorContains(t, err, "line 2: ")
+}
+
+func TestParsePoint(t *testing.T) {
+	p, err := parsePoint(`m,t=1,t=2 a=1i,b="x y",a=2i  10`)
+	assert.Nil(t, err)
+	assert.Equal(t, "m", p.measurement)
+	assert.Equal(t, []tag{{key: "t", value: "2"}}, p.tags)
+	assert.Equal(t, []field{
+		{key: "a", value: int64(2), type_: types.INT64},
+		{key: "b", value: "x y", type_: types.STRING},
+	}, p.fields)
+	assert.Equal(t, int64(10), *p.timestamp)
+
+	p, err = parsePoint(`m f=F`)
+	assert.Nil(t, err)
+	assert.Nil(t, p.timestamp)
+	assert.Equal(t, false, p.fields[0].value)
+}
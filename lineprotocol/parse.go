@@ -0,0 +1,218 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lineprotocol
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+type tag struct {
+	key   string
+	value string
+}
+
+type field struct {
+	key   string
+	value any
+	type_ types.ColumnType
+}
+
+type point struct {
+	measurement string
+	tags        []tag
+	fields      []field
+	timestamp   *int64
+}
+
+// parsePoint parses the line. If a tag or field appears more than once, the last
+// value wins.
+func parsePoint(line string) (*point, error) {
+	p := &point{}
+
+	measurement, rest, sep := cut(line, ", ")
+	if measurement == "" {
+		return nil, errors.New("missing measurement")
+	}
+	p.measurement = unescape(measurement)
+
+	for sep == ',' {
+		var key, value string
+		key, rest, sep = cut(rest, "=, ")
+		if sep != '=' || key == "" {
+			return nil, fmt.Errorf("invalid tag %q", key)
+		}
+		value, rest, sep = cut(rest, ", ")
+		if value == "" {
+			return nil, fmt.Errorf("missing value of tag %q", unescape(key))
+		}
+		p.setTag(unescape(key), unescape(value))
+	}
+
+	rest = strings.TrimLeft(rest, " ")
+	if rest == "" {
+		return nil, errors.New("missing fields")
+	}
+	for {
+		var key string
+		key, rest, sep = cut(rest, "=, ")
+		if sep != '=' || key == "" {
+			return nil, fmt.Errorf("invalid field %q", key)
+		}
+		key = unescape(key)
+
+		var (
+			value any
+			type_ types.ColumnType
+			err   error
+		)
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, err = cutString(rest[1:])
+			type_ = types.STRING
+			sep = 0
+			if rest != "" {
+				sep, rest = rest[0], rest[1:]
+			}
+		} else {
+			var raw string
+			raw, rest, sep = cut(rest, ", ")
+			value, type_, err = parseFieldValue(raw)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of field %q: %w", key, err)
+		}
+		p.setField(field{key: key, value: value, type_: type_})
+
+		if sep != ',' {
+			break
+		}
+	}
+
+	if sep != 0 && sep != ' ' {
+		return nil, fmt.Errorf("unexpected %q after fields", sep)
+	}
+	if rest = strings.TrimSpace(rest); rest != "" {
+		ts, err := strconv.ParseInt(rest, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q", rest)
+		}
+		p.timestamp = &ts
+	}
+	return p, nil
+}
+
+func (p *point) setTag(key, value string) {
+	for i := range p.tags {
+		if p.tags[i].key == key {
+			p.tags[i].value = value
+			return
+		}
+	}
+	p.tags = append(p.tags, tag{key: key, value: value})
+}
+
+func (p *point) setField(f field) {
+	for i := range p.fields {
+		if p.fields[i].key == f.key {
+			p.fields[i] = f
+			return
+		}
+	}
+	p.fields = append(p.fields, f)
+}
+
+// cut slices s around the first unescaped byte of seps, and returns the byte found,
+// which is 0 if not found.
+func cut(s string, seps string) (before, after string, sep byte) {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case strings.IndexByte(seps, s[i]) >= 0:
+			return s[:i], s[i+1:], s[i]
+		}
+	}
+	return s, "", 0
+}
+
+// unescape removes the backslashes before the special characters.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte(`,= \`, s[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// cutString reads the string field value after the opening quote until the closing
+// quote, in which the quotes and backslashes are escaped.
+func cutString(s string) (value string, rest string, err error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) && (s[i+1] == '"' || s[i+1] == '\\') {
+				i++
+			}
+		case '"':
+			return b.String(), s[i+1:], nil
+		}
+		b.WriteByte(s[i])
+	}
+	return "", "", errors.New("unterminated string")
+}
+
+func parseFieldValue(raw string) (any, types.ColumnType, error) {
+	if raw == "" {
+		return nil, 0, errors.New("missing value")
+	}
+
+	switch raw {
+	case "t", "T", "true", "True", "TRUE":
+		return true, types.BOOLEAN, nil
+	case "f", "F", "false", "False", "FALSE":
+		return false, types.BOOLEAN, nil
+	}
+
+	switch raw[len(raw)-1] {
+	case 'i':
+		v, err := strconv.ParseInt(raw[:len(raw)-1], 10, 64)
+		return v, types.INT64, err
+	case 'u':
+		v, err := strconv.ParseUint(raw[:len(raw)-1], 10, 64)
+		return v, types.UINT64, err
+	}
+
+	v, err := strconv.ParseFloat(raw, 64)
+	if err == nil && (math.IsNaN(v) || math.IsInf(v, 0)) {
+		err = fmt.Errorf("%q is not supported", raw)
+	}
+	return v, types.FLOAT64, err
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package greptime
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/schema"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+// ObjectWriter writes the objects of T, whose schema is defined in the struct tags like
+// [Client.WriteObject]. T is a struct or a pointer of struct, and the table name can be
+// defined by the [schema.Tabler] interface.
+//
+//	writer, err := greptime.NewObjectWriter[Monitor](client)
+//
+//	resp, err := writer.Write(ctx, monitor1, monitor2)
+//	resp, err := writer.Delete(ctx, monitor1)
+//
+// If T implements [schema.Encoder], the rows are built by it without reflection.
+type ObjectWriter[T any] struct {
+	client  *Client
+	encoder bool
+}
+
+// NewObjectWriter validates the struct tags of T, which MUST define the columns and
+// exactly one timestamp column, so the invalid tags are found before writing.
+func NewObjectWriter[T any](client *Client) (*ObjectWriter[T], error) {
+	typ := reflect.TypeFor[T]()
+
+	tbl, err := schema.ParseSchema((*T)(nil))
+	if err != nil {
+		return nil, fmt.Errorf("invalid object type %s: %w", typ, err)
+	}
+	if tbl.IsColumnEmpty() {
+		return nil, fmt.Errorf("invalid object type %s: %w", typ, errs.ErrEmptyColumn)
+	}
+
+	timestamps := 0
+	for _, column := range tbl.GetColumnsSchema() {
+		if column.SemanticType == gpb.SemanticType_TIMESTAMP {
+			timestamps++
+		}
+	}
+	if timestamps == 0 {
+		return nil, fmt.Errorf("invalid object type %s: %w", typ, errs.ErrEmptyTimeIndex)
+	} else if timestamps > 1 {
+		return nil, fmt.Errorf("invalid object type %s: %w", typ, errs.ErrMultipleTimeIndex)
+	}
+
+	_, encoder := any(new(T)).(schema.Encoder)
+	if !encoder {
+		_, encoder = any(*new(T)).(schema.Encoder)
+	}
+	return &ObjectWriter[T]{client: client, encoder: encoder}, nil
+}
+
+// Write is like [Client.WriteObject], to write the objects into GreptimeDB.
+func (w *ObjectWriter[T]) Write(ctx context.Context, objs ...T) (*gpb.GreptimeResponse, error) {
+	return w.submit(ctx, types.INSERT, objs)
+}
+
+// Delete is like [Client.DeleteObject], to delete the objects from GreptimeDB.
+func (w *ObjectWriter[T]) Delete(ctx context.Context, objs ...T) (*gpb.GreptimeResponse, error) {
+	return w.submit(ctx, types.DELETE, objs)
+}
+
+// StreamWrite is like [Client.StreamWriteObject], to send the objects by the stream of
+// the client.
+func (w *ObjectWriter[T]) StreamWrite(ctx context.Context, objs ...T) error {
+	return w.streamSubmit(ctx, types.INSERT, objs)
+}
+
+// StreamDelete is like [Client.StreamDeleteObject], to delete the objects by the stream of
+// the client.
+func (w *ObjectWriter[T]) StreamDelete(ctx context.Context, objs ...T) error {
+	return w.streamSubmit(ctx, types.DELETE, objs)
+}
+
+func (w *ObjectWriter[T]) submit(ctx context.Context, operation types.Operation, objs []T) (*gpb.GreptimeResponse, error) {
+	if len(objs) == 0 {
+		return nil, errs.ErrEmptyTable
+	}
+
+	tbl, err := w.parse(objs)
+	if err != nil {
+		return nil, err
+	}
+	return w.client.submit(ctx, operation, tbl)
+}
+
+func (w *ObjectWriter[T]) streamSubmit(ctx context.Context, operation types.Operation, objs []T) error {
+	if len(objs) == 0 {
+		return errs.ErrEmptyTable
+	}
+
+	tbl, err := w.parse(objs)
+	if err != nil {
+		return err
+	}
+	return w.client.streamSubmit(ctx, operation, tbl)
+}
+
+func (w *ObjectWriter[T]) parse(objs []T) (*table.Table, error) {
+	if !w.encoder {
+		return schema.Parse(objs)
+	}
+
+	tbl, err := schema.ParseSchema((*T)(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*gpb.Row, len(objs))
+	for i := range objs {
+		encoder, ok := any(&objs[i]).(schema.Encoder)
+		if !ok { // T is a pointer
+			if reflect.ValueOf(objs[i]).IsNil() {
+				return nil, errs.ErrNilPointer
+			}
+			encoder = any(objs[i]).(schema.Encoder)
+		}
+		if rows[i], err = encoder.GreptimeRow(); err != nil {
+			return nil, err
+		}
+	}
+	return tbl.WithRows(&gpb.Rows{Rows: rows}), nil
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package greptime
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/schema"
+)
+
+func TestNewObjectWriterInvalid(t *testing.T) {
+	type noColumn struct {
+		Host string `greptime:"-"`
+	}
+	_, err := NewObjectWriter[noColumn](cli)
+	assert.ErrorIs(t, err, errs.ErrEmptyColumn)
+	assert.ErrorContains(t, err, "noColumn")
+
+	type noTimestamp struct {
+		Host string `greptime:"tag;column:host;type:string"`
+	}
+	_, err = NewObjectWriter[noTimestamp](cli)
+	assert.ErrorIs(t, err, errs.ErrEmptyTimeIndex)
+
+	type multipleTimestamps struct {
+		Ts1 time.Time `greptime:"timestamp;column:ts1;type:timestamp"`
+		Ts2 time.Time `greptime:"timestamp;column:ts2;type:timestamp"`
+	}
+	_, err = NewObjectWriter[*multipleTimestamps](cli)
+	assert.ErrorIs(t, err, errs.ErrMultipleTimeIndex)
+
+	type unknownType struct {
+		Host string    `greptime:"tag;column:host;type:unknown"`
+		Ts   time.Time `greptime:"timestamp;column:ts;type:timestamp"`
+	}
+	_, err = NewObjectWriter[unknownType](cli)
+	assert.ErrorContains(t, err, "unknownType")
+
+	_, err = NewObjectWriter[int](cli)
+	assert.NotNil(t, err)
+
+	writer, err := NewObjectWriter[monitor](cli)
+	assert.Nil(t, err)
+	_, err = writer.Write(context.Background())
+	assert.ErrorIs(t, err, errs.ErrEmptyTable)
+}
+
+func TestObjectWriter(t *testing.T) {
+	loc, err := time.LoadLocation(timezone)
+	assert.Nil(t, err)
+	ts1 := time.Now().Add(-1 * time.Minute).UnixMilli()
+	time1 := time.UnixMilli(ts1).In(loc)
+	ts2 := time.Now().Add(-2 * time.Minute).UnixMilli()
+	time2 := time.UnixMilli(ts2).In(loc)
+
+	monitors := []monitor{
+		{
+			ID:          randomId(),
+			Host:        "127.0.0.1",
+			Memory:      1,
+			Cpu:         1.0,
+			Temperature: -1,
+			Ts:          time1,
+			Running:     true,
+		},
+		{
+			ID:          randomId(),
+			Host:        "127.0.0.2",
+			Memory:      2,
+			Cpu:         2.0,
+			Temperature: -2,
+			Ts:          time2,
+			Running:     true,
+		},
+	}
+
+	writer, err := NewObjectWriter[monitor](cli)
+	assert.Nil(t, err)
+
+	resp, err := writer.Write(context.Background(), monitors...)
+	assert.Nil(t, err)
+	assert.Zero(t, resp.GetHeader().GetStatus().GetStatusCode())
+	assert.Equal(t, uint32(len(monitors)), resp.GetAffectedRows().GetValue())
+
+	resp, err = writer.Delete(context.Background(), monitors[0])
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), resp.GetAffectedRows().GetValue())
+
+	monitors_, err := db.Query(fmt.Sprintf("select * from %s where id in %s order by host asc", monitorTableName, getMonitorsIds(monitors)))
+	assert.Nil(t, err)
+	assert.Equal(t, monitors[1:], monitors_)
+}
+
+type encodedMonitor struct {
+	Host string
+	Ts   time.Time
+}
+
+func (encodedMonitor) GreptimeColumns() []*gpb.ColumnSchema {
+	return []*gpb.ColumnSchema{
+		{ColumnName: "host", Datatype: gpb.ColumnDataType_STRING, SemanticType: gpb.SemanticType_TAG},
+		{ColumnName: "ts", Datatype: gpb.ColumnDataType_TIMESTAMP_MILLISECOND, SemanticType: gpb.SemanticType_TIMESTAMP},
+	}
+}
+
+func (m encodedMonitor) GreptimeRow() (*gpb.Row, error) {
+	return &gpb.Row{Values: []*gpb.Value{
+		{ValueData: &gpb.Value_StringValue{StringValue: m.Host}},
+		{ValueData: &gpb.Value_TimestampMillisecondValue{TimestampMillisecondValue: m.Ts.UnixMilli()}},
+	}}, nil
+}
+
+func TestObjectWriterNilEncoder(t *testing.T) {
+	writer, err := NewObjectWriter[*encodedMonitor](cli)
+	assert.Nil(t, err)
+
+	tbl, err := writer.parse([]*encodedMonitor{{Host: "127.0.0.1", Ts: time.Now()}})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, tbl.RowCount())
+
+	_, err = writer.Write(context.Background(), &encodedMonitor{Host: "127.0.0.1", Ts: time.Now()}, nil)
+	assert.ErrorIs(t, err, errs.ErrNilPointer)
+
+	// the same error as the objects parsed by the struct tags
+	_, err = schema.Parse([]*monitor{nil})
+	assert.ErrorIs(t, err, errs.ErrNilPointer)
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package options
+
+import (
+	"math/rand"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	defaultRetryMaxAttempts = 3
+	defaultRetryBaseBackoff = time.Millisecond * 100
+	defaultRetryMaxBackoff  = time.Second * 5
+	defaultRetryJitter      = 0.2
+	defaultRetriableCodes   = []codes.Code{codes.Unavailable, codes.ResourceExhausted}
+)
+
+// RetryOption defines how the failed requests are retried.
+//
+//   - MaxAttempts is the max number of attempts, including the first one.
+//   - BaseBackoff is the backoff before the first retry, and it doubles for each retry.
+//   - MaxBackoff is the upper bound of the backoff.
+//   - Jitter is the fraction in [0, 1] of the backoff to be randomized.
+//   - AttemptTimeout is the timeout of each attempt. 0 means no timeout. The attempt
+//     which times out is retried as long as the context of the request is not done.
+//   - RetriableCodes are the gRPC codes to be retried.
+type RetryOption struct {
+	MaxAttempts    int
+	BaseBackoff    time.Duration
+	MaxBackoff     time.Duration
+	Jitter         float64
+	AttemptTimeout time.Duration
+	RetriableCodes []codes.Code
+}
+
+// NewRetryOption returns a RetryOption with default settings, which retries
+// Unavailable and ResourceExhausted errors at most 3 attempts.
+func NewRetryOption() RetryOption {
+	return RetryOption{
+		MaxAttempts:    defaultRetryMaxAttempts,
+		BaseBackoff:    defaultRetryBaseBackoff,
+		MaxBackoff:     defaultRetryMaxBackoff,
+		Jitter:         defaultRetryJitter,
+		RetriableCodes: defaultRetriableCodes,
+	}
+}
+
+// IsRetriable reports whether the gRPC code of err is one of RetriableCodes.
+func (opt RetryOption) IsRetriable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	s, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+
+	for _, code := range opt.RetriableCodes {
+		if s.Code() == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns how long to wait before the given retry, which starts from 1.
+func (opt RetryOption) Backoff(retry int) time.Duration {
+	if retry < 1 || opt.BaseBackoff <= 0 {
+		return 0
+	}
+
+	backoff := opt.BaseBackoff
+	for i := 1; i < retry; i++ {
+		backoff *= 2
+		if opt.MaxBackoff > 0 && backoff >= opt.MaxBackoff {
+			break
+		}
+	}
+	if opt.MaxBackoff > 0 && backoff > opt.MaxBackoff {
+		backoff = opt.MaxBackoff
+	}
+
+	if opt.Jitter > 0 {
+		jitter := opt.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		delta := float64(backoff) * jitter
+		backoff = time.Duration(float64(backoff) - delta*rand.Float64())
+	}
+	return backoff
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package options
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	opt := NewRetryOption()
+	opt.Jitter = 0
+
+	assert.Equal(t, time.Duration(0), opt.Backoff(0))
+	assert.Equal(t, 100*time.Millisecond, opt.Backoff(1))
+	assert.Equal(t, 200*time.Millisecond, opt.Backoff(2))
+	assert.Equal(t, 400*time.Millisecond, opt.Backoff(3))
+	assert.Equal(t, 5*time.Second, opt.Backoff(10))
+	assert.Equal(t, 5*time.Second, opt.Backoff(1000))
+
+	opt.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		backoff := opt.Backoff(2)
+		assert.LessOrEqual(t, backoff, 200*time.Millisecond)
+		assert.GreaterOrEqual(t, backoff, 100*time.Millisecond)
+	}
+}
+
+func TestRetryIsRetriable(t *testing.T) {
+	opt := NewRetryOption()
+
+	assert.False(t, opt.IsRetriable(nil))
+	assert.False(t, opt.IsRetriable(errors.New("not a grpc error")))
+	assert.False(t, opt.IsRetriable(status.Error(codes.InvalidArgument, "invalid")))
+	assert.True(t, opt.IsRetriable(status.Error(codes.Unavailable, "unavailable")))
+	assert.True(t, opt.IsRetriable(status.Error(codes.ResourceExhausted, "exhausted")))
+	assert.True(t, opt.IsRetriable(fmt.Errorf("wrapped: %w", status.Error(codes.Unavailable, "unavailable"))))
+}
@@ -18,6 +18,7 @@ package options
 
 import (
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/metric"
 	metricnoop "go.opentelemetry.io/otel/metric/noop"
 	"go.opentelemetry.io/otel/trace"
@@ -77,3 +78,15 @@ func (o *TelemetryOptions) Build() grpc.DialOption {
 		otelgrpc.WithTracerProvider(o.Traces.TracerProvider),
 	))
 }
+
+// Meter returns a Meter to record SDK's own metrics.
+// It is a noop Meter if metrics collection is not enabled.
+func (o *TelemetryOptions) Meter(name string) metric.Meter {
+	if !o.Metrics.Enabled {
+		return metricnoop.NewMeterProvider().Meter(name)
+	}
+	if o.Metrics.MeterProvider == nil {
+		return otel.GetMeterProvider().Meter(name)
+	}
+	return o.Metrics.MeterProvider.Meter(name)
+}
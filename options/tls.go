@@ -17,31 +17,260 @@
 package options
 
 import (
+	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+var defaultTlsMinVersion uint16 = tls.VersionTLS12
+
+// TlsOption defines how the client connects to GreptimeDB.
+//
+//   - InsecureSkipVerify is to connect without TLS at all.
+//   - CaFiles are the PEM files of the CAs to verify the server. They are appended
+//     to RootCAs. If both are empty, the system CAs are used.
+//   - CertFile and KeyFile are the PEM files of the client certificate for mTLS.
+//     Certificates is used if the files are not set.
+//   - ServerName overrides the name to verify the server certificate.
+//   - MinVersion is the minimum TLS version, TLS 1.2 by default.
+//   - ReloadInterval is how often the files are checked for changes. The files are
+//     reloaded on the next handshake after they are rotated. 0 disables reloading.
 type TlsOption struct {
 	InsecureSkipVerify bool
 
-	// TODO(yuanbohan): support cert path
-	// ServerCertPath                string //
-	// ClientKeyPath, ClientCertPath string // mTLS
+	CaFiles      []string
+	RootCAs      *x509.CertPool
+	CertFile     string
+	KeyFile      string
+	Certificates []tls.Certificate
+	ServerName   string
+	MinVersion   uint16
+
+	ReloadInterval time.Duration
 }
 
 func NewTlsOption(InsecureSkipVerify bool) TlsOption {
 	return TlsOption{InsecureSkipVerify: InsecureSkipVerify}
 }
 
+// WithCaFiles appends the PEM files of the CAs.
+func (opt TlsOption) WithCaFiles(files ...string) TlsOption {
+	opt.CaFiles = append(append([]string(nil), opt.CaFiles...), files...)
+	return opt
+}
+
+// WithRootCAs set the RootCAs field.
+func (opt TlsOption) WithRootCAs(pool *x509.CertPool) TlsOption {
+	opt.RootCAs = pool
+	return opt
+}
+
+// WithClientCertFiles set the CertFile and KeyFile fields.
+func (opt TlsOption) WithClientCertFiles(certFile, keyFile string) TlsOption {
+	opt.CertFile = certFile
+	opt.KeyFile = keyFile
+	return opt
+}
+
+// WithCertificates set the Certificates field.
+func (opt TlsOption) WithCertificates(certs ...tls.Certificate) TlsOption {
+	opt.Certificates = certs
+	return opt
+}
+
+// WithServerName set the ServerName field.
+func (opt TlsOption) WithServerName(name string) TlsOption {
+	opt.ServerName = name
+	return opt
+}
+
+// WithMinVersion set the MinVersion field, like tls.VersionTLS13.
+func (opt TlsOption) WithMinVersion(version uint16) TlsOption {
+	opt.MinVersion = version
+	return opt
+}
+
+// WithReloadInterval set the ReloadInterval field.
+func (opt TlsOption) WithReloadInterval(interval time.Duration) TlsOption {
+	opt.ReloadInterval = interval
+	return opt
+}
+
+// Build builds the dial option. If the files can not be loaded, every handshake
+// fails with the error, use BuildCredentials to get the error in advance.
 func (opt TlsOption) Build() grpc.DialOption {
+	creds, err := opt.BuildCredentials()
+	if err != nil {
+		creds = credentials.NewTLS(&tls.Config{
+			VerifyConnection: func(tls.ConnectionState) error { return err },
+		})
+	}
+	return grpc.WithTransportCredentials(creds)
+}
+
+// BuildCredentials loads the files and builds the transport credentials.
+func (opt TlsOption) BuildCredentials() (credentials.TransportCredentials, error) {
 	if opt.InsecureSkipVerify {
-		return grpc.WithTransportCredentials(insecure.NewCredentials())
-	} else {
-		// TODO(yuanbohan): setting for cert or key
-		tls := tls.Config{InsecureSkipVerify: opt.InsecureSkipVerify}
-		return grpc.WithTransportCredentials(credentials.NewTLS(&tls))
+		return insecure.NewCredentials(), nil
+	}
+
+	if (opt.CertFile == "") != (opt.KeyFile == "") {
+		return nil, errors.New("both of CertFile and KeyFile must be set for client certificate")
+	}
+
+	files := &tlsFiles{opt: opt}
+	if err := files.load(); err != nil {
+		return nil, err
+	}
+
+	creds := credentials.NewTLS(files.config())
+	if opt.ReloadInterval <= 0 || (len(opt.CaFiles) == 0 && opt.CertFile == "") {
+		return creds, nil
+	}
+	return &reloadableCredentials{TransportCredentials: creds, files: files}, nil
+}
+
+// reloadableCredentials builds the TLS config with the reloaded files for every handshake.
+type reloadableCredentials struct {
+	credentials.TransportCredentials
+	files *tlsFiles
+}
+
+func (c *reloadableCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return credentials.NewTLS(c.files.current()).ClientHandshake(ctx, authority, conn)
+}
+
+func (c *reloadableCredentials) Clone() credentials.TransportCredentials {
+	return &reloadableCredentials{TransportCredentials: c.TransportCredentials.Clone(), files: c.files}
+}
+
+// tlsFiles holds the CAs and the client certificate loaded from the files,
+// and reloads them when the files are modified.
+type tlsFiles struct {
+	opt TlsOption
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	modTimes  map[string]time.Time
+	roots     *x509.CertPool
+	certs     []tls.Certificate
+}
+
+// current returns the TLS config, the files are reloaded first if
+// ReloadInterval has passed since the last check and they are modified.
+func (f *tlsFiles) current() *tls.Config {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if time.Since(f.checkedAt) >= f.opt.ReloadInterval && f.modified() {
+		// keep using the loaded ones if the files are being rotated
+		_ = f.loadLocked()
+	}
+	return f.configLocked()
+}
+
+func (f *tlsFiles) config() *tls.Config {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.configLocked()
+}
+
+func (f *tlsFiles) configLocked() *tls.Config {
+	config := &tls.Config{
+		ServerName:   f.opt.ServerName,
+		MinVersion:   f.opt.MinVersion,
+		RootCAs:      f.roots,
+		Certificates: f.certs,
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = defaultTlsMinVersion
+	}
+	return config
+}
+
+func (f *tlsFiles) load() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.loadLocked()
+}
+
+func (f *tlsFiles) loadLocked() error {
+	modTimes := make(map[string]time.Time)
+
+	roots := f.opt.RootCAs
+	if len(f.opt.CaFiles) > 0 {
+		if roots == nil {
+			roots = x509.NewCertPool()
+		} else {
+			roots = roots.Clone()
+		}
+
+		for _, file := range f.opt.CaFiles {
+			pem, modTime, err := readFile(file)
+			if err != nil {
+				return err
+			}
+			if !roots.AppendCertsFromPEM(pem) {
+				return fmt.Errorf("no certificate found in CA file %q", file)
+			}
+			modTimes[file] = modTime
+		}
+	}
+
+	certs := f.opt.Certificates
+	if f.opt.CertFile != "" {
+		certPEM, certModTime, err := readFile(f.opt.CertFile)
+		if err != nil {
+			return err
+		}
+		keyPEM, keyModTime, err := readFile(f.opt.KeyFile)
+		if err != nil {
+			return err
+		}
+
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		certs = []tls.Certificate{cert}
+		modTimes[f.opt.CertFile] = certModTime
+		modTimes[f.opt.KeyFile] = keyModTime
+	}
+
+	f.roots, f.certs, f.modTimes = roots, certs, modTimes
+	f.checkedAt = time.Now()
+	return nil
+}
+
+func (f *tlsFiles) modified() bool {
+	f.checkedAt = time.Now()
+	for file, modTime := range f.modTimes {
+		info, err := os.Stat(file)
+		if err == nil && !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+func readFile(file string) ([]byte, time.Time, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, time.Time{}, err
 	}
+	return data, info.ModTime(), nil
 }
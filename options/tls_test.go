@@ -0,0 +1,206 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package options
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	assert.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func writeFile(t *testing.T, file string, data []byte, modTime time.Time) {
+	assert.Nil(t, os.WriteFile(file, data, 0o600))
+	assert.Nil(t, os.Chtimes(file, modTime, modTime))
+}
+
+func TestTlsOptionInvalid(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := NewTlsOption(false).WithCaFiles(filepath.Join(dir, "missing.pem")).BuildCredentials()
+	assert.NotNil(t, err)
+
+	_, err = NewTlsOption(false).WithClientCertFiles(filepath.Join(dir, "client.pem"), "").BuildCredentials()
+	assert.NotNil(t, err)
+
+	invalid := filepath.Join(dir, "invalid.pem")
+	writeFile(t, invalid, []byte("invalid"), time.Now())
+	_, err = NewTlsOption(false).WithCaFiles(invalid).BuildCredentials()
+	assert.NotNil(t, err)
+
+	creds, err := NewTlsOption(true).WithCaFiles(invalid).BuildCredentials()
+	assert.Nil(t, err)
+	assert.Equal(t, "insecure", creds.Info().SecurityProtocol)
+}
+
+func TestTlsOptionMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil)
+	server := newTestCert(t, "greptimedb.local", ca)
+	client := newTestCert(t, "client", ca)
+
+	caFile, certFile, keyFile := filepath.Join(dir, "ca.pem"), filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	writeFile(t, caFile, ca.certPEM, time.Now())
+	writeFile(t, certFile, client.certPEM, time.Now())
+	writeFile(t, keyFile, client.keyPEM, time.Now())
+
+	serverCert, err := tls.X509KeyPair(server.certPEM, server.keyPEM)
+	assert.Nil(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		NextProtos:   []string{"h2"},
+	})
+	assert.Nil(t, err)
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	dial := func(opt TlsOption) error {
+		creds, err := opt.BuildCredentials()
+		if err != nil {
+			return err
+		}
+
+		rawConn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			return err
+		}
+		conn, _, err := creds.ClientHandshake(context.Background(), listener.Addr().String(), rawConn)
+		if err != nil {
+			rawConn.Close()
+			return err
+		}
+		return conn.Close()
+	}
+
+	opt := NewTlsOption(false).
+		WithCaFiles(caFile).
+		WithClientCertFiles(certFile, keyFile).
+		WithServerName("greptimedb.local").
+		WithMinVersion(tls.VersionTLS13)
+	assert.Nil(t, dial(opt))
+	assert.Nil(t, dial(opt.WithReloadInterval(time.Minute)))
+
+	// the server name does not match the certificate
+	assert.NotNil(t, dial(opt.WithServerName("127.0.0.1")))
+	assert.NotNil(t, dial(opt.WithServerName("127.0.0.1").WithReloadInterval(time.Minute)))
+
+	// the CA is not trusted
+	assert.NotNil(t, dial(NewTlsOption(false).WithServerName("greptimedb.local")))
+}
+
+func TestTlsOptionReload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil)
+	client1 := newTestCert(t, "client1", ca)
+	client2 := newTestCert(t, "client2", ca)
+
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	modTime := time.Now().Add(-time.Minute)
+	writeFile(t, certFile, client1.certPEM, modTime)
+	writeFile(t, keyFile, client1.keyPEM, modTime)
+
+	creds, err := NewTlsOption(false).
+		WithClientCertFiles(certFile, keyFile).
+		WithReloadInterval(time.Nanosecond).
+		BuildCredentials()
+	assert.Nil(t, err)
+	files := creds.(*reloadableCredentials).files
+
+	assert.Equal(t, client1.cert.Raw, files.current().Certificates[0].Certificate[0])
+
+	// the half rotated files are ignored
+	writeFile(t, certFile, client2.certPEM, time.Now())
+	assert.Equal(t, client1.cert.Raw, files.current().Certificates[0].Certificate[0])
+
+	writeFile(t, keyFile, client2.keyPEM, time.Now())
+	assert.Equal(t, client2.cert.Raw, files.current().Certificates[0].Certificate[0])
+}
@@ -0,0 +1,274 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package otelmetric
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+const (
+	ValueColumn     = "greptime_value"
+	TimestampColumn = "greptime_timestamp"
+
+	ScopeNameTag    = "otel_scope_name"
+	ScopeVersionTag = "otel_scope_version"
+	BucketTag       = "le"
+	QuantileTag     = "quantile"
+)
+
+type tag struct {
+	key   string
+	value string
+}
+
+type point struct {
+	tags  []tag
+	time  time.Time
+	value float64
+}
+
+// series is the points of a table, and the tag keys are in the order they first appear.
+type series struct {
+	name    string
+	keys    []string
+	indexes map[string]int
+	points  []point
+}
+
+// converter groups the points of the metrics by the table names.
+type converter struct {
+	names  []string
+	series map[string]*series
+}
+
+func toTables(rm *metricdata.ResourceMetrics) ([]*table.Table, error) {
+	c := &converter{series: make(map[string]*series)}
+
+	resourceTags := appendAttributes(nil, rm.Resource.Iter())
+	for _, sm := range rm.ScopeMetrics {
+		tags := resourceTags[:len(resourceTags):len(resourceTags)]
+		if sm.Scope.Name != "" {
+			tags = append(tags, tag{ScopeNameTag, sm.Scope.Name})
+		}
+		if sm.Scope.Version != "" {
+			tags = append(tags, tag{ScopeVersionTag, sm.Scope.Version})
+		}
+		tags = appendAttributes(tags, sm.Scope.Attributes.Iter())
+
+		for _, m := range sm.Metrics {
+			name := normalizeName(m.Name)
+			switch data := m.Data.(type) {
+			case metricdata.Gauge[int64]:
+				addDataPoints(c, name, tags, data.DataPoints)
+			case metricdata.Gauge[float64]:
+				addDataPoints(c, name, tags, data.DataPoints)
+			case metricdata.Sum[int64]:
+				addDataPoints(c, name, tags, data.DataPoints)
+			case metricdata.Sum[float64]:
+				addDataPoints(c, name, tags, data.DataPoints)
+			case metricdata.Histogram[int64]:
+				addHistogram(c, name, tags, data.DataPoints)
+			case metricdata.Histogram[float64]:
+				addHistogram(c, name, tags, data.DataPoints)
+			case metricdata.ExponentialHistogram[int64]:
+				addExponentialHistogram(c, name, tags, data.DataPoints)
+			case metricdata.ExponentialHistogram[float64]:
+				addExponentialHistogram(c, name, tags, data.DataPoints)
+			case metricdata.Summary:
+				addSummary(c, name, tags, data.DataPoints)
+			}
+		}
+	}
+
+	tables := make([]*table.Table, 0, len(c.names))
+	for _, name := range c.names {
+		tbl, err := c.series[name].toTable()
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, tbl)
+	}
+	return tables, nil
+}
+
+func addDataPoints[N int64 | float64](c *converter, name string, tags []tag, points []metricdata.DataPoint[N]) {
+	for _, dp := range points {
+		c.add(name, appendAttributes(tags, dp.Attributes.Iter()), dp.Time, float64(dp.Value))
+	}
+}
+
+func addHistogram[N int64 | float64](c *converter, name string, tags []tag, points []metricdata.HistogramDataPoint[N]) {
+	for _, dp := range points {
+		tags := appendAttributes(tags, dp.Attributes.Iter())
+
+		var count uint64
+		for i, bound := range dp.Bounds {
+			if i < len(dp.BucketCounts) {
+				count += dp.BucketCounts[i]
+			}
+			c.add(name+"_bucket", withTag(tags, BucketTag, formatFloat(bound)), dp.Time, float64(count))
+		}
+		c.add(name+"_bucket", withTag(tags, BucketTag, "+Inf"), dp.Time, float64(dp.Count))
+		c.add(name+"_sum", tags, dp.Time, float64(dp.Sum))
+		c.add(name+"_count", tags, dp.Time, float64(dp.Count))
+	}
+}
+
+// addExponentialHistogram converts the exponential buckets into the cumulative buckets,
+// the bucket of index i has the upper bound base^(i+1), where base is 2^(2^-scale).
+func addExponentialHistogram[N int64 | float64](c *converter, name string, tags []tag, points []metricdata.ExponentialHistogramDataPoint[N]) {
+	for _, dp := range points {
+		tags := appendAttributes(tags, dp.Attributes.Iter())
+		factor := math.Exp2(-float64(dp.Scale))
+
+		var count uint64
+		negative := dp.NegativeBucket
+		for i := len(negative.Counts) - 1; i >= 0; i-- {
+			count += negative.Counts[i]
+			bound := -math.Exp2(float64(int(negative.Offset)+i) * factor)
+			c.add(name+"_bucket", withTag(tags, BucketTag, formatFloat(bound)), dp.Time, float64(count))
+		}
+
+		count += dp.ZeroCount
+		c.add(name+"_bucket", withTag(tags, BucketTag, formatFloat(dp.ZeroThreshold)), dp.Time, float64(count))
+
+		positive := dp.PositiveBucket
+		for i, n := range positive.Counts {
+			count += n
+			bound := math.Exp2(float64(int(positive.Offset)+i+1) * factor)
+			c.add(name+"_bucket", withTag(tags, BucketTag, formatFloat(bound)), dp.Time, float64(count))
+		}
+
+		c.add(name+"_bucket", withTag(tags, BucketTag, "+Inf"), dp.Time, float64(dp.Count))
+		c.add(name+"_sum", tags, dp.Time, float64(dp.Sum))
+		c.add(name+"_count", tags, dp.Time, float64(dp.Count))
+	}
+}
+
+func addSummary(c *converter, name string, tags []tag, points []metricdata.SummaryDataPoint) {
+	for _, dp := range points {
+		tags := appendAttributes(tags, dp.Attributes.Iter())
+		for _, q := range dp.QuantileValues {
+			c.add(name, withTag(tags, QuantileTag, formatFloat(q.Quantile)), dp.Time, q.Value)
+		}
+		c.add(name+"_sum", tags, dp.Time, dp.Sum)
+		c.add(name+"_count", tags, dp.Time, float64(dp.Count))
+	}
+}
+
+func (c *converter) add(name string, tags []tag, t time.Time, value float64) {
+	s, ok := c.series[name]
+	if !ok {
+		s = &series{name: name, indexes: make(map[string]int)}
+		c.series[name] = s
+		c.names = append(c.names, name)
+	}
+
+	for _, tag := range tags {
+		if _, ok := s.indexes[tag.key]; !ok {
+			s.indexes[tag.key] = len(s.keys) + 2
+			s.keys = append(s.keys, tag.key)
+		}
+	}
+	s.points = append(s.points, point{tags: tags, time: t, value: value})
+}
+
+func (s *series) toTable() (*table.Table, error) {
+	// the names are normalized already
+	tbl, err := table.New(s.name)
+	if err != nil {
+		return nil, err
+	}
+	tbl.WithSanitate(false)
+
+	if err := tbl.AddTimestampColumn(TimestampColumn, types.TIMESTAMP_NANOSECOND); err != nil {
+		return nil, err
+	}
+	if err := tbl.AddFieldColumn(ValueColumn, types.FLOAT64); err != nil {
+		return nil, err
+	}
+	for _, key := range s.keys {
+		if err := tbl.AddTagColumn(key, types.STRING); err != nil {
+			return nil, err
+		}
+	}
+
+	inputs := make([]any, len(s.keys)+2)
+	for _, p := range s.points {
+		clear(inputs)
+		inputs[0], inputs[1] = p.time, p.value
+		for _, tag := range p.tags {
+			inputs[s.indexes[tag.key]] = tag.value
+		}
+		if err := tbl.AddRow(inputs...); err != nil {
+			return nil, err
+		}
+	}
+	return tbl, nil
+}
+
+// appendAttributes appends the attributes as tags, and the later tags of the same key
+// override the former ones. The attributes of the same names as the value and timestamp
+// columns are dropped.
+func appendAttributes(tags []tag, iter attribute.Iterator) []tag {
+	tags = tags[:len(tags):len(tags)]
+	for iter.Next() {
+		kv := iter.Attribute()
+		key := normalizeName(string(kv.Key))
+		if key == ValueColumn || key == TimestampColumn {
+			continue
+		}
+		tags = withTag(tags, key, kv.Value.Emit())
+	}
+	return tags
+}
+
+// withTag returns the tags with the tag set, and tags is not modified.
+func withTag(tags []tag, key, value string) []tag {
+	for i := range tags {
+		if tags[i].key == key {
+			overridden := append([]tag(nil), tags...)
+			overridden[i].value = value
+			return overridden
+		}
+	}
+	return append(tags[:len(tags):len(tags)], tag{key, value})
+}
+
+// normalizeName replaces the characters not allowed in Prometheus metric names, like
+// dots, with underscores.
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == ':' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
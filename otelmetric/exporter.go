@@ -0,0 +1,144 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package otelmetric provides the OpenTelemetry metric exporter writing the metrics
+// into GreptimeDB by the client.
+//
+// Every metric is a table of the greptime_timestamp and greptime_value columns, and the
+// attributes of the resource, scope and data points are tag columns. Histograms and
+// summaries are written as the Prometheus style tables of <name>_bucket with the le tag
+// (or <name> with the quantile tag for summaries), <name>_sum and <name>_count.
+package otelmetric
+
+import (
+	"context"
+	"sync/atomic"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+)
+
+// DefaultMaxRowsPerWrite is the default of how many rows are sent by a Write at most.
+const DefaultMaxRowsPerWrite = 5000
+
+// Writer writes the tables, like greptime.Client.
+type Writer interface {
+	Write(ctx context.Context, tables ...*table.Table) (*gpb.GreptimeResponse, error)
+}
+
+// Exporter is the sdkmetric.Exporter writing the metrics by the Writer. Call NewExporter()
+// to create it.
+//
+//	exporter := otelmetric.NewExporter(client)
+//	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)))
+type Exporter struct {
+	writer              Writer
+	temporalitySelector sdkmetric.TemporalitySelector
+	aggregationSelector sdkmetric.AggregationSelector
+	maxRowsPerWrite     int
+
+	shutdown atomic.Bool
+}
+
+var _ sdkmetric.Exporter = (*Exporter)(nil)
+
+// NewExporter creates the exporter of the default temporality and aggregation of the SDK.
+func NewExporter(writer Writer) *Exporter {
+	return &Exporter{
+		writer:              writer,
+		temporalitySelector: sdkmetric.DefaultTemporalitySelector,
+		aggregationSelector: sdkmetric.DefaultAggregationSelector,
+		maxRowsPerWrite:     DefaultMaxRowsPerWrite,
+	}
+}
+
+// WithTemporalitySelector sets the temporality of the instruments, like the delta
+// temporality. The values are written as they are aggregated.
+func (e *Exporter) WithTemporalitySelector(selector sdkmetric.TemporalitySelector) *Exporter {
+	e.temporalitySelector = selector
+	return e
+}
+
+// WithAggregationSelector sets the aggregation of the instruments.
+func (e *Exporter) WithAggregationSelector(selector sdkmetric.AggregationSelector) *Exporter {
+	e.aggregationSelector = selector
+	return e
+}
+
+// WithMaxRowsPerWrite sets how many rows are sent by a Write at most. The tables of an
+// export are sent by as few Writes as possible, and a table is never split.
+func (e *Exporter) WithMaxRowsPerWrite(rows int) *Exporter {
+	e.maxRowsPerWrite = rows
+	return e
+}
+
+func (e *Exporter) Temporality(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+	return e.temporalitySelector(kind)
+}
+
+func (e *Exporter) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return e.aggregationSelector(kind)
+}
+
+// Export converts the metrics into tables and writes them. The metrics of the same name
+// in different scopes are merged into the same table.
+func (e *Exporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	if e.shutdown.Load() {
+		return errs.ErrExporterShutdown
+	}
+
+	tables, err := toTables(rm)
+	if err != nil {
+		return err
+	}
+
+	var (
+		batch []*table.Table
+		rows  int
+	)
+	for _, tbl := range tables {
+		if len(batch) > 0 && e.maxRowsPerWrite > 0 && rows+tbl.RowCount() > e.maxRowsPerWrite {
+			if _, err := e.writer.Write(ctx, batch...); err != nil {
+				return err
+			}
+			batch, rows = nil, 0
+		}
+		batch = append(batch, tbl)
+		rows += tbl.RowCount()
+	}
+
+	if len(batch) > 0 {
+		if _, err := e.writer.Write(ctx, batch...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ForceFlush does nothing, since the metrics are written by Export directly.
+func (e *Exporter) ForceFlush(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// Shutdown stops the exporter, and the later Export fails.
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	e.shutdown.Store(true)
+	return ctx.Err()
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package otelmetric
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+)
+
+type fakeWriter struct {
+	writes [][]*table.Table
+	err    error
+}
+
+func (w *fakeWriter) Write(_ context.Context, tables ...*table.Table) (*gpb.GreptimeResponse, error) {
+	w.writes = append(w.writes, tables)
+	return &gpb.GreptimeResponse{}, w.err
+}
+
+func tableByName(t *testing.T, tables []*table.Table, name string) *table.Table {
+	for _, tbl := range tables {
+		if n, _ := tbl.GetName(); n == name {
+			return tbl
+		}
+	}
+	t.Fatalf("table %s not found", name)
+	return nil
+}
+
+// columnValues returns the string values of the column of every row.
+func columnValues(tbl *table.Table, column string) []string {
+	idx := -1
+	for i, c := range tbl.GetColumnsSchema() {
+		if c.ColumnName == column {
+			idx = i
+		}
+	}
+	values := make([]string, 0, tbl.RowCount())
+	for _, row := range tbl.GetRows().Rows {
+		values = append(values, row.Values[idx].GetStringValue())
+	}
+	return values
+}
+
+func TestExport(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	attrs := attribute.NewSet(attribute.String("http.method", "GET"), attribute.String("greptime_value", "dropped"))
+	rm := &metricdata.ResourceMetrics{
+		Resource: resource.NewSchemaless(attribute.String("service.name", "api")),
+		ScopeMetrics: []metricdata.ScopeMetrics{{
+			Scope: instrumentation.Scope{Name: "server", Version: "v1"},
+			Metrics: []metricdata.Metrics{
+				{Name: "http.requests", Data: metricdata.Sum[int64]{
+					DataPoints:  []metricdata.DataPoint[int64]{{Attributes: attrs, Time: now, Value: 3}},
+					Temporality: metricdata.CumulativeTemporality,
+				}},
+				{Name: "http.duration", Data: metricdata.Histogram[float64]{
+					DataPoints: []metricdata.HistogramDataPoint[float64]{{
+						Attributes: attrs, Time: now, Count: 3, Bounds: []float64{0.1, 1}, BucketCounts: []uint64{1, 1, 1}, Sum: 2.5,
+					}},
+				}},
+				{Name: "size", Data: metricdata.ExponentialHistogram[int64]{
+					DataPoints: []metricdata.ExponentialHistogramDataPoint[int64]{{
+						Time: now, Count: 4, Scale: 0, ZeroCount: 1, Sum: 5,
+						PositiveBucket: metricdata.ExponentialBucket{Offset: 0, Counts: []uint64{1, 1}},
+						NegativeBucket: metricdata.ExponentialBucket{Offset: 0, Counts: []uint64{1}},
+					}},
+				}},
+			},
+		}, {
+			Scope: instrumentation.Scope{Name: "client"},
+			Metrics: []metricdata.Metrics{
+				{Name: "http.requests", Data: metricdata.Gauge[float64]{
+					DataPoints: []metricdata.DataPoint[float64]{{Attributes: attribute.NewSet(attribute.Int("code", 200)), Time: now, Value: 1.5}},
+				}},
+			},
+		}},
+	}
+
+	writer := &fakeWriter{}
+	assert.Nil(t, NewExporter(writer).Export(context.Background(), rm))
+	assert.Len(t, writer.writes, 1)
+	tables := writer.writes[0]
+	assert.Len(t, tables, 7)
+
+	requests := tableByName(t, tables, "http_requests")
+	columns := make([]string, 0)
+	for _, c := range requests.GetColumnsSchema() {
+		columns = append(columns, c.ColumnName)
+	}
+	assert.Equal(t, []string{TimestampColumn, ValueColumn, "service_name", ScopeNameTag, ScopeVersionTag, "http_method", "code"}, columns)
+	rows := requests.GetRows().Rows
+	assert.Len(t, rows, 2)
+	assert.Equal(t, now.UnixNano(), rows[0].Values[0].GetTimestampNanosecondValue())
+	assert.Equal(t, 3.0, rows[0].Values[1].GetF64Value())
+	assert.Equal(t, "GET", rows[0].Values[5].GetStringValue())
+	assert.Equal(t, 1.5, rows[1].Values[1].GetF64Value())
+	assert.Equal(t, "client", rows[1].Values[3].GetStringValue())
+	assert.Nil(t, rows[1].Values[4].GetValueData())
+	assert.Equal(t, "200", rows[1].Values[6].GetStringValue())
+
+	buckets := tableByName(t, tables, "http_duration_bucket")
+	assert.Equal(t, []string{"0.1", "1", "+Inf"}, columnValues(buckets, BucketTag))
+	for i, expected := range []float64{1, 2, 3} {
+		assert.Equal(t, expected, buckets.GetRows().Rows[i].Values[1].GetF64Value())
+	}
+	assert.Equal(t, 2.5, tableByName(t, tables, "http_duration_sum").GetRows().Rows[0].Values[1].GetF64Value())
+	assert.Equal(t, 3.0, tableByName(t, tables, "http_duration_count").GetRows().Rows[0].Values[1].GetF64Value())
+
+	// base is 2 at scale 0, the negative bucket is (-2, -1], and the positive ones are (1, 2] and (2, 4]
+	sizeBuckets := tableByName(t, tables, "size_bucket")
+	assert.Equal(t, []string{"-1", "0", "2", "4", "+Inf"}, columnValues(sizeBuckets, BucketTag))
+	for i, expected := range []float64{1, 2, 3, 4, 4} {
+		assert.Equal(t, expected, sizeBuckets.GetRows().Rows[i].Values[1].GetF64Value())
+	}
+}
+
+func TestExportBatches(t *testing.T) {
+	now := time.Now()
+	metrics := make([]metricdata.Metrics, 0)
+	for _, name := range []string{"a", "b", "c"} {
+		metrics = append(metrics, metricdata.Metrics{Name: name, Data: metricdata.Gauge[int64]{
+			DataPoints: []metricdata.DataPoint[int64]{{Time: now, Value: 1}, {Time: now, Value: 2}},
+		}})
+	}
+	rm := &metricdata.ResourceMetrics{ScopeMetrics: []metricdata.ScopeMetrics{{Metrics: metrics}}}
+
+	writer := &fakeWriter{}
+	exporter := NewExporter(writer).WithMaxRowsPerWrite(4)
+	assert.Nil(t, exporter.Export(context.Background(), rm))
+	assert.Len(t, writer.writes, 2)
+	assert.Len(t, writer.writes[0], 2)
+	assert.Len(t, writer.writes[1], 1)
+
+	writer.err = errors.New("unavailable")
+	assert.NotNil(t, exporter.Export(context.Background(), rm))
+
+	assert.Nil(t, exporter.Shutdown(context.Background()))
+	assert.ErrorIs(t, exporter.Export(context.Background(), rm), errs.ErrExporterShutdown)
+}
+
+func TestExporterWithMeterProvider(t *testing.T) {
+	writer := &fakeWriter{}
+	exporter := NewExporter(writer).WithTemporalitySelector(func(sdkmetric.InstrumentKind) metricdata.Temporality {
+		return metricdata.DeltaTemporality
+	})
+	assert.Equal(t, metricdata.DeltaTemporality, exporter.Temporality(sdkmetric.InstrumentKindCounter))
+
+	reader := sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(time.Hour))
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	counter, err := provider.Meter("test").Int64Counter("jobs")
+	assert.Nil(t, err)
+	counter.Add(context.Background(), 2)
+
+	assert.Nil(t, provider.ForceFlush(context.Background()))
+	counter.Add(context.Background(), 3)
+	assert.Nil(t, provider.Shutdown(context.Background()))
+
+	assert.Len(t, writer.writes, 2)
+	second := tableByName(t, writer.writes[1], "jobs")
+	assert.Equal(t, 3.0, second.GetRows().Rows[0].Values[1].GetF64Value())
+}
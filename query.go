@@ -0,0 +1,101 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package greptime
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/query"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/request"
+)
+
+// Query executes the sql in the default database and returns the decoded rows.
+// The result is read via the Arrow Flight service of GreptimeDB.
+//
+//	result, err := client.Query(context.Background(), "SELECT * FROM monitors WHERE host = '127.0.0.1'")
+//
+//	// scan the rows into tagged structs
+//	var monitors []Monitor
+//	err = result.Scan(&monitors)
+func (c *Client) Query(ctx context.Context, sql string) (*query.Result, error) {
+	request_, err := request.BuildSqlQuery(c.newHeader(), sql)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := proto.Marshal(request_)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := c.flightClient.DoGet(ctx, &flight.Ticket{Ticket: ticket})
+	if err != nil {
+		return nil, err
+	}
+	return query.FromFlightStream(stream)
+}
+
+// PromRangeQuery executes the PromQL range query in the default database.
+// lookback is the lookback delta, 0 means the default of GreptimeDB.
+//
+//	end := time.Now()
+//	result, err := client.PromRangeQuery(context.Background(), "rate(http_requests_total[5m])",
+//		end.Add(-time.Hour), end, time.Minute, 0)
+func (c *Client) PromRangeQuery(ctx context.Context, promql string, start, end time.Time,
+	step, lookback time.Duration) (*query.PromResult, error) {
+	rangeQuery := &gpb.PromRangeQuery{
+		Query: promql,
+		Start: formatPromTime(start),
+		End:   formatPromTime(end),
+		Step:  formatPromDuration(step),
+	}
+	if lookback > 0 {
+		rangeQuery.Lookback = formatPromDuration(lookback)
+	}
+
+	request_, err := request.BuildPromRangeQuery(c.newHeader(), rangeQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.promClient.Handle(ctx, request_)
+	if err != nil {
+		return nil, err
+	}
+
+	if status := resp.GetHeader().GetStatus(); status != nil && status.GetStatusCode() != 0 {
+		return nil, fmt.Errorf("promql query failed with code %d: %s", status.GetStatusCode(), status.GetErrMsg())
+	}
+	return query.ParsePromResponse(resp.GetBody())
+}
+
+// formatPromTime formats t as unix seconds, which is accepted by Prometheus API.
+func formatPromTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixMilli())/1e3, 'f', -1, 64)
+}
+
+// formatPromDuration formats d as seconds, which is accepted by Prometheus API.
+func formatPromDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
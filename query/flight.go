@@ -0,0 +1,168 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"google.golang.org/protobuf/proto"
+)
+
+// FromFlightStream reads the result of a query from the Arrow Flight DoGet stream.
+//
+// GreptimeDB sends either the affected rows as the app metadata of the first message,
+// or the Arrow schema and record batches.
+func FromFlightStream(stream flight.DataStreamReader) (*Result, error) {
+	first, err := stream.Recv()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return &Result{}, nil
+		}
+		return nil, err
+	}
+
+	if len(first.DataHeader) == 0 {
+		var metadata gpb.FlightMetadata
+		if err := proto.Unmarshal(first.AppMetadata, &metadata); err != nil {
+			return nil, err
+		}
+		return &Result{AffectedRows: metadata.GetAffectedRows().GetValue()}, nil
+	}
+
+	reader, err := flight.NewRecordReader(&dataStream{first: first, stream: stream})
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Release()
+
+	result := &Result{Columns: toColumns(reader.Schema())}
+	for reader.Next() {
+		record := reader.Record()
+		for i := 0; i < int(record.NumRows()); i++ {
+			row := make([]any, record.NumCols())
+			for j, arr := range record.Columns() {
+				row[j] = valueAt(arr, i)
+			}
+			result.Rows = append(result.Rows, row)
+		}
+	}
+
+	if err := reader.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return result, nil
+}
+
+// dataStream puts the peeked first message back, and skips the messages
+// only carrying the app metadata, like the metrics of the query.
+type dataStream struct {
+	first  *flight.FlightData
+	stream flight.DataStreamReader
+}
+
+func (s *dataStream) Recv() (*flight.FlightData, error) {
+	if s.first != nil {
+		data := s.first
+		s.first = nil
+		return data, nil
+	}
+
+	for {
+		data, err := s.stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if len(data.DataHeader) > 0 {
+			return data, nil
+		}
+	}
+}
+
+func toColumns(schema *arrow.Schema) []Column {
+	columns := make([]Column, 0, schema.NumFields())
+	for _, field := range schema.Fields() {
+		columns = append(columns, Column{
+			Name:     field.Name,
+			DataType: field.Type.String(),
+			Nullable: field.Nullable,
+		})
+	}
+	return columns
+}
+
+func valueAt(arr arrow.Array, i int) any {
+	if arr.IsNull(i) {
+		return nil
+	}
+
+	switch a := arr.(type) {
+	case *array.Boolean:
+		return a.Value(i)
+	case *array.Int8:
+		return a.Value(i)
+	case *array.Int16:
+		return a.Value(i)
+	case *array.Int32:
+		return a.Value(i)
+	case *array.Int64:
+		return a.Value(i)
+	case *array.Uint8:
+		return a.Value(i)
+	case *array.Uint16:
+		return a.Value(i)
+	case *array.Uint32:
+		return a.Value(i)
+	case *array.Uint64:
+		return a.Value(i)
+	case *array.Float32:
+		return a.Value(i)
+	case *array.Float64:
+		return a.Value(i)
+	case *array.String:
+		return a.Value(i)
+	case *array.LargeString:
+		return a.Value(i)
+	case *array.Binary:
+		return append([]byte(nil), a.Value(i)...)
+	case *array.LargeBinary:
+		return append([]byte(nil), a.Value(i)...)
+	case *array.Timestamp:
+		unit := a.DataType().(*arrow.TimestampType).Unit
+		return a.Value(i).ToTime(unit).UTC()
+	case *array.Date32:
+		return a.Value(i).ToTime().UTC()
+	case *array.Date64:
+		return a.Value(i).ToTime().UTC()
+	case *array.Time32:
+		unit := a.DataType().(*arrow.Time32Type).Unit
+		return time.Duration(a.Value(i)) * unit.Multiplier()
+	case *array.Time64:
+		unit := a.DataType().(*arrow.Time64Type).Unit
+		return time.Duration(a.Value(i)) * unit.Multiplier()
+	case *array.Duration:
+		unit := a.DataType().(*arrow.DurationType).Unit
+		return time.Duration(a.Value(i)) * unit.Multiplier()
+	default:
+		return arr.ValueStr(i)
+	}
+}
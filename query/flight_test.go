@@ -0,0 +1,95 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+type fakeStream struct {
+	messages []*flight.FlightData
+}
+
+func (s *fakeStream) Send(data *flight.FlightData) error {
+	// the writer reuses the message, so it must be copied
+	s.messages = append(s.messages, proto.Clone(data).(*flight.FlightData))
+	return nil
+}
+
+func (s *fakeStream) Recv() (*flight.FlightData, error) {
+	if len(s.messages) == 0 {
+		return nil, io.EOF
+	}
+	data := s.messages[0]
+	s.messages = s.messages[1:]
+	return data, nil
+}
+
+func TestFromFlightStreamRecords(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "host", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "cpu", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "ts", Type: &arrow.TimestampType{Unit: arrow.Millisecond}},
+	}, nil)
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.StringBuilder).AppendValues([]string{"127.0.0.1", "127.0.0.2"}, nil)
+	builder.Field(1).(*array.Float64Builder).AppendValues([]float64{0.5, 0}, []bool{true, false})
+	builder.Field(2).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{1700000000000, 1700000000001}, nil)
+	record := builder.NewRecord()
+	defer record.Release()
+
+	stream := &fakeStream{}
+	writer := flight.NewRecordWriter(stream, ipc.WithSchema(schema))
+	assert.Nil(t, writer.Write(record))
+	assert.Nil(t, writer.Close())
+
+	// the metrics of the query are sent as the app metadata at last
+	stream.messages = append(stream.messages, &flight.FlightData{AppMetadata: []byte{}})
+
+	result, err := FromFlightStream(stream)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"host", "cpu", "ts"}, result.ColumnNames())
+	assert.Equal(t, "timestamp[ms]", result.Columns[2].DataType)
+	assert.Equal(t, [][]any{
+		{"127.0.0.1", 0.5, time.UnixMilli(1700000000000).UTC()},
+		{"127.0.0.2", nil, time.UnixMilli(1700000000001).UTC()},
+	}, result.Rows)
+}
+
+func TestFromFlightStreamAffectedRows(t *testing.T) {
+	metadata, err := proto.Marshal(&gpb.FlightMetadata{AffectedRows: &gpb.AffectedRows{Value: 3}})
+	assert.Nil(t, err)
+
+	stream := &fakeStream{messages: []*flight.FlightData{{AppMetadata: metadata}}}
+	result, err := FromFlightStream(stream)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(3), result.AffectedRows)
+	assert.Empty(t, result.Rows)
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// PromSample is a sample of a Prometheus time series.
+type PromSample struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// PromSeries is a Prometheus time series.
+type PromSeries struct {
+	Labels  map[string]string
+	Samples []PromSample
+}
+
+// PromResult is the result of a PromQL query.
+type PromResult struct {
+	ResultType string // matrix, vector or scalar, the string result is not supported
+	Series     []PromSeries
+}
+
+type promResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+type promSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values [][2]any          `json:"values"`
+	Value  *[2]any           `json:"value"`
+}
+
+// ParsePromResponse parses the body of a PromQL query, which is the same JSON format as Prometheus.
+func ParsePromResponse(body []byte) (*PromResult, error) {
+	var resp promResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("promql query failed: %s: %s", resp.ErrorType, resp.Error)
+	}
+
+	result := &PromResult{ResultType: resp.Data.ResultType}
+	switch resp.Data.ResultType {
+	case "matrix", "vector":
+		var series []promSeries
+		if err := json.Unmarshal(resp.Data.Result, &series); err != nil {
+			return nil, err
+		}
+
+		for _, s := range series {
+			values := s.Values
+			if s.Value != nil {
+				values = append(values, *s.Value)
+			}
+
+			samples := make([]PromSample, 0, len(values))
+			for _, value := range values {
+				sample, err := parsePromSample(value)
+				if err != nil {
+					return nil, err
+				}
+				samples = append(samples, sample)
+			}
+			result.Series = append(result.Series, PromSeries{Labels: s.Metric, Samples: samples})
+		}
+	case "scalar":
+		var value [2]any
+		if err := json.Unmarshal(resp.Data.Result, &value); err != nil {
+			return nil, err
+		}
+		sample, err := parsePromSample(value)
+		if err != nil {
+			return nil, err
+		}
+		result.Series = []PromSeries{{Samples: []PromSample{sample}}}
+	default:
+		return nil, fmt.Errorf("unsupported promql result type %q", resp.Data.ResultType)
+	}
+	return result, nil
+}
+
+func parsePromSample(value [2]any) (PromSample, error) {
+	ts, ok := value[0].(float64)
+	if !ok {
+		return PromSample{}, fmt.Errorf("invalid timestamp of sample %v", value)
+	}
+
+	str, ok := value[1].(string)
+	if !ok {
+		return PromSample{}, fmt.Errorf("invalid value of sample %v", value)
+	}
+	val, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return PromSample{}, err
+	}
+
+	sec, frac := math.Modf(ts)
+	timestamp := time.Unix(int64(sec), int64(math.Round(frac*1e3))*int64(time.Millisecond)).UTC()
+	return PromSample{Timestamp: timestamp, Value: val}, nil
+}
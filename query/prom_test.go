@@ -0,0 +1,64 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePromResponseMatrix(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"__name__":"up","host":"h1"},"values":[[1700000000,"1"],[1700000000.5,"0.5"]]}]}}`
+
+	result, err := ParsePromResponse([]byte(body))
+	assert.Nil(t, err)
+	assert.Equal(t, "matrix", result.ResultType)
+	assert.Len(t, result.Series, 1)
+	assert.Equal(t, map[string]string{"__name__": "up", "host": "h1"}, result.Series[0].Labels)
+	assert.Equal(t, []PromSample{
+		{Timestamp: time.UnixMilli(1700000000000).UTC(), Value: 1},
+		{Timestamp: time.UnixMilli(1700000000500).UTC(), Value: 0.5},
+	}, result.Series[0].Samples)
+}
+
+func TestParsePromResponseVectorAndScalar(t *testing.T) {
+	vector := `{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"host":"h1"},"value":[1700000000,"3"]}]}}`
+	result, err := ParsePromResponse([]byte(vector))
+	assert.Nil(t, err)
+	assert.Len(t, result.Series, 1)
+	assert.Equal(t, 3.0, result.Series[0].Samples[0].Value)
+
+	scalar := `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"NaN"]}}`
+	result, err = ParsePromResponse([]byte(scalar))
+	assert.Nil(t, err)
+	assert.Len(t, result.Series, 1)
+	assert.True(t, result.Series[0].Samples[0].Value != result.Series[0].Samples[0].Value)
+}
+
+func TestParsePromResponseError(t *testing.T) {
+	body := `{"status":"error","errorType":"bad_data","error":"parse error"}`
+	_, err := ParsePromResponse([]byte(body))
+	assert.ErrorContains(t, err, "parse error")
+
+	body = `{"status":"success","data":{"resultType":"string","result":[1700000000,"hello"]}}`
+	_, err = ParsePromResponse([]byte(body))
+	assert.ErrorContains(t, err, `unsupported promql result type "string"`)
+}
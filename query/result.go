@@ -0,0 +1,69 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"github.com/GreptimeTeam/greptimedb-ingester-go/schema"
+)
+
+// Column describes a column of the query result.
+type Column struct {
+	Name     string
+	DataType string // the Arrow data type, e.g. int64, utf8, timestamp[ms]
+	Nullable bool
+}
+
+// Result is the result of a query. Rows are decoded into Go values:
+//
+//   - integers, floats, bool, string and []byte are the matched Go types
+//   - timestamp and date are time.Time in UTC
+//   - time and duration are time.Duration
+//   - null is nil
+//   - other types are the string representation of the value
+//
+// For the statements which do not return rows, like INSERT or DELETE,
+// only AffectedRows is set.
+type Result struct {
+	Columns      []Column
+	Rows         [][]any
+	AffectedRows uint32
+}
+
+// ColumnNames returns the names of the columns.
+func (r *Result) ColumnNames() []string {
+	names := make([]string, 0, len(r.Columns))
+	for _, column := range r.Columns {
+		names = append(names, column.Name)
+	}
+	return names
+}
+
+// Scan assigns the rows into dest, which is a pointer to a struct or a pointer to a
+// slice of struct. The column of the field is defined in the struct tag the same way
+// as [greptime.Client.WriteObject].
+//
+//	type Monitor struct {
+//	  ID   int64     `greptime:"tag;column:id;type:int64"`
+//	  Host string    `greptime:"tag;column:host;type:string"`
+//	  Ts   time.Time `greptime:"timestamp;column:ts;type:timestamp;precision:millisecond"`
+//	}
+//
+//	var monitors []Monitor
+//	err := result.Scan(&monitors)
+func (r *Result) Scan(dest any) error {
+	return schema.ScanRows(r.ColumnNames(), r.Rows, dest)
+}
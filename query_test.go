@@ -0,0 +1,69 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package greptime
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryMonitors(t *testing.T) {
+	ts1 := time.Now().Add(-1 * time.Minute).UnixMilli()
+	ts2 := time.Now().Add(-2 * time.Minute).UnixMilli()
+
+	monitors := []monitor{
+		{
+			ID:          randomId(),
+			Host:        "127.0.0.1",
+			Memory:      1,
+			Cpu:         1.0,
+			Temperature: -1,
+			Ts:          time.UnixMilli(ts1).UTC(),
+			Running:     true,
+		},
+		{
+			ID:          randomId(),
+			Host:        "127.0.0.2",
+			Memory:      2,
+			Cpu:         2.0,
+			Temperature: -2,
+			Ts:          time.UnixMilli(ts2).UTC(),
+			Running:     false,
+		},
+	}
+
+	resp, err := cli.WriteObject(context.Background(), monitors)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(len(monitors)), resp.GetAffectedRows().GetValue())
+
+	result, err := cli.Query(context.Background(),
+		fmt.Sprintf("select * from %s where id in %s order by host asc", monitorTableName, getMonitorsIds(monitors)))
+	assert.Nil(t, err)
+	assert.Len(t, result.Rows, len(monitors))
+	assert.Contains(t, result.ColumnNames(), "host")
+
+	var monitors_ []monitor
+	assert.Nil(t, result.Scan(&monitors_))
+	assert.Equal(t, monitors, monitors_)
+
+	_, err = cli.Query(context.Background(), " ")
+	assert.NotNil(t, err)
+}
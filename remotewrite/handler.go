@@ -0,0 +1,146 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remotewrite
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+
+	ingesterContext "github.com/GreptimeTeam/greptimedb-ingester-go/context"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+)
+
+const (
+	// DefaultPhysicalTable is the physical table of the metric engine, which is the same as
+	// the remote write endpoint of GreptimeDB.
+	DefaultPhysicalTable = "greptime_physical_table"
+
+	// DefaultMaxBodySize is the max size of the snappy compressed request body.
+	DefaultMaxBodySize = 32 << 20
+	// DefaultMaxDecodedSize is the max size of the decompressed request.
+	DefaultMaxDecodedSize = 128 << 20
+)
+
+// Writer writes the tables, like greptime.Client.
+type Writer interface {
+	Write(ctx context.Context, tables ...*table.Table) (*gpb.GreptimeResponse, error)
+}
+
+// Handler is the http.Handler receiving the Prometheus remote write requests, and writing
+// them by the Writer. Call NewHandler() to create it.
+//
+//	http.Handle("/api/v1/write", remotewrite.NewHandler(client))
+type Handler struct {
+	writer         Writer
+	physicalTable  string
+	maxBodySize    int64
+	maxDecodedSize int
+}
+
+// NewHandler creates the handler writing into the logical tables of DefaultPhysicalTable.
+func NewHandler(writer Writer) *Handler {
+	return &Handler{
+		writer:         writer,
+		physicalTable:  DefaultPhysicalTable,
+		maxBodySize:    DefaultMaxBodySize,
+		maxDecodedSize: DefaultMaxDecodedSize,
+	}
+}
+
+// WithPhysicalTable sets the physical table of the metric engine. If it is empty, the
+// tables are created without the metric engine.
+func (h *Handler) WithPhysicalTable(name string) *Handler {
+	h.physicalTable = name
+	return h
+}
+
+// WithMaxBodySize sets the max size of the snappy compressed request body, the default is
+// DefaultMaxBodySize. 0 means no limit.
+func (h *Handler) WithMaxBodySize(size int64) *Handler {
+	h.maxBodySize = size
+	return h
+}
+
+// WithMaxDecodedSize sets the max size of the decompressed request, the default is
+// DefaultMaxDecodedSize. 0 means no limit.
+func (h *Handler) WithMaxDecodedSize(size int) *Handler {
+	h.maxDecodedSize = size
+	return h
+}
+
+// ServeHTTP responds 204 if the request is written, 400 if it is invalid, 413 if it is
+// too large, and 500 if it fails to be written.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// remote write 2.0 is sent as io.prometheus.write.v2.Request
+	if strings.Contains(r.Header.Get("Content-Type"), "io.prometheus.write.v2") {
+		http.Error(w, "remote write 2.0 is not supported", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if h.maxBodySize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, h.maxBodySize)
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req, err := decode(body, h.maxDecodedSize)
+	if errors.Is(err, errs.ErrRequestTooLarge) {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tables, err := ToTables(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(tables) > 0 {
+		ctx := r.Context()
+		if h.physicalTable != "" {
+			ctx = ingesterContext.New(ctx, ingesterContext.WithPhysicalTable(h.physicalTable))
+		}
+		if _, err := h.writer.Write(ctx, tables...); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
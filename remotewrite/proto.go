@@ -0,0 +1,62 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remotewrite
+
+import (
+	"fmt"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+)
+
+// Decode decodes the snappy compressed protobuf body of the remote write request. The
+// decompressed request should not exceed DefaultMaxDecodedSize.
+func Decode(body []byte) (*prompb.WriteRequest, error) {
+	return decode(body, DefaultMaxDecodedSize)
+}
+
+func decode(body []byte, maxDecodedSize int) (*prompb.WriteRequest, error) {
+	size, err := snappy.DecodedLen(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress the request: %w", err)
+	}
+	if maxDecodedSize > 0 && size > maxDecodedSize {
+		return nil, fmt.Errorf("%w: %d bytes decompressed exceeds %d", errs.ErrRequestTooLarge, size, maxDecodedSize)
+	}
+
+	data, err := snappy.Decode(nil, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress the request: %w", err)
+	}
+
+	req := &prompb.WriteRequest{}
+	if err := req.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Encode encodes the request into the snappy compressed protobuf body, like Prometheus.
+func Encode(req *prompb.WriteRequest) ([]byte, error) {
+	data, err := req.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return snappy.Encode(nil, data), nil
+}
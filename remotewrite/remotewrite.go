@@ -0,0 +1,142 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package remotewrite converts the Prometheus remote write requests into tables in the
+// layout of the metric engine of GreptimeDB, which is the same as the remote write
+// endpoint of GreptimeDB. Every metric is a table, the labels are the tag columns, and
+// the samples are in the greptime_value and greptime_timestamp columns.
+package remotewrite
+
+import (
+	"fmt"
+
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+const (
+	// MetricNameLabel is the label of the metric name, which is the table name.
+	MetricNameLabel = "__name__"
+
+	ValueColumn     = "greptime_value"
+	TimestampColumn = "greptime_timestamp"
+)
+
+// metric is the labels and series of a metric, the labels are in the order they first appear.
+type metric struct {
+	labels []string
+	seen   map[string]bool
+	series []*prompb.TimeSeries
+}
+
+// ToTables converts the request into tables, one table for each metric in the order
+// they first appear. The series without the samples are ignored.
+func ToTables(req *prompb.WriteRequest) ([]*table.Table, error) {
+	var names []string
+	metrics := make(map[string]*metric)
+
+	for i := range req.Timeseries {
+		series := &req.Timeseries[i]
+		if len(series.Samples) == 0 {
+			continue
+		}
+
+		name := ""
+		for _, label := range series.Labels {
+			if label.Name == MetricNameLabel {
+				name = label.Value
+			}
+		}
+		if name == "" {
+			return nil, fmt.Errorf("series %d: missing metric name label %s", i, MetricNameLabel)
+		}
+
+		m, ok := metrics[name]
+		if !ok {
+			m = &metric{seen: make(map[string]bool)}
+			metrics[name] = m
+			names = append(names, name)
+		}
+		for _, label := range series.Labels {
+			switch label.Name {
+			case MetricNameLabel:
+				continue
+			case ValueColumn, TimestampColumn:
+				return nil, fmt.Errorf("series %d: label %s conflicts with the column of samples", i, label.Name)
+			}
+			if !m.seen[label.Name] {
+				m.seen[label.Name] = true
+				m.labels = append(m.labels, label.Name)
+			}
+		}
+		m.series = append(m.series, series)
+	}
+
+	tables := make([]*table.Table, 0, len(names))
+	for _, name := range names {
+		tbl, err := metrics[name].toTable(name)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, tbl)
+	}
+	return tables, nil
+}
+
+func (m *metric) toTable(name string) (*table.Table, error) {
+	// the names of metrics and labels are kept as is
+	tbl, err := table.New(name)
+	if err != nil {
+		return nil, err
+	}
+	tbl.WithSanitate(false)
+
+	if err := tbl.AddTimestampColumn(TimestampColumn, types.TIMESTAMP_MILLISECOND); err != nil {
+		return nil, err
+	}
+	if err := tbl.AddFieldColumn(ValueColumn, types.FLOAT64); err != nil {
+		return nil, err
+	}
+	for _, label := range m.labels {
+		if err := tbl.AddTagColumn(label, types.STRING); err != nil {
+			return nil, err
+		}
+	}
+
+	indexes := make(map[string]int, len(m.labels))
+	for i, label := range m.labels {
+		indexes[label] = i + 2
+	}
+
+	inputs := make([]any, len(m.labels)+2)
+	for _, series := range m.series {
+		clear(inputs)
+		for _, label := range series.Labels {
+			if idx, ok := indexes[label.Name]; ok {
+				inputs[idx] = label.Value
+			}
+		}
+		for _, sample := range series.Samples {
+			inputs[0], inputs[1] = sample.Timestamp, sample.Value
+			if err := tbl.AddRow(inputs...); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return tbl, nil
+}
@@ -0,0 +1,205 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+)
+
+func newWriteRequest() *prompb.WriteRequest {
+	return &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		{
+			Labels:  newLabels(MetricNameLabel, "up", "instance", "a:9090", "job", "prometheus"),
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 1700000000000}, {Value: 0, Timestamp: 1700000015000}},
+		},
+		{
+			Labels:  newLabels(MetricNameLabel, "http_requests_total", "code", "200"),
+			Samples: []prompb.Sample{{Value: math.Inf(1), Timestamp: 1700000000000}},
+		},
+		{
+			Labels:  newLabels(MetricNameLabel, "up", "instance", "b:9100", "zone", "us"),
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 1700000000000}},
+		},
+		{
+			Labels: newLabels(MetricNameLabel, "empty"),
+		},
+	}}
+}
+
+func newLabels(pairs ...string) []prompb.Label {
+	labels := make([]prompb.Label, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		labels = append(labels, prompb.Label{Name: pairs[i], Value: pairs[i+1]})
+	}
+	return labels
+}
+
+func encode(t *testing.T, req *prompb.WriteRequest) []byte {
+	body, err := Encode(req)
+	assert.Nil(t, err)
+	return body
+}
+
+func TestDecode(t *testing.T) {
+	req := newWriteRequest()
+	decoded, err := Decode(encode(t, req))
+	assert.Nil(t, err)
+	assert.Equal(t, req.Timeseries[:3], decoded.Timeseries[:3])
+	assert.Empty(t, decoded.Timeseries[3].Samples)
+
+	_, err = Decode([]byte("invalid"))
+	assert.NotNil(t, err)
+	_, err = Decode(encode(t, req)[:20])
+	assert.NotNil(t, err)
+}
+
+func TestToTables(t *testing.T) {
+	tables, err := ToTables(newWriteRequest())
+	assert.Nil(t, err)
+	assert.Len(t, tables, 2)
+
+	up := tables[0]
+	name, err := up.GetName()
+	assert.Nil(t, err)
+	assert.Equal(t, "up", name)
+
+	expected := []*gpb.ColumnSchema{
+		{ColumnName: TimestampColumn, SemanticType: gpb.SemanticType_TIMESTAMP, Datatype: gpb.ColumnDataType_TIMESTAMP_MILLISECOND},
+		{ColumnName: ValueColumn, SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_FLOAT64},
+		{ColumnName: "instance", SemanticType: gpb.SemanticType_TAG, Datatype: gpb.ColumnDataType_STRING},
+		{ColumnName: "job", SemanticType: gpb.SemanticType_TAG, Datatype: gpb.ColumnDataType_STRING},
+		{ColumnName: "zone", SemanticType: gpb.SemanticType_TAG, Datatype: gpb.ColumnDataType_STRING},
+	}
+	assert.Equal(t, expected, up.GetColumnsSchema())
+
+	rows := up.GetRows().Rows
+	assert.Len(t, rows, 3)
+	assert.Equal(t, int64(1700000015000), rows[1].Values[0].GetTimestampMillisecondValue())
+	assert.Equal(t, 0.0, rows[1].Values[1].GetF64Value())
+	assert.Equal(t, "prometheus", rows[1].Values[3].GetStringValue())
+	assert.Nil(t, rows[1].Values[4].GetValueData())
+	assert.Equal(t, "b:9100", rows[2].Values[2].GetStringValue())
+	assert.Nil(t, rows[2].Values[3].GetValueData())
+	assert.Equal(t, "us", rows[2].Values[4].GetStringValue())
+
+	assert.True(t, math.IsInf(tables[1].GetRows().Rows[0].Values[1].GetF64Value(), 1))
+}
+
+func TestToTablesInvalid(t *testing.T) {
+	_, err := ToTables(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		{Labels: newLabels("job", "prometheus"), Samples: []prompb.Sample{{Value: 1, Timestamp: 1}}},
+	}})
+	assert.ErrorContains(t, err, MetricNameLabel)
+
+	_, err = ToTables(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		{Labels: newLabels(MetricNameLabel, "up", ValueColumn, "1"), Samples: []prompb.Sample{{Value: 1, Timestamp: 1}}},
+	}})
+	assert.NotNil(t, err)
+}
+
+type fakeWriter struct {
+	ctx    context.Context
+	tables []*table.Table
+	err    error
+}
+
+func (w *fakeWriter) Write(ctx context.Context, tables ...*table.Table) (*gpb.GreptimeResponse, error) {
+	w.ctx, w.tables = ctx, tables
+	return &gpb.GreptimeResponse{}, w.err
+}
+
+func TestHandler(t *testing.T) {
+	writer := &fakeWriter{}
+	server := httptest.NewServer(NewHandler(writer))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "application/x-protobuf", bytes.NewReader(encode(t, newWriteRequest())))
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Len(t, writer.tables, 2)
+
+	md, _ := metadata.FromOutgoingContext(writer.ctx)
+	assert.Equal(t, []string{DefaultPhysicalTable}, md.Get("x-greptime-hint-physical_table"))
+
+	resp, err = http.Post(server.URL, "application/x-protobuf", bytes.NewReader([]byte("invalid")))
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Post(server.URL, "application/x-protobuf;proto=io.prometheus.write.v2.Request", nil)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+
+	resp, err = http.Get(server.URL)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	writer.err = errors.New("unavailable")
+	resp, err = http.Post(server.URL, "application/x-protobuf", bytes.NewReader(encode(t, newWriteRequest())))
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestHandlerWithoutPhysicalTable(t *testing.T) {
+	writer := &fakeWriter{}
+	handler := NewHandler(writer).WithPhysicalTable("")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(encode(t, newWriteRequest()))))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+
+	md, _ := metadata.FromOutgoingContext(writer.ctx)
+	assert.Empty(t, md.Get("x-greptime-hint-physical_table"))
+}
+
+func TestHandlerTooLarge(t *testing.T) {
+	writer := &fakeWriter{}
+	body := encode(t, newWriteRequest())
+
+	recorder := httptest.NewRecorder()
+	handler := NewHandler(writer).WithMaxBodySize(int64(len(body) - 1))
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	handler = NewHandler(writer).WithMaxDecodedSize(newWriteRequest().Size() - 1)
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+	assert.Nil(t, writer.tables)
+
+	recorder = httptest.NewRecorder()
+	handler = NewHandler(writer).WithMaxBodySize(int64(len(body))).WithMaxDecodedSize(newWriteRequest().Size())
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+}
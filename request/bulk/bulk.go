@@ -0,0 +1,336 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package bulk converts tables into Arrow record batches, which are written
+// into GreptimeDB via the DoPut endpoint of Arrow Flight.
+package bulk
+
+import (
+	"encoding/json"
+	"fmt"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/decimal128"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+// Metadata is the app metadata of every record batch sent via DoPut.
+type Metadata struct {
+	RequestID int64 `json:"request_id"`
+}
+
+// Result is the app metadata of every PutResult GreptimeDB responds.
+type Result struct {
+	RequestID    int64   `json:"request_id"`
+	AffectedRows uint64  `json:"affected_rows"`
+	ElapsedSecs  float64 `json:"elapsed_secs"`
+}
+
+// BuildMetadata builds the app metadata of the record batch.
+func BuildMetadata(requestID int64) ([]byte, error) {
+	return json.Marshal(Metadata{RequestID: requestID})
+}
+
+// ParseResult parses the app metadata of the PutResult.
+func ParseResult(appMetadata []byte) (*Result, error) {
+	var result Result
+	if err := json.Unmarshal(appMetadata, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Schema converts the columns into the Arrow schema.
+func Schema(columns []*gpb.ColumnSchema) (*arrow.Schema, error) {
+	fields := make([]arrow.Field, 0, len(columns))
+	for _, column := range columns {
+		if types.IsJSONBinary(column.DatatypeExtension) {
+			return nil, fmt.Errorf("column %q: JSON is not supported by bulk write", column.ColumnName)
+		}
+		dataType, err := arrowType(column.Datatype, column.DatatypeExtension)
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", column.ColumnName, err)
+		}
+		fields = append(fields, arrow.Field{
+			Name:     column.ColumnName,
+			Type:     dataType,
+			Nullable: column.SemanticType != gpb.SemanticType_TIMESTAMP,
+		})
+	}
+	return arrow.NewSchema(fields, nil), nil
+}
+
+// NewRecord converts the table into an Arrow record batch of the schema, which MUST be
+// built from the columns of the table. The caller should release it.
+//
+// The table built by table.Builder is converted from its column buffers, which is much
+// cheaper than the rows added by AddRow.
+func NewRecord(mem memory.Allocator, schema *arrow.Schema, tbl *table.Table) (arrow.Record, error) {
+	if tbl.IsRowEmpty() {
+		return nil, errs.ErrEmptyTable
+	}
+
+	columns := tbl.GetColumnsSchema()
+	if len(columns) != schema.NumFields() {
+		return nil, fmt.Errorf("table has %d columns, but schema has %d fields", len(columns), schema.NumFields())
+	}
+
+	builder := array.NewRecordBuilder(mem, schema)
+	defer builder.Release()
+
+	rows := tbl.GetRows().Rows
+	for i, column := range columns {
+		field := schema.Field(i)
+		if field.Name != column.ColumnName {
+			return nil, fmt.Errorf("column %q does not match field %q of schema", column.ColumnName, field.Name)
+		}
+
+		if err := appendColumn(builder.Field(i), tbl, i, rows); err != nil {
+			return nil, fmt.Errorf("column %q: %w", column.ColumnName, err)
+		}
+	}
+	return builder.NewRecord(), nil
+}
+
+// appendColumn appends the values of the column col. The buffers of the table built
+// by table.Builder are copied into Arrow as they are, instead of the values of rows.
+func appendColumn(builder array.Builder, tbl *table.Table, col int, rows []*gpb.Row) error {
+	values, ok := tbl.ColumnValues(col)
+	if !ok {
+		builder.Reserve(len(rows))
+		for _, row := range rows {
+			if err := appendValue(builder, row.Values[col]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	valid := values.Valid
+	switch b := builder.(type) {
+	case *array.Int8Builder:
+		appendValues(b, values.Ints, valid, func(v int64) int8 { return int8(v) })
+	case *array.Int16Builder:
+		appendValues(b, values.Ints, valid, func(v int64) int16 { return int16(v) })
+	case *array.Int32Builder:
+		appendValues(b, values.Ints, valid, func(v int64) int32 { return int32(v) })
+	case *array.Int64Builder:
+		b.AppendValues(values.Ints, valid)
+	case *array.Uint8Builder:
+		appendValues(b, values.Uints, valid, func(v uint64) uint8 { return uint8(v) })
+	case *array.Uint16Builder:
+		appendValues(b, values.Uints, valid, func(v uint64) uint16 { return uint16(v) })
+	case *array.Uint32Builder:
+		appendValues(b, values.Uints, valid, func(v uint64) uint32 { return uint32(v) })
+	case *array.Uint64Builder:
+		b.AppendValues(values.Uints, valid)
+	case *array.Float32Builder:
+		appendValues(b, values.Floats, valid, func(v float64) float32 { return float32(v) })
+	case *array.Float64Builder:
+		b.AppendValues(values.Floats, valid)
+	case *array.BooleanBuilder:
+		b.AppendValues(values.Bools, valid)
+	case *array.BinaryBuilder:
+		b.AppendValues(values.Bytes, valid)
+	case *array.StringBuilder:
+		b.AppendValues(values.Strings, valid)
+	case *array.Date32Builder:
+		appendValues(b, values.Ints, valid, func(v int64) arrow.Date32 { return arrow.Date32(v) })
+	case *array.TimestampBuilder:
+		appendValues(b, values.Ints, valid, func(v int64) arrow.Timestamp { return arrow.Timestamp(v) })
+	case *array.Time32Builder:
+		appendValues(b, values.Ints, valid, func(v int64) arrow.Time32 { return arrow.Time32(v) })
+	case *array.Time64Builder:
+		appendValues(b, values.Ints, valid, func(v int64) arrow.Time64 { return arrow.Time64(v) })
+	default:
+		// the null values are kept as the empty values
+		builder.Reserve(len(values.Others))
+		for _, value := range values.Others {
+			if err := appendValue(builder, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type valueBuilder[T any] interface {
+	Append(T)
+	AppendNull()
+	Reserve(int)
+}
+
+// appendValues appends the values converted, or null if they are not valid.
+func appendValues[V, T any](builder valueBuilder[T], values []V, valid []bool, convert func(V) T) {
+	builder.Reserve(len(values))
+	for i, v := range values {
+		if valid[i] {
+			builder.Append(convert(v))
+		} else {
+			builder.AppendNull()
+		}
+	}
+}
+
+func arrowType(dataType gpb.ColumnDataType, ext *gpb.ColumnDataTypeExtension) (arrow.DataType, error) {
+	switch dataType {
+	case gpb.ColumnDataType_INT8:
+		return arrow.PrimitiveTypes.Int8, nil
+	case gpb.ColumnDataType_INT16:
+		return arrow.PrimitiveTypes.Int16, nil
+	case gpb.ColumnDataType_INT32:
+		return arrow.PrimitiveTypes.Int32, nil
+	case gpb.ColumnDataType_INT64:
+		return arrow.PrimitiveTypes.Int64, nil
+	case gpb.ColumnDataType_UINT8:
+		return arrow.PrimitiveTypes.Uint8, nil
+	case gpb.ColumnDataType_UINT16:
+		return arrow.PrimitiveTypes.Uint16, nil
+	case gpb.ColumnDataType_UINT32:
+		return arrow.PrimitiveTypes.Uint32, nil
+	case gpb.ColumnDataType_UINT64:
+		return arrow.PrimitiveTypes.Uint64, nil
+	case gpb.ColumnDataType_FLOAT32:
+		return arrow.PrimitiveTypes.Float32, nil
+	case gpb.ColumnDataType_FLOAT64:
+		return arrow.PrimitiveTypes.Float64, nil
+	case gpb.ColumnDataType_BOOLEAN:
+		return arrow.FixedWidthTypes.Boolean, nil
+	case gpb.ColumnDataType_BINARY:
+		return arrow.BinaryTypes.Binary, nil
+	case gpb.ColumnDataType_STRING:
+		return arrow.BinaryTypes.String, nil
+	case gpb.ColumnDataType_DATE:
+		return arrow.FixedWidthTypes.Date32, nil
+	case gpb.ColumnDataType_DATETIME, gpb.ColumnDataType_TIMESTAMP_MICROSECOND:
+		return &arrow.TimestampType{Unit: arrow.Microsecond}, nil
+	case gpb.ColumnDataType_TIMESTAMP_SECOND:
+		return &arrow.TimestampType{Unit: arrow.Second}, nil
+	case gpb.ColumnDataType_TIMESTAMP_MILLISECOND:
+		return &arrow.TimestampType{Unit: arrow.Millisecond}, nil
+	case gpb.ColumnDataType_TIMESTAMP_NANOSECOND:
+		return &arrow.TimestampType{Unit: arrow.Nanosecond}, nil
+	case gpb.ColumnDataType_TIME_SECOND:
+		return arrow.FixedWidthTypes.Time32s, nil
+	case gpb.ColumnDataType_TIME_MILLISECOND:
+		return arrow.FixedWidthTypes.Time32ms, nil
+	case gpb.ColumnDataType_TIME_MICROSECOND:
+		return arrow.FixedWidthTypes.Time64us, nil
+	case gpb.ColumnDataType_TIME_NANOSECOND:
+		return arrow.FixedWidthTypes.Time64ns, nil
+	case gpb.ColumnDataType_INTERVAL_YEAR_MONTH:
+		return arrow.FixedWidthTypes.MonthInterval, nil
+	case gpb.ColumnDataType_INTERVAL_DAY_TIME:
+		return arrow.FixedWidthTypes.DayTimeInterval, nil
+	case gpb.ColumnDataType_INTERVAL_MONTH_DAY_NANO:
+		return arrow.FixedWidthTypes.MonthDayNanoInterval, nil
+	case gpb.ColumnDataType_DECIMAL128:
+		precision, scale := types.DecimalPrecisionScale(ext)
+		return &arrow.Decimal128Type{Precision: precision, Scale: scale}, nil
+	default:
+		return nil, fmt.Errorf("data type %s is not supported by bulk write", dataType)
+	}
+}
+
+func appendValue(builder array.Builder, value *gpb.Value) error {
+	if value == nil || value.ValueData == nil {
+		builder.AppendNull()
+		return nil
+	}
+
+	switch b := builder.(type) {
+	case *array.Int8Builder:
+		b.Append(int8(value.GetI8Value()))
+	case *array.Int16Builder:
+		b.Append(int16(value.GetI16Value()))
+	case *array.Int32Builder:
+		b.Append(value.GetI32Value())
+	case *array.Int64Builder:
+		b.Append(value.GetI64Value())
+	case *array.Uint8Builder:
+		b.Append(uint8(value.GetU8Value()))
+	case *array.Uint16Builder:
+		b.Append(uint16(value.GetU16Value()))
+	case *array.Uint32Builder:
+		b.Append(value.GetU32Value())
+	case *array.Uint64Builder:
+		b.Append(value.GetU64Value())
+	case *array.Float32Builder:
+		b.Append(value.GetF32Value())
+	case *array.Float64Builder:
+		b.Append(value.GetF64Value())
+	case *array.BooleanBuilder:
+		b.Append(value.GetBoolValue())
+	case *array.BinaryBuilder:
+		b.Append(value.GetBinaryValue())
+	case *array.StringBuilder:
+		b.Append(value.GetStringValue())
+	case *array.Date32Builder:
+		b.Append(arrow.Date32(value.GetDateValue()))
+	case *array.TimestampBuilder:
+		b.Append(arrow.Timestamp(integerOf(value)))
+	case *array.Time32Builder:
+		b.Append(arrow.Time32(integerOf(value)))
+	case *array.Time64Builder:
+		b.Append(arrow.Time64(integerOf(value)))
+	case *array.MonthIntervalBuilder:
+		b.Append(arrow.MonthInterval(value.GetIntervalYearMonthValue()))
+	case *array.DayTimeIntervalBuilder:
+		v := value.GetIntervalDayTimeValue()
+		b.Append(arrow.DayTimeInterval{Days: int32(v >> 32), Milliseconds: int32(v)})
+	case *array.MonthDayNanoIntervalBuilder:
+		v := value.GetIntervalMonthDayNanoValue()
+		b.Append(arrow.MonthDayNanoInterval{Months: v.GetMonths(), Days: v.GetDays(), Nanoseconds: v.GetNanoseconds()})
+	case *array.Decimal128Builder:
+		d := value.GetDecimal128Value()
+		b.Append(decimal128.New(d.GetHi(), uint64(d.GetLo())))
+	default:
+		return fmt.Errorf("unsupported arrow builder %T", builder)
+	}
+	return nil
+}
+
+// integerOf returns the value of the timestamp and time values.
+func integerOf(value *gpb.Value) int64 {
+	switch v := value.ValueData.(type) {
+	case *gpb.Value_DatetimeValue:
+		return v.DatetimeValue
+	case *gpb.Value_TimestampSecondValue:
+		return v.TimestampSecondValue
+	case *gpb.Value_TimestampMillisecondValue:
+		return v.TimestampMillisecondValue
+	case *gpb.Value_TimestampMicrosecondValue:
+		return v.TimestampMicrosecondValue
+	case *gpb.Value_TimestampNanosecondValue:
+		return v.TimestampNanosecondValue
+	case *gpb.Value_TimeSecondValue:
+		return v.TimeSecondValue
+	case *gpb.Value_TimeMillisecondValue:
+		return v.TimeMillisecondValue
+	case *gpb.Value_TimeMicrosecondValue:
+		return v.TimeMicrosecondValue
+	case *gpb.Value_TimeNanosecondValue:
+		return v.TimeNanosecondValue
+	default:
+		return 0
+	}
+}
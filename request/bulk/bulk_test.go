@@ -0,0 +1,238 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bulk
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+func TestNewRecord(t *testing.T) {
+	tbl, err := table.New("monitor")
+	assert.Nil(t, err)
+	assert.Nil(t, tbl.AddTagColumn("host", types.STRING))
+	assert.Nil(t, tbl.AddFieldColumn("memory", types.UINT64))
+	assert.Nil(t, tbl.AddFieldColumn("cpu", types.FLOAT64))
+	assert.Nil(t, tbl.AddFieldColumn("running", types.BOOLEAN))
+	assert.Nil(t, tbl.AddTimestampColumn("ts", types.TIMESTAMP_MILLISECOND))
+
+	ts := time.UnixMilli(1700000000000)
+	assert.Nil(t, tbl.AddRow("127.0.0.1", 1, 1.5, true, ts))
+	assert.Nil(t, tbl.AddRow("127.0.0.2", nil, nil, false, ts.Add(time.Second)))
+
+	schema, err := Schema(tbl.GetColumnsSchema())
+	assert.Nil(t, err)
+	assert.Equal(t, arrow.BinaryTypes.String, schema.Field(0).Type)
+	assert.Equal(t, &arrow.TimestampType{Unit: arrow.Millisecond}, schema.Field(4).Type)
+	assert.False(t, schema.Field(4).Nullable)
+
+	mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+	defer mem.AssertSize(t, 0)
+
+	record, err := NewRecord(mem, schema, tbl)
+	assert.Nil(t, err)
+	defer record.Release()
+
+	assert.EqualValues(t, 2, record.NumRows())
+	assert.Equal(t, "127.0.0.2", record.Column(0).(*array.String).Value(1))
+	assert.Equal(t, uint64(1), record.Column(1).(*array.Uint64).Value(0))
+	assert.True(t, record.Column(1).IsNull(1))
+	assert.True(t, record.Column(2).IsNull(1))
+	assert.Equal(t, arrow.Timestamp(1700000001000), record.Column(4).(*array.Timestamp).Value(1))
+}
+
+func TestNewRecordDecimal(t *testing.T) {
+	tbl, err := table.New("bill")
+	assert.Nil(t, err)
+	assert.Nil(t, tbl.AddFieldColumn("price", types.DECIMAL128, table.WithDecimal(10, 2)))
+	assert.Nil(t, tbl.AddTimestampColumn("ts", types.TIMESTAMP_MILLISECOND))
+	assert.Nil(t, tbl.AddRow("-12.34", time.UnixMilli(0)))
+
+	schema, err := Schema(tbl.GetColumnsSchema())
+	assert.Nil(t, err)
+	assert.Equal(t, &arrow.Decimal128Type{Precision: 10, Scale: 2}, schema.Field(0).Type)
+
+	record, err := NewRecord(memory.DefaultAllocator, schema, tbl)
+	assert.Nil(t, err)
+	defer record.Release()
+
+	assert.Equal(t, "-12.34", record.Column(0).ValueStr(0))
+}
+
+func TestNewRecordInterval(t *testing.T) {
+	tbl, err := table.New("job")
+	assert.Nil(t, err)
+	assert.Nil(t, tbl.AddFieldColumn("period", types.INTERVAL_YEAR_MONTH))
+	assert.Nil(t, tbl.AddFieldColumn("timeout", types.INTERVAL_DAY_TIME))
+	assert.Nil(t, tbl.AddFieldColumn("schedule", types.INTERVAL))
+	assert.Nil(t, tbl.AddTimestampColumn("ts", types.TIMESTAMP_MILLISECOND))
+	assert.Nil(t, tbl.AddRow(12, 25*time.Hour, types.Interval{Months: 1, Days: 2, Nanoseconds: 3}, time.UnixMilli(0)))
+
+	schema, err := Schema(tbl.GetColumnsSchema())
+	assert.Nil(t, err)
+
+	record, err := NewRecord(memory.DefaultAllocator, schema, tbl)
+	assert.Nil(t, err)
+	defer record.Release()
+
+	assert.Equal(t, arrow.MonthInterval(12), record.Column(0).(*array.MonthInterval).Value(0))
+	assert.Equal(t, arrow.DayTimeInterval{Days: 1, Milliseconds: 3600000}, record.Column(1).(*array.DayTimeInterval).Value(0))
+	assert.Equal(t, arrow.MonthDayNanoInterval{Months: 1, Days: 2, Nanoseconds: 3}, record.Column(2).(*array.MonthDayNanoInterval).Value(0))
+}
+
+func newBuilderTable(t testing.TB) *table.Table {
+	tbl, err := table.New("monitor")
+	assert.Nil(t, err)
+	assert.Nil(t, tbl.AddTagColumn("host", types.STRING))
+	assert.Nil(t, tbl.AddFieldColumn("id", types.INT16))
+	assert.Nil(t, tbl.AddFieldColumn("memory", types.UINT32))
+	assert.Nil(t, tbl.AddFieldColumn("cpu", types.FLOAT32))
+	assert.Nil(t, tbl.AddFieldColumn("running", types.BOOLEAN))
+	assert.Nil(t, tbl.AddFieldColumn("payload", types.BINARY))
+	assert.Nil(t, tbl.AddFieldColumn("price", types.DECIMAL128, table.WithDecimal(10, 2)))
+	assert.Nil(t, tbl.AddFieldColumn("period", types.INTERVAL_YEAR_MONTH))
+	assert.Nil(t, tbl.AddFieldColumn("date", types.DATE))
+	assert.Nil(t, tbl.AddFieldColumn("start", types.TIME_MILLISECOND))
+	assert.Nil(t, tbl.AddTimestampColumn("ts", types.TIMESTAMP_MILLISECOND))
+	return tbl
+}
+
+func TestNewRecordBuilder(t *testing.T) {
+	ts := time.UnixMilli(1700000000000)
+	rows := [][]any{
+		{"127.0.0.1", int16(-1), uint32(2), float32(0.5), true, []byte("a"), "1.25", 12, ts, ts, ts},
+		{"127.0.0.2", nil, nil, nil, nil, nil, nil, nil, nil, nil, ts.Add(time.Second)},
+	}
+
+	expected := newBuilderTable(t)
+	builder, err := table.NewBuilder(newBuilderTable(t))
+	assert.Nil(t, err)
+	for _, row := range rows {
+		assert.Nil(t, expected.AddRow(row...))
+		for col, v := range row {
+			assert.Nil(t, builder.AppendValue(col, v))
+		}
+	}
+	built, err := builder.Build()
+	assert.Nil(t, err)
+	_, ok := built.ColumnValues(0)
+	assert.True(t, ok)
+
+	schema, err := Schema(expected.GetColumnsSchema())
+	assert.Nil(t, err)
+
+	mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+	defer mem.AssertSize(t, 0)
+
+	expectedRecord, err := NewRecord(mem, schema, expected)
+	assert.Nil(t, err)
+	defer expectedRecord.Release()
+
+	record, err := NewRecord(mem, schema, built)
+	assert.Nil(t, err)
+	defer record.Release()
+
+	assert.True(t, array.RecordEqual(expectedRecord, record), "%v\n%v", expectedRecord, record)
+	assert.True(t, record.Column(1).IsNull(1))
+	assert.True(t, record.Column(6).IsNull(1))
+}
+
+func TestNewRecordInvalid(t *testing.T) {
+	tbl, err := table.New("monitor")
+	assert.Nil(t, err)
+	assert.Nil(t, tbl.AddTimestampColumn("ts", types.TIMESTAMP_MILLISECOND))
+
+	schema, err := Schema(tbl.GetColumnsSchema())
+	assert.Nil(t, err)
+	_, err = NewRecord(memory.DefaultAllocator, schema, tbl)
+	assert.ErrorIs(t, err, errs.ErrEmptyTable)
+
+	assert.Nil(t, tbl.AddFieldColumn("json", types.JSON))
+	_, err = Schema(tbl.GetColumnsSchema())
+	assert.NotNil(t, err)
+
+	jsonb, err := table.New("monitor")
+	assert.Nil(t, err)
+	assert.Nil(t, jsonb.AddFieldColumn("jsonb", types.JSONB))
+	_, err = Schema(jsonb.GetColumnsSchema())
+	assert.NotNil(t, err)
+}
+
+func TestMetadata(t *testing.T) {
+	metadata, err := BuildMetadata(3)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"request_id":3}`, string(metadata))
+
+	result, err := ParseResult([]byte(`{"request_id":3,"affected_rows":10,"elapsed_secs":0.1}`))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), result.RequestID)
+	assert.Equal(t, uint64(10), result.AffectedRows)
+}
+
+func newMonitorTable(b *testing.B, n int) (*table.Table, *table.Builder) {
+	tbl, err := table.New("monitor")
+	assert.Nil(b, err)
+	assert.Nil(b, tbl.AddTagColumn("host", types.STRING))
+	assert.Nil(b, tbl.AddFieldColumn("memory", types.UINT64))
+	assert.Nil(b, tbl.AddFieldColumn("cpu", types.FLOAT64))
+	assert.Nil(b, tbl.AddFieldColumn("running", types.BOOLEAN))
+	assert.Nil(b, tbl.AddTimestampColumn("ts", types.TIMESTAMP_MILLISECOND))
+
+	builder, err := table.NewBuilder(tbl)
+	assert.Nil(b, err)
+	now := time.Now()
+	for i := 0; i < n; i++ {
+		host, ts := fmt.Sprintf("127.0.0.%d", i%255), now.Add(-time.Duration(i)*time.Millisecond)
+		assert.Nil(b, tbl.AddRow(host, uint64(i), float64(i), i%2 == 0, ts))
+		assert.Nil(b, builder.AppendString(0, host))
+		assert.Nil(b, builder.AppendUint64(1, uint64(i)))
+		assert.Nil(b, builder.AppendFloat64(2, float64(i)))
+		assert.Nil(b, builder.AppendBool(3, i%2 == 0))
+		assert.Nil(b, builder.AppendTimestamp(4, ts))
+	}
+	return tbl, builder
+}
+
+func BenchmarkNewRecord(b *testing.B) {
+	tbl, builder := newMonitorTable(b, 10000)
+	built, err := builder.Build()
+	assert.Nil(b, err)
+	schema, err := Schema(tbl.GetColumnsSchema())
+	assert.Nil(b, err)
+
+	run := func(tbl *table.Table) func(b *testing.B) {
+		return func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				record, err := NewRecord(memory.DefaultAllocator, schema, tbl)
+				assert.Nil(b, err)
+				record.Release()
+			}
+		}
+	}
+	b.Run("Rows", run(tbl))
+	b.Run("Builder", run(built))
+}
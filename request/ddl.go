@@ -0,0 +1,45 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package request
+
+import (
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/request/header"
+)
+
+// BuildCreateTable builds the DDL request to create the table.
+func BuildCreateTable(header *header.Header, expr *gpb.CreateTableExpr) (*gpb.GreptimeRequest, error) {
+	return buildDdl(header, &gpb.DdlRequest{Expr: &gpb.DdlRequest_CreateTable{CreateTable: expr}})
+}
+
+// BuildAlterTable builds the DDL request to alter the table.
+func BuildAlterTable(header *header.Header, expr *gpb.AlterExpr) (*gpb.GreptimeRequest, error) {
+	return buildDdl(header, &gpb.DdlRequest{Expr: &gpb.DdlRequest_Alter{Alter: expr}})
+}
+
+func buildDdl(header *header.Header, ddl *gpb.DdlRequest) (*gpb.GreptimeRequest, error) {
+	header_, err := header.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return &gpb.GreptimeRequest{
+		Header:  header_,
+		Request: &gpb.GreptimeRequest_Ddl{Ddl: ddl},
+	}, nil
+}
@@ -17,6 +17,8 @@
 package header
 
 import (
+	"encoding/base64"
+
 	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
 
 	"github.com/GreptimeTeam/greptimedb-ingester-go/util"
@@ -50,3 +52,12 @@ func (a Auth) buildAuthHeader() *gpb.AuthHeader {
 	}
 
 }
+
+// buildAuthorization builds the value of the authorization metadata, only Basic Auth is supported
+func (a Auth) buildAuthorization() string {
+	if util.IsEmptyString(a.username) || util.IsEmptyString(a.password) {
+		return ""
+	}
+
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(a.username+":"+a.password))
+}
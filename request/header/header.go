@@ -18,11 +18,17 @@ package header
 
 import (
 	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
 	"github.com/GreptimeTeam/greptimedb-ingester-go/util"
 )
 
+const (
+	databaseMetadataKey      = "x-greptime-db-name"
+	authorizationMetadataKey = "authorization"
+)
+
 type Header struct {
 	database string
 	auth     Auth
@@ -56,3 +62,17 @@ func (h *Header) Build() (*gpb.RequestHeader, error) {
 
 	return header, nil
 }
+
+// BuildMetadata builds the gRPC metadata for the endpoints which do not carry
+// the RequestHeader, like DoPut of Arrow Flight.
+func (h *Header) BuildMetadata() (metadata.MD, error) {
+	if util.IsEmptyString(h.database) {
+		return nil, errs.ErrEmptyDatabaseName
+	}
+
+	md := metadata.Pairs(databaseMetadataKey, h.database)
+	if authorization := h.auth.buildAuthorization(); authorization != "" {
+		md.Set(authorizationMetadataKey, authorization)
+	}
+	return md, nil
+}
@@ -41,3 +41,20 @@ func TestHeaderBuild(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotNil(t, gh.Authorization)
 }
+
+func TestHeaderBuildMetadata(t *testing.T) {
+	h := &Header{}
+
+	md, err := h.BuildMetadata()
+	assert.ErrorIs(t, err, errs.ErrEmptyDatabaseName)
+	assert.Nil(t, md)
+
+	md, err = h.WithDatabase("public").BuildMetadata()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"public"}, md.Get("x-greptime-db-name"))
+	assert.Empty(t, md.Get("authorization"))
+
+	md, err = h.WithAuth("user", "pass").BuildMetadata()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Basic dXNlcjpwYXNz"}, md.Get("authorization"))
+}
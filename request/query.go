@@ -0,0 +1,63 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package request
+
+import (
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/request/header"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/util"
+)
+
+// BuildSqlQuery builds the request to execute the sql.
+func BuildSqlQuery(header *header.Header, sql string) (*gpb.GreptimeRequest, error) {
+	if util.IsEmptyString(sql) {
+		return nil, errs.ErrEmptyQuery
+	}
+
+	header_, err := header.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return &gpb.GreptimeRequest{
+		Header: header_,
+		Request: &gpb.GreptimeRequest_Query{
+			Query: &gpb.QueryRequest{
+				Query: &gpb.QueryRequest_Sql{Sql: sql},
+			},
+		},
+	}, nil
+}
+
+// BuildPromRangeQuery builds the request to execute the PromQL range query.
+func BuildPromRangeQuery(header *header.Header, query *gpb.PromRangeQuery) (*gpb.PromqlRequest, error) {
+	if query == nil || util.IsEmptyString(query.Query) {
+		return nil, errs.ErrEmptyQuery
+	}
+
+	header_, err := header.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return &gpb.PromqlRequest{
+		Header: header_,
+		Promql: &gpb.PromqlRequest_RangeQuery{RangeQuery: query},
+	}, nil
+}
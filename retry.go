@@ -0,0 +1,108 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package greptime
+
+import (
+	"context"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/options"
+)
+
+const (
+	meterName         = "github.com/GreptimeTeam/greptimedb-ingester-go"
+	retriesMetricName = "greptimedb.client.retries"
+	retryEventName    = "greptimedb.client.retry"
+	attemptAttrKey    = "greptimedb.client.attempt"
+)
+
+func newRetriesCounter(meter metric.Meter) metric.Int64Counter {
+	counter, err := meter.Int64Counter(retriesMetricName,
+		metric.WithDescription("The number of retried requests sent to GreptimeDB."),
+		metric.WithUnit("{retry}"))
+	if err != nil {
+		return nil
+	}
+	return counter
+}
+
+// handleWithRetry sends the request to GreptimeDB, and retries it according to
+// the retry option of Config. The error is wrapped in errs.RetryError to expose
+// the attempts if the retry option is set.
+func (c *Client) handleWithRetry(ctx context.Context, req *gpb.GreptimeRequest) (*gpb.GreptimeResponse, error) {
+	opt := c.cfg.retry
+	if opt == nil || opt.MaxAttempts <= 1 {
+		return c.client.Handle(ctx, req)
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		var resp *gpb.GreptimeResponse
+		resp, err = c.handleAttempt(ctx, req, opt.AttemptTimeout)
+		if err == nil {
+			return resp, nil
+		}
+
+		if attempt >= opt.MaxAttempts || !isRetriable(ctx, opt, err) || ctx.Err() != nil {
+			return nil, &errs.RetryError{Attempts: attempt, Err: err}
+		}
+
+		timer := time.NewTimer(opt.Backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, &errs.RetryError{Attempts: attempt, Err: err}
+		case <-timer.C:
+		}
+
+		c.recordRetry(ctx, attempt+1)
+	}
+}
+
+// isRetriable reports whether err is retriable. The attempt which exceeds the
+// AttemptTimeout is also retried as long as ctx is not done.
+func isRetriable(ctx context.Context, opt *options.RetryOption, err error) bool {
+	if opt.IsRetriable(err) {
+		return true
+	}
+	return opt.AttemptTimeout > 0 && ctx.Err() == nil && status.Code(err) == codes.DeadlineExceeded
+}
+
+func (c *Client) handleAttempt(ctx context.Context, req *gpb.GreptimeRequest, timeout time.Duration) (*gpb.GreptimeResponse, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return c.client.Handle(ctx, req)
+}
+
+func (c *Client) recordRetry(ctx context.Context, attempt int) {
+	attr := attribute.Int(attemptAttrKey, attempt)
+	if c.retries != nil {
+		c.retries.Add(ctx, 1, metric.WithAttributes(attr))
+	}
+	trace.SpanFromContext(ctx).AddEvent(retryEventName, trace.WithAttributes(attr))
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package greptime
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/options"
+)
+
+// fakeDatabaseClient fails the first failures attempts with err.
+type fakeDatabaseClient struct {
+	gpb.GreptimeDatabaseClient
+
+	failures int32
+	err      error
+	delay    time.Duration
+	attempts atomic.Int32
+}
+
+func (c *fakeDatabaseClient) Handle(ctx context.Context, _ *gpb.GreptimeRequest, _ ...grpc.CallOption) (*gpb.GreptimeResponse, error) {
+	attempt := c.attempts.Add(1)
+	if attempt > c.failures {
+		return &gpb.GreptimeResponse{}, nil
+	}
+
+	if c.delay > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		case <-time.After(c.delay):
+		}
+	}
+	return nil, c.err
+}
+
+func newRetryClient(fake *fakeDatabaseClient, opt options.RetryOption) *Client {
+	opt.BaseBackoff = time.Millisecond
+	opt.Jitter = 0
+	return &Client{cfg: NewConfig("127.0.0.1").WithRetry(opt), client: fake}
+}
+
+func TestRetrySucceeds(t *testing.T) {
+	fake := &fakeDatabaseClient{failures: 2, err: status.Error(codes.Unavailable, "unavailable")}
+	client := newRetryClient(fake, options.NewRetryOption())
+
+	resp, err := client.handleWithRetry(context.Background(), &gpb.GreptimeRequest{})
+	assert.Nil(t, err)
+	assert.NotNil(t, resp)
+	assert.EqualValues(t, 3, fake.attempts.Load())
+}
+
+func TestRetryGiveUp(t *testing.T) {
+	fake := &fakeDatabaseClient{failures: 5, err: status.Error(codes.Unavailable, "unavailable")}
+	client := newRetryClient(fake, options.NewRetryOption())
+
+	_, err := client.handleWithRetry(context.Background(), &gpb.GreptimeRequest{})
+	var retryErr *errs.RetryError
+	assert.True(t, errors.As(err, &retryErr))
+	assert.Equal(t, 3, retryErr.Attempts)
+	assert.Equal(t, codes.Unavailable, status.Code(retryErr.Err))
+	assert.EqualValues(t, 3, fake.attempts.Load())
+}
+
+func TestRetryNotRetriable(t *testing.T) {
+	fake := &fakeDatabaseClient{failures: 5, err: status.Error(codes.InvalidArgument, "invalid")}
+	client := newRetryClient(fake, options.NewRetryOption())
+
+	_, err := client.handleWithRetry(context.Background(), &gpb.GreptimeRequest{})
+	var retryErr *errs.RetryError
+	assert.True(t, errors.As(err, &retryErr))
+	assert.Equal(t, 1, retryErr.Attempts)
+	assert.EqualValues(t, 1, fake.attempts.Load())
+}
+
+func TestRetryAttemptTimeout(t *testing.T) {
+	fake := &fakeDatabaseClient{failures: 1, err: status.Error(codes.Internal, "internal"), delay: time.Second}
+	opt := options.NewRetryOption()
+	opt.AttemptTimeout = time.Millisecond * 10
+	client := newRetryClient(fake, opt)
+
+	resp, err := client.handleWithRetry(context.Background(), &gpb.GreptimeRequest{})
+	assert.Nil(t, err)
+	assert.NotNil(t, resp)
+	assert.EqualValues(t, 2, fake.attempts.Load())
+
+	// the deadline of the request itself is not retried
+	fake = &fakeDatabaseClient{failures: 5, err: status.Error(codes.Internal, "internal"), delay: time.Second}
+	client = newRetryClient(fake, opt)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*5)
+	defer cancel()
+
+	_, err = client.handleWithRetry(ctx, &gpb.GreptimeRequest{})
+	var retryErr *errs.RetryError
+	assert.True(t, errors.As(err, &retryErr))
+	assert.Equal(t, 1, retryErr.Attempts)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(retryErr.Err))
+}
@@ -0,0 +1,250 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/cell"
+)
+
+// codec is the schema of a struct type compiled from the struct tags, it is cached by the
+// type, so the struct tags are parsed only once in the process.
+type codec struct {
+	tableName string
+	fields    []*Field
+	columns   []columnCodec
+
+	// encoder is true if the struct implements Encoder, and columns is empty
+	encoder bool
+}
+
+// columnCodec encodes the struct field of index into the value of the column.
+type columnCodec struct {
+	index  []int
+	encode func(val reflect.Value) (*gpb.Value, error)
+}
+
+var codecs sync.Map // reflect.Type -> *codec
+
+// codecOf returns the cached codec of the struct type, or compiles it at the first time.
+func codecOf(typ reflect.Type) (*codec, error) {
+	if c, ok := codecs.Load(typ); ok {
+		return c.(*codec), nil
+	}
+
+	c, err := compileCodec(typ)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := codecs.LoadOrStore(typ, c)
+	return actual.(*codec), nil
+}
+
+func compileCodec(typ reflect.Type) (*codec, error) {
+	tableName, err := getTableName(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	if reflect.PointerTo(typ).Implements(encoderType) {
+		return compileEncoderCodec(tableName, typ), nil
+	}
+
+	fields, err := parseFlatFields(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &codec{
+		tableName: tableName,
+		fields:    make([]*Field, len(fields)),
+		columns:   make([]columnCodec, len(fields)),
+	}
+	for i, field := range fields {
+		c.fields[i] = field.field
+		c.columns[i] = columnCodec{
+			index:  field.Index,
+			encode: compileEncoder(field.field, field.Type),
+		}
+	}
+	return c, nil
+}
+
+// encodeRow encodes the struct value into the row. The fields in the nil pointers of
+// the embedded structs are null.
+func (c *codec) encodeRow(val reflect.Value) (*gpb.Row, error) {
+	if c.encoder {
+		return encodeEncoderRow(val)
+	}
+
+	values := make([]*gpb.Value, len(c.columns))
+	for i, column := range c.columns {
+		fieldVal, err := val.FieldByIndexErr(column.index)
+		if err != nil {
+			continue
+		}
+
+		if values[i], err = column.encode(fieldVal); err != nil {
+			return nil, err
+		}
+	}
+	return &gpb.Row{Values: values}, nil
+}
+
+// compileEncoder returns the encoder of the field. The common types are encoded
+// directly, and the others are encoded by parseValue.
+func compileEncoder(field *Field, typ reflect.Type) func(val reflect.Value) (*gpb.Value, error) {
+	datatype, ext := field.Datatype, field.DatatypeExtension
+	fallback := func(val reflect.Value) (*gpb.Value, error) {
+		return parseValue(datatype, ext, val)
+	}
+
+	isPtr := typ.Kind() == reflect.Pointer
+	if isPtr {
+		typ = typ.Elem()
+	}
+
+	// the custom types are unwrapped by parseValue
+	if cell.IsCustomType(reflect.PointerTo(typ), datatype) {
+		return fallback
+	}
+
+	fast := compileFastEncoder(datatype, typ)
+	if fast == nil || ext != nil {
+		return fallback
+	}
+
+	return func(val reflect.Value) (*gpb.Value, error) {
+		if isPtr {
+			if val.IsNil() {
+				return nil, nil
+			}
+			val = val.Elem()
+		}
+		return fast(val), nil
+	}
+}
+
+func compileFastEncoder(datatype gpb.ColumnDataType, typ reflect.Type) func(val reflect.Value) *gpb.Value {
+	switch kind := typ.Kind(); {
+	case isIntKind(kind):
+		switch datatype {
+		case gpb.ColumnDataType_INT8:
+			return func(val reflect.Value) *gpb.Value {
+				return &gpb.Value{ValueData: &gpb.Value_I8Value{I8Value: int32(val.Int())}}
+			}
+		case gpb.ColumnDataType_INT16:
+			return func(val reflect.Value) *gpb.Value {
+				return &gpb.Value{ValueData: &gpb.Value_I16Value{I16Value: int32(val.Int())}}
+			}
+		case gpb.ColumnDataType_INT32:
+			return func(val reflect.Value) *gpb.Value {
+				return &gpb.Value{ValueData: &gpb.Value_I32Value{I32Value: int32(val.Int())}}
+			}
+		case gpb.ColumnDataType_INT64:
+			return func(val reflect.Value) *gpb.Value {
+				return &gpb.Value{ValueData: &gpb.Value_I64Value{I64Value: val.Int()}}
+			}
+		}
+
+	case isUintKind(kind):
+		switch datatype {
+		case gpb.ColumnDataType_UINT8:
+			return func(val reflect.Value) *gpb.Value {
+				return &gpb.Value{ValueData: &gpb.Value_U8Value{U8Value: uint32(val.Uint())}}
+			}
+		case gpb.ColumnDataType_UINT16:
+			return func(val reflect.Value) *gpb.Value {
+				return &gpb.Value{ValueData: &gpb.Value_U16Value{U16Value: uint32(val.Uint())}}
+			}
+		case gpb.ColumnDataType_UINT32:
+			return func(val reflect.Value) *gpb.Value {
+				return &gpb.Value{ValueData: &gpb.Value_U32Value{U32Value: uint32(val.Uint())}}
+			}
+		case gpb.ColumnDataType_UINT64:
+			return func(val reflect.Value) *gpb.Value {
+				return &gpb.Value{ValueData: &gpb.Value_U64Value{U64Value: val.Uint()}}
+			}
+		}
+
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		switch datatype {
+		case gpb.ColumnDataType_FLOAT32:
+			return func(val reflect.Value) *gpb.Value {
+				return &gpb.Value{ValueData: &gpb.Value_F32Value{F32Value: float32(val.Float())}}
+			}
+		case gpb.ColumnDataType_FLOAT64:
+			return func(val reflect.Value) *gpb.Value {
+				return &gpb.Value{ValueData: &gpb.Value_F64Value{F64Value: val.Float()}}
+			}
+		}
+
+	case kind == reflect.Bool && datatype == gpb.ColumnDataType_BOOLEAN:
+		return func(val reflect.Value) *gpb.Value {
+			return &gpb.Value{ValueData: &gpb.Value_BoolValue{BoolValue: val.Bool()}}
+		}
+
+	case kind == reflect.String && datatype == gpb.ColumnDataType_STRING:
+		return func(val reflect.Value) *gpb.Value {
+			return &gpb.Value{ValueData: &gpb.Value_StringValue{StringValue: val.String()}}
+		}
+
+	case typ == timeType:
+		switch datatype {
+		case gpb.ColumnDataType_TIMESTAMP_SECOND:
+			return func(val reflect.Value) *gpb.Value {
+				return &gpb.Value{ValueData: &gpb.Value_TimestampSecondValue{TimestampSecondValue: timeOf(val).Unix()}}
+			}
+		case gpb.ColumnDataType_TIMESTAMP_MILLISECOND:
+			return func(val reflect.Value) *gpb.Value {
+				return &gpb.Value{ValueData: &gpb.Value_TimestampMillisecondValue{TimestampMillisecondValue: timeOf(val).UnixMilli()}}
+			}
+		case gpb.ColumnDataType_TIMESTAMP_MICROSECOND:
+			return func(val reflect.Value) *gpb.Value {
+				return &gpb.Value{ValueData: &gpb.Value_TimestampMicrosecondValue{TimestampMicrosecondValue: timeOf(val).UnixMicro()}}
+			}
+		case gpb.ColumnDataType_TIMESTAMP_NANOSECOND:
+			return func(val reflect.Value) *gpb.Value {
+				return &gpb.Value{ValueData: &gpb.Value_TimestampNanosecondValue{TimestampNanosecondValue: timeOf(val).UnixNano()}}
+			}
+		}
+	}
+	return nil
+}
+
+// timeOf avoids copying the time.Time into an interface if val is addressable.
+func timeOf(val reflect.Value) time.Time {
+	if val.CanAddr() {
+		return *val.Addr().Interface().(*time.Time)
+	}
+	return val.Interface().(time.Time)
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Int64
+}
+
+func isUintKind(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uint64
+}
+
+var timeType = reflect.TypeOf(time.Time{})
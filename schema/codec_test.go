@@ -0,0 +1,109 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+type benchmarkMonitor struct {
+	Host        string    `greptime:"tag;column:host;type:string"`
+	Memory      uint64    `greptime:"field;column:memory;type:uint64"`
+	Cpu         float64   `greptime:"field;column:cpu;type:float64"`
+	Temperature int64     `greptime:"field;column:temperature;type:int64"`
+	Running     bool      `greptime:"field;column:running;type:boolean"`
+	Note        *string   `greptime:"field;column:note;type:string"`
+	Ts          time.Time `greptime:"timestamp;column:ts;type:timestamp;precision:millisecond"`
+}
+
+func newBenchmarkMonitors(n int) []benchmarkMonitor {
+	note := "note"
+	ts := time.Now()
+	monitors := make([]benchmarkMonitor, n)
+	for i := range monitors {
+		monitors[i] = benchmarkMonitor{
+			Host:        "127.0.0.1",
+			Memory:      uint64(i),
+			Cpu:         float64(i),
+			Temperature: int64(i),
+			Running:     i%2 == 0,
+			Note:        &note,
+			Ts:          ts.Add(time.Duration(i) * time.Millisecond),
+		}
+	}
+	return monitors
+}
+
+func TestCodecCache(t *testing.T) {
+	typ := reflect.TypeOf(benchmarkMonitor{})
+	c1, err := codecOf(typ)
+	assert.Nil(t, err)
+	c2, err := codecOf(typ)
+	assert.Nil(t, err)
+	assert.Same(t, c1, c2)
+
+	monitors := newBenchmarkMonitors(2)
+	monitors[1].Note = nil
+	tbl1, err := Parse(monitors)
+	assert.Nil(t, err)
+	tbl2, err := Parse(&monitors[0])
+	assert.Nil(t, err)
+
+	// the tables do not share the columns cached
+	assert.NotSame(t, tbl1.GetColumnsSchema()[0], tbl2.GetColumnsSchema()[0])
+	tbl1.GetColumnsSchema()[0].ColumnName = "changed"
+	assert.Equal(t, "host", tbl2.GetColumnsSchema()[0].ColumnName)
+
+	rows := tbl1.GetRows().Rows
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "note", rows[0].Values[5].GetStringValue())
+	assert.Nil(t, rows[1].Values[5])
+	assert.Equal(t, monitors[1].Ts.UnixMilli(), rows[1].Values[6].GetTimestampMillisecondValue())
+	assert.Equal(t, &gpb.Value{ValueData: &gpb.Value_U64Value{U64Value: 1}}, rows[1].Values[1])
+}
+
+func BenchmarkParse10k(b *testing.B) {
+	monitors := newBenchmarkMonitors(10000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Parse(monitors); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParse10kPointers(b *testing.B) {
+	monitors := make([]*benchmarkMonitor, 0, 10000)
+	for _, monitor := range newBenchmarkMonitors(10000) {
+		monitors = append(monitors, &monitor)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Parse(monitors); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
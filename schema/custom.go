@@ -0,0 +1,94 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"database/sql/driver"
+	"encoding"
+	"reflect"
+	"strings"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/cell"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+var (
+	valuerType        = reflect.TypeOf((*types.Valuer)(nil)).Elem()
+	driverValuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// parseCustomType infers the datatype of the custom types, ok is false for the others:
+//
+//   - types.Valuer is the column type returned by GreptimeValue of the zero value
+//   - sql.NullString, sql.Null[T] and the other sql.Null types are the type of the value
+//   - the other driver.Valuer is inferred from Value of the zero value, or STRING
+//   - net.IP, netip.Addr and the other non-basic types implementing encoding.TextMarshaler
+//     are STRING
+func parseCustomType(typ reflect.Type) (gpb.ColumnDataType, bool, error) {
+	if isTimeType(typ) || isDecimalType(typ) || typ == intervalType || typ == rawMessageType {
+		return 0, false, nil
+	}
+
+	ptr := reflect.PointerTo(typ)
+	switch {
+	case isSQLNullType(typ):
+		datatype, err := parseType(typ.Field(0).Type)
+		return datatype, true, err
+
+	case ptr.Implements(valuerType), ptr.Implements(driverValuerType):
+		datatype, err := cell.CustomType(reflect.New(typ).Interface())
+		return datatype, true, err
+
+	case ptr.Implements(textMarshalerType):
+		switch typ.Kind() {
+		case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+			return gpb.ColumnDataType_STRING, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// isSQLNullType reports whether typ is like sql.NullString or sql.Null[T], whose first
+// field is the value, and the second is Valid.
+func isSQLNullType(typ reflect.Type) bool {
+	return typ.PkgPath() == "database/sql" && strings.HasPrefix(typ.Name(), "Null") &&
+		typ.Kind() == reflect.Struct && typ.NumField() == 2 && typ.Field(1).Name == "Valid"
+}
+
+// parseCustomValue builds the value of the custom type like cell.Build, ok is false for
+// the others. The value is copied if it is not addressable, so the methods of the pointer
+// receiver are found.
+func parseCustomValue(typ gpb.ColumnDataType, ext *gpb.ColumnDataTypeExtension, val reflect.Value) (*gpb.Value, bool, error) {
+	if !cell.IsCustomType(reflect.PointerTo(val.Type()), typ) {
+		return nil, false, nil
+	}
+
+	if !val.CanAddr() {
+		ptr := reflect.New(val.Type())
+		ptr.Elem().Set(val)
+		val = ptr.Elem()
+	}
+	v, err := cell.Unwrap(val.Addr().Interface(), typ)
+	if err != nil || v == nil {
+		return nil, true, err
+	}
+	value, err := cell.New(v, typ).WithExtension(ext).Build()
+	return value, true, err
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+type level int
+
+func (l level) String() string {
+	return [...]string{"debug", "info", "warn"}[l]
+}
+
+type userID struct {
+	id int64
+}
+
+func (u *userID) GreptimeValue() (any, types.ColumnType) {
+	return u.id, types.UINT32
+}
+
+// uuid is like github.com/google/uuid
+type uuid [4]byte
+
+func (u uuid) Value() (driver.Value, error) {
+	return string(u[:]), nil
+}
+
+func TestParseCustomType(t *testing.T) {
+	type Event struct {
+		User    userID             `greptime:"tag"`
+		UserPtr *userID            `greptime:"field"`
+		Trace   uuid               `greptime:"field"`
+		Name    sql.NullString     `greptime:"field"`
+		Count   sql.NullInt64      `greptime:"field"`
+		Ratio   sql.Null[float32]  `greptime:"field"`
+		Updated sql.NullTime       `greptime:"field"`
+		Level   level              `greptime:"field;type:string"`
+		Code    level              `greptime:"field"`
+		IP      net.IP             `greptime:"field"`
+		Addr    netip.Addr         `greptime:"field"`
+		AddrPtr *netip.Addr        `greptime:"field"`
+		Tags    map[string]float64 `greptime:"field"`
+		Ts      time.Time          `greptime:"timestamp"`
+	}
+
+	ts := time.UnixMilli(1700000000000)
+	addr := netip.MustParseAddr("::1")
+	events := []Event{
+		{
+			User:    userID{id: 1},
+			UserPtr: &userID{id: 2},
+			Trace:   uuid{'a', 'b', 'c', 'd'},
+			Name:    sql.NullString{String: "name", Valid: true},
+			Count:   sql.NullInt64{Int64: 3, Valid: true},
+			Ratio:   sql.Null[float32]{V: 0.5, Valid: true},
+			Updated: sql.NullTime{Time: ts, Valid: true},
+			Level:   2,
+			Code:    1,
+			IP:      net.ParseIP("127.0.0.1"),
+			Addr:    addr,
+			AddrPtr: &addr,
+			Ts:      ts,
+		},
+		{Ts: ts},
+	}
+
+	tbl, err := Parse(events)
+	assert.Nil(t, err)
+
+	datatypes := make([]gpb.ColumnDataType, 0)
+	for _, column := range tbl.GetColumnsSchema() {
+		datatypes = append(datatypes, column.Datatype)
+	}
+	assert.Equal(t, []gpb.ColumnDataType{
+		gpb.ColumnDataType_UINT32,
+		gpb.ColumnDataType_UINT32,
+		gpb.ColumnDataType_STRING,
+		gpb.ColumnDataType_STRING,
+		gpb.ColumnDataType_INT64,
+		gpb.ColumnDataType_FLOAT32,
+		gpb.ColumnDataType_TIMESTAMP_MILLISECOND,
+		gpb.ColumnDataType_STRING,
+		gpb.ColumnDataType_INT64,
+		gpb.ColumnDataType_STRING,
+		gpb.ColumnDataType_STRING,
+		gpb.ColumnDataType_STRING,
+		gpb.ColumnDataType_JSON,
+		gpb.ColumnDataType_TIMESTAMP_MILLISECOND,
+	}, datatypes)
+
+	values := tbl.GetRows().Rows[0].Values
+	assert.Equal(t, uint32(1), values[0].GetU32Value())
+	assert.Equal(t, uint32(2), values[1].GetU32Value())
+	assert.Equal(t, "abcd", values[2].GetStringValue())
+	assert.Equal(t, "name", values[3].GetStringValue())
+	assert.Equal(t, int64(3), values[4].GetI64Value())
+	assert.Equal(t, float32(0.5), values[5].GetF32Value())
+	assert.Equal(t, ts.UnixMilli(), values[6].GetTimestampMillisecondValue())
+	assert.Equal(t, "warn", values[7].GetStringValue())
+	assert.Equal(t, int64(1), values[8].GetI64Value())
+	assert.Equal(t, "127.0.0.1", values[9].GetStringValue())
+	assert.Equal(t, "::1", values[10].GetStringValue())
+	assert.Equal(t, "::1", values[11].GetStringValue())
+
+	// the invalid sql.Null and the nil pointers are null
+	values = tbl.GetRows().Rows[1].Values
+	assert.Equal(t, uint32(0), values[0].GetU32Value())
+	for _, i := range []int{1, 3, 4, 5, 6, 11} {
+		assert.Nil(t, values[i].GetValueData(), "column %d", i)
+	}
+}
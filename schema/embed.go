@@ -0,0 +1,142 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+)
+
+// ParseEmbedTag reports whether the columns of the struct field are flattened into the
+// parent struct by the tag like `greptime:"embed;prefix:res_"`, prefix is prepended to the
+// names of the columns. The anonymous struct field without the greptime tag is also
+// flattened, which is decided by the caller.
+func ParseEmbedTag(tag reflect.StructTag) (prefix string, embed bool) {
+	tags := parseTag(reflect.StructField{Tag: tag})
+	_, embed = tags["EMBED"]
+	prefix, ok := tags["PREFIX"]
+	return prefix, embed || ok
+}
+
+// flatField is the struct field of the column, which may be in the embedded structs.
+type flatField struct {
+	reflect.StructField // Index is the path from the root struct
+
+	field *Field
+	path  string // like Resource.Region
+}
+
+// parseFlatFields parses the columns of the struct, the embedded structs are flattened,
+// and the duplicate columns are reported.
+func parseFlatFields(typ reflect.Type) ([]*flatField, error) {
+	fields := make([]*flatField, 0, typ.NumField())
+	if err := flattenFields(typ, nil, "", "", map[reflect.Type]bool{}, &fields); err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]string, len(fields))
+	for _, field := range fields {
+		if path, ok := paths[field.field.Name]; ok {
+			return nil, fmt.Errorf("%w %q of field %s and %s", errs.ErrDuplicateColumn, field.field.Name, path, field.path)
+		}
+		paths[field.field.Name] = field.path
+	}
+	return fields, nil
+}
+
+func flattenFields(typ reflect.Type, index []int, prefix, path string, parents map[reflect.Type]bool, fields *[]*flatField) error {
+	if parents[typ] {
+		return fmt.Errorf("field %s: recursive embedded struct %s", strings.TrimSuffix(path, "."), typ)
+	}
+	parents[typ] = true
+	defer delete(parents, typ)
+
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+		structField.Index = append(append(make([]int, 0, len(index)+1), index...), i)
+		fieldPath := path + structField.Name
+
+		embedPrefix, embed, err := isEmbedded(structField)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", fieldPath, err)
+		}
+		if embed {
+			elem := structField.Type
+			if elem.Kind() == reflect.Pointer {
+				elem = elem.Elem()
+			}
+			if err := flattenFields(elem, structField.Index, prefix+embedPrefix, fieldPath+".", parents, fields); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !structField.IsExported() {
+			continue
+		}
+		field, err := parseField(structField)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", fieldPath, err)
+		}
+		if field == nil {
+			continue
+		}
+		field.Name = prefix + field.Name
+		*fields = append(*fields, &flatField{StructField: structField, field: field, path: fieldPath})
+	}
+	return nil
+}
+
+// isEmbedded reports whether the columns of the struct field are flattened, which is
+// tagged by embed or prefix, or the anonymous struct without the greptime tag.
+func isEmbedded(structField reflect.StructField) (string, bool, error) {
+	typ := structField.Type
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	prefix, embed := ParseEmbedTag(structField.Tag)
+	if embed {
+		if !structField.IsExported() && !structField.Anonymous {
+			return "", false, fmt.Errorf("unexported field can not be embedded")
+		}
+		if typ.Kind() != reflect.Struct {
+			return "", false, fmt.Errorf("embedded type %s is not a struct", structField.Type)
+		}
+		return prefix, true, nil
+	}
+
+	if _, ok := structField.Tag.Lookup(GreptimeFieldTagKey); ok || !structField.Anonymous {
+		return "", false, nil
+	}
+	return "", isPlainStruct(typ), nil
+}
+
+// isPlainStruct reports whether typ is a struct whose value is JSON, unlike time.Time,
+// the decimal and the custom types.
+func isPlainStruct(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+	datatype, err := parseType(typ)
+	return err == nil && datatype == gpb.ColumnDataType_JSON
+}
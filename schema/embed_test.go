@@ -0,0 +1,140 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"testing"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+)
+
+type labels struct {
+	Region string `greptime:"tag"`
+	Zone   string `greptime:"tag"`
+}
+
+type Resource struct {
+	Host    string  `greptime:"tag"`
+	Version *string `greptime:"field;column:ver"`
+}
+
+type embedMetric struct {
+	labels
+	Resource *Resource `greptime:"embed;prefix:res_"`
+	Local    Resource  `greptime:"embed"`
+	Extra    Resource  `greptime:"field"`
+	Cpu      float64   `greptime:"field"`
+	Ts       time.Time `greptime:"timestamp"`
+}
+
+func TestParseEmbeddedStruct(t *testing.T) {
+	version := "v1"
+	ts := time.UnixMilli(1700000000000)
+	metrics := []embedMetric{
+		{
+			labels:   labels{Region: "us", Zone: "a"},
+			Resource: &Resource{Host: "h1", Version: &version},
+			Local:    Resource{Host: "local"},
+			Cpu:      0.5,
+			Ts:       ts,
+		},
+		{labels: labels{Region: "eu"}, Cpu: 1, Ts: ts},
+	}
+
+	tbl, err := Parse(metrics)
+	assert.Nil(t, err)
+
+	names := make([]string, 0)
+	for _, column := range tbl.GetColumnsSchema() {
+		names = append(names, column.ColumnName)
+	}
+	assert.Equal(t, []string{"region", "zone", "res_host", "res_ver", "host", "ver", "extra", "cpu", "ts"}, names)
+	assert.Equal(t, gpb.SemanticType_TAG, tbl.GetColumnsSchema()[2].SemanticType)
+	assert.Equal(t, gpb.ColumnDataType_JSON, tbl.GetColumnsSchema()[6].Datatype)
+
+	values := tbl.GetRows().Rows[0].Values
+	assert.Equal(t, "us", values[0].GetStringValue())
+	assert.Equal(t, "a", values[1].GetStringValue())
+	assert.Equal(t, "h1", values[2].GetStringValue())
+	assert.Equal(t, "v1", values[3].GetStringValue())
+	assert.Equal(t, "local", values[4].GetStringValue())
+	assert.Nil(t, values[5])
+
+	// the columns of the nil pointer are null
+	values = tbl.GetRows().Rows[1].Values
+	assert.Equal(t, "eu", values[0].GetStringValue())
+	assert.Nil(t, values[2])
+	assert.Nil(t, values[3])
+	assert.Equal(t, 1.0, values[7].GetF64Value())
+}
+
+func TestParseEmbeddedStructInvalid(t *testing.T) {
+	type duplicate struct {
+		labels
+		Region string    `greptime:"tag"`
+		Ts     time.Time `greptime:"timestamp"`
+	}
+	_, err := Parse(duplicate{})
+	assert.ErrorIs(t, err, errs.ErrDuplicateColumn)
+	assert.ErrorContains(t, err, `"region" of field labels.Region and Region`)
+
+	type prefixed struct {
+		First  Resource `greptime:"embed;prefix:first_"`
+		Second Resource `greptime:"embed;prefix:first_"`
+	}
+	_, err = Parse(prefixed{})
+	assert.ErrorIs(t, err, errs.ErrDuplicateColumn)
+
+	type notStruct struct {
+		Host string `greptime:"embed"`
+	}
+	_, err = Parse(notStruct{})
+	assert.ErrorContains(t, err, "field Host: embedded type string is not a struct")
+
+	_, err = Parse(recursive{})
+	assert.ErrorContains(t, err, "recursive embedded struct")
+
+	type invalidNested struct {
+		Resource struct {
+			Host chan int `greptime:"tag"`
+		} `greptime:"embed"`
+	}
+	_, err = Parse(invalidNested{})
+	assert.ErrorContains(t, err, "field Resource.Host")
+}
+
+type recursive struct {
+	Name string     `greptime:"tag"`
+	Next *recursive `greptime:"embed;prefix:next_"`
+}
+
+func TestScanEmbeddedStruct(t *testing.T) {
+	columns := []string{"region", "res_host", "res_ver", "host", "cpu"}
+	rows := [][]any{{"us", "h1", "v1", "local", 0.5}}
+
+	var metric embedMetric
+	assert.Nil(t, ScanRows(columns, rows, &metric))
+	assert.Equal(t, "us", metric.Region)
+	assert.Equal(t, "h1", metric.Resource.Host)
+	assert.Equal(t, "v1", *metric.Resource.Version)
+	assert.Equal(t, "local", metric.Local.Host)
+	assert.Equal(t, 0.5, metric.Cpu)
+}
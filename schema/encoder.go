@@ -0,0 +1,64 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"reflect"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+)
+
+// Encoder builds the columns and the rows of the struct without reflection. It is usually
+// generated by cmd/greptime-gen from the greptime tags, and Parse prefers it over the tags
+// if the pointer of the struct implements it.
+type Encoder interface {
+	// GreptimeColumns returns the columns, which MUST be newly allocated for every call,
+	// and do not depend on the receiver, which may be nil.
+	GreptimeColumns() []*gpb.ColumnSchema
+
+	// GreptimeRow returns the values of the receiver in the order of the columns.
+	GreptimeRow() (*gpb.Row, error)
+}
+
+var encoderType = reflect.TypeOf((*Encoder)(nil)).Elem()
+
+// compileEncoderCodec uses the columns of the Encoder instead of the struct tags.
+func compileEncoderCodec(tableName string, typ reflect.Type) *codec {
+	columns := reflect.New(typ).Interface().(Encoder).GreptimeColumns()
+
+	c := &codec{tableName: tableName, fields: make([]*Field, len(columns)), encoder: true}
+	for i, column := range columns {
+		c.fields[i] = &Field{
+			Name:              column.ColumnName,
+			SemanticType:      column.SemanticType,
+			Datatype:          column.Datatype,
+			DatatypeExtension: column.DatatypeExtension,
+		}
+	}
+	return c
+}
+
+// encodeEncoderRow calls the Encoder of the pointer of val, val is copied if it is not
+// addressable.
+func encodeEncoderRow(val reflect.Value) (*gpb.Row, error) {
+	if !val.CanAddr() {
+		ptr := reflect.New(val.Type())
+		ptr.Elem().Set(val)
+		val = ptr.Elem()
+	}
+	return val.Addr().Interface().(Encoder).GreptimeRow()
+}
@@ -17,8 +17,11 @@
 package schema
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,16 +33,18 @@ import (
 )
 
 type Field struct {
-	Name         string             // default is field name
-	SemanticType gpb.SemanticType   // default is field
-	Datatype     gpb.ColumnDataType // default is the value type
+	Name              string                       // default is field name
+	SemanticType      gpb.SemanticType             // default is field
+	Datatype          gpb.ColumnDataType           // default is the value type
+	DatatypeExtension *gpb.ColumnDataTypeExtension // like the precision and scale of decimal
 }
 
 func (f Field) ToColumnSchema() *gpb.ColumnSchema {
 	return &gpb.ColumnSchema{
-		ColumnName:   f.Name,
-		SemanticType: f.SemanticType,
-		Datatype:     f.Datatype,
+		ColumnName:        f.Name,
+		SemanticType:      f.SemanticType,
+		Datatype:          f.Datatype,
+		DatatypeExtension: f.DatatypeExtension,
 	}
 }
 
@@ -52,13 +57,23 @@ func newColumnSchema(columnName string, semanticType gpb.SemanticType, datatype
 }
 
 func parseField(structField reflect.StructField) (*Field, error) {
-	tags := parseTag(structField)
+	return ParseFieldTag(structField.Name, structField.Tag, func() (gpb.ColumnDataType, error) {
+		return parseType(structField.Type)
+	})
+}
+
+// ParseFieldTag parses the column of the struct field from its name and greptime tag.
+// infer is called for the datatype only if the type is not set in the tag, and nil is
+// returned for the ignored field. It is for the tools which do not have the reflect.Type,
+// like the code generator.
+func ParseFieldTag(name string, tag reflect.StructTag, infer func() (gpb.ColumnDataType, error)) (*Field, error) {
+	tags := parseTag(reflect.StructField{Name: name, Tag: tag})
 
 	if _, ok := tags[IgnoreFiledTag]; ok && len(tags) == 1 {
 		return nil, nil
 	}
 
-	columnName, err := util.SanitateName(structField.Name)
+	columnName, err := util.SanitateName(name)
 	if err != nil {
 		return nil, err
 	}
@@ -73,19 +88,49 @@ func parseField(structField reflect.StructField) (*Field, error) {
 		semanticType = gpb.SemanticType_TIMESTAMP
 	}
 
-	typ, err := parseType(structField.Type)
+	// the type in tag takes precedence, so the value type is not required to be known
+	var typ gpb.ColumnDataType
+	if val, ok := tags["TYPE"]; ok {
+		typ, err = types.ParseColumnType(val, tags["PRECISION"])
+	} else {
+		typ, err = infer()
+	}
 	if err != nil {
 		return nil, err
 	}
-	if val, ok := tags["TYPE"]; ok {
-		typ_, err := types.ParseColumnType(val, tags["PRECISION"])
+
+	field := newField(columnName, semanticType, typ)
+	if strings.EqualFold(tags["TYPE"], types.JSONB.String()) {
+		field.DatatypeExtension = types.JSONBinaryExtension()
+	} else if typ == gpb.ColumnDataType_DECIMAL128 {
+		ext, err := parseDecimalExtension(tags["PRECISION"], tags["SCALE"])
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("field %q: %w", name, err)
 		}
-		typ = typ_
+		field.DatatypeExtension = ext
 	}
+	return field, nil
+}
 
-	return newField(columnName, semanticType, typ), nil
+// parseDecimalExtension parses the precision and scale from tag like
+// `greptime:"type:decimal128;precision:38;scale:4"`.
+func parseDecimalExtension(precision, scale string) (*gpb.ColumnDataTypeExtension, error) {
+	p, s := types.DefaultDecimalPrecision, types.DefaultDecimalScale
+	if precision != "" {
+		v, err := strconv.ParseInt(strings.TrimSpace(precision), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid decimal precision %q", precision)
+		}
+		p = int32(v)
+	}
+	if scale != "" {
+		v, err := strconv.ParseInt(strings.TrimSpace(scale), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid decimal scale %q", scale)
+		}
+		s = int32(v)
+	}
+	return types.DecimalExtension(p, s)
 }
 
 func parseTag(structField reflect.StructField) map[string]string {
@@ -112,6 +157,10 @@ func parseTag(structField reflect.StructField) map[string]string {
 }
 
 func parseType(typ reflect.Type) (gpb.ColumnDataType, error) {
+	if datatype, ok, err := parseCustomType(typ); ok {
+		return datatype, err
+	}
+
 	switch kind := typ.Kind(); kind {
 	case reflect.Bool:
 		return gpb.ColumnDataType_BOOLEAN, nil
@@ -139,13 +188,16 @@ func parseType(typ reflect.Type) (gpb.ColumnDataType, error) {
 		return gpb.ColumnDataType_FLOAT32, nil
 	case reflect.Float64:
 		return gpb.ColumnDataType_FLOAT64, nil
-	case reflect.Array, reflect.Slice: // only binary is supported
-		elem := typ.Elem()
-		if elem.Kind() == reflect.Uint8 {
+	case reflect.Array, reflect.Slice: // bytes are binary, the others are json
+		if typ == rawMessageType {
+			return gpb.ColumnDataType_JSON, nil
+		} else if typ.Elem().Kind() == reflect.Uint8 {
 			return gpb.ColumnDataType_BINARY, nil
 		} else {
-			return -1, fmt.Errorf("unsupported type %q", kind.String())
+			return gpb.ColumnDataType_JSON, nil
 		}
+	case reflect.Map:
+		return gpb.ColumnDataType_JSON, nil
 	case reflect.Pointer:
 		return parseType(typ.Elem())
 	case reflect.String:
@@ -153,8 +205,12 @@ func parseType(typ reflect.Type) (gpb.ColumnDataType, error) {
 	case reflect.Struct:
 		if isTimeType(typ) {
 			return gpb.ColumnDataType_TIMESTAMP_MILLISECOND, nil
+		} else if isDecimalType(typ) {
+			return gpb.ColumnDataType_DECIMAL128, nil
+		} else if typ == intervalType {
+			return gpb.ColumnDataType_INTERVAL_MONTH_DAY_NANO, nil
 		} else {
-			return -1, fmt.Errorf("unsupported type %q", kind.String())
+			return gpb.ColumnDataType_JSON, nil
 		}
 	default:
 		return -1, fmt.Errorf("unsupported type %q", kind.String())
@@ -184,12 +240,66 @@ func parseIntOrTimeValue(typ gpb.ColumnDataType, val reflect.Value) (*gpb.Value,
 	return nil, fmt.Errorf("unsupported type %T of %#v", val, val)
 }
 
-func parseValue(typ gpb.ColumnDataType, val reflect.Value) (*gpb.Value, error) {
+// parseTimeOfDayValue assumes val is a time.Duration, a time.Time type or a integer type.
+// The time of day of time.Time is kept in its own location.
+func parseTimeOfDayValue(typ gpb.ColumnDataType, val reflect.Value) (*gpb.Value, error) {
+	if val.Type() == durationType {
+		return cell.New(val.Interface(), typ).Build()
+	}
+
+	if isTimeType(val.Type()) {
+		return cell.New(val.Interface(), typ).Build()
+	}
+
+	return parseIntOrTimeValue(typ, val)
+}
+
+// parseIntervalValue assumes val is a types.Interval, a time.Duration or a integer type
+func parseIntervalValue(typ gpb.ColumnDataType, val reflect.Value) (*gpb.Value, error) {
+	if val.Type() == intervalType || val.Type() == durationType {
+		return cell.New(val.Interface(), typ).Build()
+	}
+
+	if val.CanInt() {
+		return cell.New(val.Int(), typ).Build()
+	}
+
+	if val.CanUint() {
+		return cell.New(val.Uint(), typ).Build()
+	}
+
+	return nil, fmt.Errorf("%#v is not compatible with Interval", val)
+}
+
+// parseJSONValue serializes val into JSON, except the string which is already JSON.
+// The nil map, slice and json.RawMessage are null.
+func parseJSONValue(typ gpb.ColumnDataType, ext *gpb.ColumnDataTypeExtension, val reflect.Value) (*gpb.Value, error) {
+	switch val.Kind() {
+	case reflect.String:
+		return cell.New(val.String(), typ).WithExtension(ext).Build()
+	case reflect.Map, reflect.Slice, reflect.Interface:
+		if val.IsNil() {
+			return nil, nil
+		}
+	}
+
+	return cell.New(val.Interface(), typ).WithExtension(ext).Build()
+}
+
+func parseValue(typ gpb.ColumnDataType, ext *gpb.ColumnDataTypeExtension, val reflect.Value) (*gpb.Value, error) {
 	val = reflect.Indirect(val)
 	if !val.IsValid() {
 		return nil, nil
 	}
 
+	if value, ok, err := parseCustomValue(typ, ext, val); ok {
+		return value, err
+	}
+
+	if types.IsJSONBinary(ext) {
+		return parseJSONValue(typ, ext, val)
+	}
+
 	switch typ {
 	case gpb.ColumnDataType_INT8, gpb.ColumnDataType_INT16, gpb.ColumnDataType_INT32, gpb.ColumnDataType_INT64:
 		if !val.CanInt() {
@@ -243,28 +353,28 @@ func parseValue(typ gpb.ColumnDataType, val reflect.Value) (*gpb.Value, error) {
 		return parseIntOrTimeValue(typ, val)
 
 	case gpb.ColumnDataType_TIME_SECOND:
-		return parseIntOrTimeValue(typ, val)
+		return parseTimeOfDayValue(typ, val)
 	case gpb.ColumnDataType_TIME_MILLISECOND:
-		return parseIntOrTimeValue(typ, val)
+		return parseTimeOfDayValue(typ, val)
 	case gpb.ColumnDataType_TIME_MICROSECOND:
-		return parseIntOrTimeValue(typ, val)
+		return parseTimeOfDayValue(typ, val)
 	case gpb.ColumnDataType_TIME_NANOSECOND:
-		return parseIntOrTimeValue(typ, val)
+		return parseTimeOfDayValue(typ, val)
 
 	case gpb.ColumnDataType_INTERVAL_YEAR_MONTH,
 		gpb.ColumnDataType_INTERVAL_DAY_TIME,
 		gpb.ColumnDataType_INTERVAL_MONTH_DAY_NANO:
-		return nil, fmt.Errorf("INTERVAL not implemented yet for %#v", val)
+		return parseIntervalValue(typ, val)
 
-	// TODO(yuanbohan): support decimal 128
 	case gpb.ColumnDataType_DECIMAL128:
-		return nil, fmt.Errorf("DECIMAL 128 not supported for %#v", val)
+		// the methods of the decimal types may have pointer receivers
+		if val.CanAddr() {
+			val = val.Addr()
+		}
+		return cell.New(val.Interface(), typ).WithExtension(ext).Build()
 
 	case gpb.ColumnDataType_JSON:
-		if val.Kind() != reflect.String {
-			return nil, fmt.Errorf("%#v is not compatible with String", val)
-		}
-		return cell.New(val.String(), typ).Build()
+		return parseJSONValue(typ, ext, val)
 
 	default:
 		return nil, fmt.Errorf("unknown column data type: %v", typ)
@@ -274,3 +384,23 @@ func parseValue(typ gpb.ColumnDataType, val reflect.Value) (*gpb.Value, error) {
 func isTimeType(typ reflect.Type) bool {
 	return typ.PkgPath() == "time" && typ.Name() == "Time"
 }
+
+var (
+	intervalType   = reflect.TypeOf(types.Interval{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+var decimalCoefficientType = reflect.TypeOf((*interface {
+	Coefficient() *big.Int
+	Exponent() int32
+})(nil)).Elem()
+
+// isDecimalType reports whether the struct is big.Int, big.Rat or the decimal type with
+// Coefficient() and Exponent() methods, like github.com/shopspring/decimal.
+func isDecimalType(typ reflect.Type) bool {
+	if typ.PkgPath() == "math/big" && (typ.Name() == "Int" || typ.Name() == "Rat") {
+		return true
+	}
+	return typ.Implements(decimalCoefficientType) || reflect.PointerTo(typ).Implements(decimalCoefficientType)
+}
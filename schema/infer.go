@@ -0,0 +1,391 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+const defaultTimestampKey = "ts"
+
+// InferConfig is the rules to infer the columns from the dynamic data, like maps and
+// JSON objects.
+//   - TagKeys are the keys of the tag columns, and the others are field columns.
+//   - TimestampKey is the key of the timestamp column, which every row MUST have.
+//     No timestamp column is inferred if it is empty.
+//   - TimestampType is the type of the timestamp column. The numbers of the timestamp
+//     are in its precision, and the strings are parsed in RFC3339.
+//   - WidenNumbers infers the integers as INT64 or UINT64 and the floats as FLOAT64, and
+//     the column of both integers and floats is FLOAT64. Otherwise the numbers keep their
+//     widths, and the column of different types is rejected.
+type InferConfig struct {
+	TagKeys       []string
+	TimestampKey  string
+	TimestampType types.ColumnType
+	WidenNumbers  bool
+}
+
+// NewInferConfig helps to init InferConfig with default values.
+func NewInferConfig() *InferConfig {
+	return &InferConfig{
+		TimestampKey:  defaultTimestampKey,
+		TimestampType: types.TIMESTAMP_MILLISECOND,
+		WidenNumbers:  true,
+	}
+}
+
+// WithTagKeys set the TagKeys field.
+func (c *InferConfig) WithTagKeys(keys ...string) *InferConfig {
+	c.TagKeys = keys
+	return c
+}
+
+// WithTimestamp set the TimestampKey and TimestampType fields.
+func (c *InferConfig) WithTimestamp(key string, type_ types.ColumnType) *InferConfig {
+	c.TimestampKey = key
+	c.TimestampType = type_
+	return c
+}
+
+// WithWidenNumbers set the WidenNumbers field.
+func (c *InferConfig) WithWidenNumbers(widen bool) *InferConfig {
+	c.WidenNumbers = widen
+	return c
+}
+
+// Inferrer infers the columns from the rows of maps or JSON objects, and merges the
+// columns of all the rows. The keys never seen in the former rows are appended as new
+// columns, and the values missing in a row are null.
+//
+//	inferrer := schema.NewInferrer("monitor", schema.NewInferConfig().WithTagKeys("host"))
+//	err := inferrer.AddMap(map[string]any{"host": "127.0.0.1", "cpu": 0.5, "ts": time.Now()})
+//	err := inferrer.AddJSON([]byte(`{"host": "127.0.0.2", "cpu": 1, "ts": 1700000000000}`))
+//
+//	tbl, err := inferrer.Table()
+type Inferrer struct {
+	tableName string
+	config    *InferConfig
+	tags      map[string]bool
+
+	columns []*inferredColumn
+	indexes map[string]int
+	rows    [][]any
+}
+
+type inferredColumn struct {
+	name         string
+	semanticType gpb.SemanticType
+	type_        types.ColumnType
+}
+
+// NewInferrer creates the Inferrer of the table. The default config is used if config is nil.
+func NewInferrer(tableName string, config *InferConfig) *Inferrer {
+	if config == nil {
+		config = NewInferConfig()
+	}
+
+	tags := make(map[string]bool, len(config.TagKeys))
+	for _, key := range config.TagKeys {
+		tags[key] = true
+	}
+
+	return &Inferrer{
+		tableName: tableName,
+		config:    config,
+		tags:      tags,
+		indexes:   make(map[string]int),
+	}
+}
+
+// RowCount returns the number of rows added so far.
+func (i *Inferrer) RowCount() int {
+	return len(i.rows)
+}
+
+// AddMap adds the row. The nested maps and slices are JSON values. The inferrer is not
+// changed if it fails.
+func (i *Inferrer) AddMap(row map[string]any) error {
+	keys := make([]string, 0, len(row))
+	for key := range row {
+		keys = append(keys, key)
+	}
+	// the new columns are in the order of keys
+	sort.Strings(keys)
+
+	types_ := make(map[int]types.ColumnType)
+	var added []*inferredColumn
+	values := make([]any, len(i.columns))
+	hasTimestamp := false
+
+	for _, key := range keys {
+		if isNil(row[key]) {
+			continue
+		}
+
+		var (
+			val          any
+			type_        types.ColumnType
+			semanticType gpb.SemanticType
+			err          error
+		)
+		if key == i.config.TimestampKey {
+			val, err = timestampValue(row[key])
+			type_, semanticType = i.config.TimestampType, gpb.SemanticType_TIMESTAMP
+			hasTimestamp = true
+		} else {
+			val, type_, err = i.inferValue(row[key])
+			semanticType = gpb.SemanticType_FIELD
+			if i.tags[key] {
+				semanticType = gpb.SemanticType_TAG
+				if err == nil && type_ == types.JSON {
+					err = errors.New("tag can not be JSON")
+				}
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to infer %q: %w", key, err)
+		}
+
+		idx, ok := i.indexes[key]
+		if ok {
+			old, ok := types_[idx]
+			if !ok {
+				old = i.columns[idx].type_
+			}
+			if type_, err = i.mergeType(old, type_); err != nil {
+				return fmt.Errorf("failed to infer %q: %w", key, err)
+			}
+			types_[idx] = type_
+		} else {
+			idx = len(values)
+			added = append(added, &inferredColumn{name: key, semanticType: semanticType, type_: type_})
+			values = append(values, nil)
+		}
+		values[idx] = val
+	}
+
+	if i.config.TimestampKey != "" && !hasTimestamp {
+		return fmt.Errorf("timestamp %q not found", i.config.TimestampKey)
+	}
+
+	for idx, type_ := range types_ {
+		i.columns[idx].type_ = type_
+	}
+	for _, column := range added {
+		i.indexes[column.name] = len(i.columns)
+		i.columns = append(i.columns, column)
+	}
+	i.rows = append(i.rows, values)
+	return nil
+}
+
+// AddJSON adds the row of the JSON object.
+func (i *Inferrer) AddJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var row map[string]any
+	if err := decoder.Decode(&row); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return errors.New("invalid JSON: unexpected data after the object")
+	}
+	return i.AddMap(row)
+}
+
+// ReadJSON adds the rows of the JSON objects in r, like JSON lines, until EOF. The rows
+// before the invalid one are kept.
+func (i *Inferrer) ReadJSON(r io.Reader) error {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	for n := 0; ; n++ {
+		var row map[string]any
+		if err := decoder.Decode(&row); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("object %d: %w", n, err)
+		}
+		if err := i.AddMap(row); err != nil {
+			return fmt.Errorf("object %d: %w", n, err)
+		}
+	}
+}
+
+// Table converts the columns and rows inferred into the table.
+func (i *Inferrer) Table() (*table.Table, error) {
+	tbl, err := table.New(i.tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, column := range i.columns {
+		switch column.semanticType {
+		case gpb.SemanticType_TAG:
+			err = tbl.AddTagColumn(column.name, column.type_)
+		case gpb.SemanticType_TIMESTAMP:
+			err = tbl.AddTimestampColumn(column.name, column.type_)
+		default:
+			err = tbl.AddFieldColumn(column.name, column.type_)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	inputs := make([]any, len(i.columns))
+	for _, row := range i.rows {
+		for idx, column := range i.columns {
+			inputs[idx] = nil
+			if idx < len(row) {
+				if inputs[idx], err = widenValue(row[idx], column.type_); err != nil {
+					return nil, fmt.Errorf("column %q: %w", column.name, err)
+				}
+			}
+		}
+		if err := tbl.AddRow(inputs...); err != nil {
+			return nil, err
+		}
+	}
+	return tbl, nil
+}
+
+func (i *Inferrer) inferValue(v any) (any, types.ColumnType, error) {
+	if number, ok := v.(json.Number); ok {
+		return numberValue(number)
+	}
+
+	type_, err := types.InferColumnType(v)
+	if err != nil || !i.config.WidenNumbers {
+		return v, type_, err
+	}
+
+	val := reflect.Indirect(reflect.ValueOf(v))
+	switch type_ {
+	case types.INT8, types.INT16, types.INT32, types.INT64:
+		return val.Int(), types.INT64, nil
+	case types.UINT8, types.UINT16, types.UINT32, types.UINT64:
+		return val.Uint(), types.UINT64, nil
+	case types.FLOAT32, types.FLOAT64:
+		return val.Float(), types.FLOAT64, nil
+	default:
+		return v, type_, nil
+	}
+}
+
+// mergeType merges the types of the same column in different rows.
+func (i *Inferrer) mergeType(old, new types.ColumnType) (types.ColumnType, error) {
+	if old == new {
+		return old, nil
+	}
+
+	if i.config.WidenNumbers && isWidenedNumber(old) && isWidenedNumber(new) {
+		if old == types.FLOAT64 || new == types.FLOAT64 {
+			return types.FLOAT64, nil
+		}
+		return types.INT64, nil
+	}
+	return old, fmt.Errorf("conflicting types %v and %v", old, new)
+}
+
+func isWidenedNumber(type_ types.ColumnType) bool {
+	return type_ == types.INT64 || type_ == types.UINT64 || type_ == types.FLOAT64
+}
+
+// widenValue converts the number into the merged type of the column.
+func widenValue(v any, type_ types.ColumnType) (any, error) {
+	switch t := v.(type) {
+	case int64:
+		if type_ == types.FLOAT64 {
+			return float64(t), nil
+		}
+	case uint64:
+		switch type_ {
+		case types.FLOAT64:
+			return float64(t), nil
+		case types.INT64:
+			if t > math.MaxInt64 {
+				return nil, fmt.Errorf("%d overflows INT64", t)
+			}
+			return int64(t), nil
+		}
+	}
+	return v, nil
+}
+
+func numberValue(number json.Number) (any, types.ColumnType, error) {
+	if i, err := number.Int64(); err == nil {
+		return i, types.INT64, nil
+	}
+	if u, err := strconv.ParseUint(number.String(), 10, 64); err == nil {
+		return u, types.UINT64, nil
+	}
+	f, err := number.Float64()
+	return f, types.FLOAT64, err
+}
+
+// timestampValue converts the timestamp into time.Time or the integer in the precision
+// of the column.
+func timestampValue(v any) (any, error) {
+	switch t := v.(type) {
+	case time.Time, *time.Time:
+		return t, nil
+	case string:
+		return time.Parse(time.RFC3339Nano, t)
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i, nil
+		}
+		f, err := t.Float64()
+		return int64(f), err
+	}
+
+	val := reflect.Indirect(reflect.ValueOf(v))
+	switch {
+	case val.CanInt():
+		return val.Int(), nil
+	case val.CanUint():
+		return int64(val.Uint()), nil
+	case val.CanFloat():
+		return int64(val.Float()), nil
+	default:
+		return nil, fmt.Errorf("unsupported timestamp %T", v)
+	}
+}
+
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	val := reflect.ValueOf(v)
+	return val.Kind() == reflect.Ptr && val.IsNil()
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+func TestInferrer(t *testing.T) {
+	ts := time.UnixMilli(1700000000000)
+	inferrer := NewInferrer("monitor", NewInferConfig().WithTagKeys("host"))
+
+	assert.Nil(t, inferrer.AddMap(map[string]any{"host": "127.0.0.1", "cpu": int32(1), "ts": ts}))
+	assert.Nil(t, inferrer.AddJSON([]byte(`{"host": "127.0.0.2", "cpu": 0.5, "memory": 1024, "ts": 1700000001000}`)))
+	assert.Nil(t, inferrer.ReadJSON(strings.NewReader(`
+{"host": "127.0.0.3", "labels": {"region": "us"}, "running": true, "ts": "2023-11-14T22:13:22Z"}
+{"host": "127.0.0.4", "memory": null, "ts": 1700000003000}
+`)))
+	assert.Equal(t, 4, inferrer.RowCount())
+
+	tbl, err := inferrer.Table()
+	assert.Nil(t, err)
+
+	expected := []*gpb.ColumnSchema{
+		{ColumnName: "cpu", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_FLOAT64},
+		{ColumnName: "host", SemanticType: gpb.SemanticType_TAG, Datatype: gpb.ColumnDataType_STRING},
+		{ColumnName: "ts", SemanticType: gpb.SemanticType_TIMESTAMP, Datatype: gpb.ColumnDataType_TIMESTAMP_MILLISECOND},
+		{ColumnName: "memory", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_INT64},
+		{ColumnName: "labels", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_JSON},
+		{ColumnName: "running", SemanticType: gpb.SemanticType_FIELD, Datatype: gpb.ColumnDataType_BOOLEAN},
+	}
+	assert.Equal(t, expected, tbl.GetColumnsSchema())
+
+	rows := tbl.GetRows().Rows
+	assert.Len(t, rows, 4)
+	assert.Equal(t, 1.0, rows[0].Values[0].GetF64Value())
+	assert.Nil(t, rows[0].Values[3].GetValueData())
+	assert.Equal(t, 0.5, rows[1].Values[0].GetF64Value())
+	assert.Equal(t, int64(1024), rows[1].Values[3].GetI64Value())
+	assert.Equal(t, int64(1700000001000), rows[1].Values[2].GetTimestampMillisecondValue())
+	assert.Equal(t, `{"region":"us"}`, rows[2].Values[4].GetStringValue())
+	assert.Equal(t, int64(1700000002000), rows[2].Values[2].GetTimestampMillisecondValue())
+	assert.Len(t, rows[3].Values, 6)
+	assert.Nil(t, rows[3].Values[3].GetValueData())
+}
+
+func TestInferrerConfig(t *testing.T) {
+	config := NewInferConfig().
+		WithTimestamp("time", types.TIMESTAMP_SECOND).
+		WithWidenNumbers(false)
+	inferrer := NewInferrer("monitor", config)
+
+	assert.Nil(t, inferrer.AddMap(map[string]any{"cpu": float32(0.5), "disk": uint16(1), "time": 1700000000}))
+	assert.NotNil(t, inferrer.AddMap(map[string]any{"cpu": 0.5, "time": 1700000001}))
+
+	tbl, err := inferrer.Table()
+	assert.Nil(t, err)
+	columns := tbl.GetColumnsSchema()
+	assert.Len(t, columns, 3)
+	assert.Equal(t, gpb.ColumnDataType_FLOAT32, columns[0].Datatype)
+	assert.Equal(t, gpb.ColumnDataType_UINT16, columns[1].Datatype)
+	assert.Equal(t, gpb.ColumnDataType_TIMESTAMP_SECOND, columns[2].Datatype)
+	assert.Equal(t, int64(1700000000), tbl.GetRows().Rows[0].Values[2].GetTimestampSecondValue())
+}
+
+func TestInferrerInvalid(t *testing.T) {
+	inferrer := NewInferrer("monitor", NewInferConfig().WithTagKeys("labels"))
+
+	// missing timestamp
+	assert.NotNil(t, inferrer.AddMap(map[string]any{"cpu": 0.5}))
+	// tag can not be JSON
+	assert.NotNil(t, inferrer.AddJSON([]byte(`{"labels": {"region": "us"}, "ts": 1}`)))
+	// invalid timestamp
+	assert.NotNil(t, inferrer.AddJSON([]byte(`{"ts": "yesterday"}`)))
+	// unsupported value
+	assert.NotNil(t, inferrer.AddMap(map[string]any{"ch": make(chan int), "ts": 1}))
+	// trailing data
+	assert.NotNil(t, inferrer.AddJSON([]byte(`{"ts": 1} {"ts": 2}`)))
+
+	assert.Nil(t, inferrer.AddJSON([]byte(`{"cpu": 1, "ts": 1}`)))
+	assert.NotNil(t, inferrer.AddJSON([]byte(`{"cpu": "high", "ts": 2}`)))
+
+	// the rows before the invalid one are kept
+	err := inferrer.ReadJSON(strings.NewReader(`{"cpu": 2, "ts": 3}` + "\n" + `{"cpu": true, "ts": 4}`))
+	assert.ErrorContains(t, err, "object 1")
+	assert.Equal(t, 2, inferrer.RowCount())
+
+	tbl, err := inferrer.Table()
+	assert.Nil(t, err)
+	assert.Len(t, tbl.GetColumnsSchema(), 2)
+}
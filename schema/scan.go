@@ -0,0 +1,233 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// ScanRows assigns the rows into dest by the column names, which are resolved from
+// the struct tags the same way as Parse. dest MUST be a pointer to a struct, or a
+// pointer to a slice of struct or pointer of struct. If dest is a pointer to a
+// struct, only the first row is assigned.
+//
+// Columns which have no matched fields are ignored.
+func ScanRows(columns []string, rows [][]any, dest any) error {
+	val := reflect.ValueOf(dest)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return fmt.Errorf("dest must be a non-nil pointer, got %T", dest)
+	}
+	val = val.Elem()
+
+	switch val.Kind() {
+	case reflect.Struct:
+		if len(rows) == 0 {
+			return nil
+		}
+		indexes, err := scanIndexes(val.Type(), columns)
+		if err != nil {
+			return err
+		}
+		return scanRow(val, indexes, rows[0])
+
+	case reflect.Slice:
+		elemType := val.Type().Elem()
+		isPtr := elemType.Kind() == reflect.Ptr
+		if isPtr {
+			elemType = elemType.Elem()
+		}
+		if elemType.Kind() != reflect.Struct {
+			return fmt.Errorf("unsupported dest type %T", dest)
+		}
+
+		indexes, err := scanIndexes(elemType, columns)
+		if err != nil {
+			return err
+		}
+
+		slice := reflect.MakeSlice(val.Type(), 0, len(rows))
+		for _, row := range rows {
+			elem := reflect.New(elemType)
+			if err := scanRow(elem.Elem(), indexes, row); err != nil {
+				return err
+			}
+			if isPtr {
+				slice = reflect.Append(slice, elem)
+			} else {
+				slice = reflect.Append(slice, elem.Elem())
+			}
+		}
+		val.Set(slice)
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported dest type %T", dest)
+	}
+}
+
+// scanIndexes returns the field index for each column, nil if no field matches.
+func scanIndexes(typ reflect.Type, columns []string) ([][]int, error) {
+	flatFields, err := parseFlatFields(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string][]int, len(flatFields))
+	for _, field := range flatFields {
+		fields[field.field.Name] = field.Index
+	}
+
+	indexes := make([][]int, len(columns))
+	for i, column := range columns {
+		indexes[i] = fields[column]
+	}
+	return indexes, nil
+}
+
+func scanRow(val reflect.Value, indexes [][]int, row []any) error {
+	for i, index := range indexes {
+		if index == nil || i >= len(row) {
+			continue
+		}
+
+		field, err := fieldByIndexAlloc(val, index)
+		if err != nil {
+			return err
+		}
+		if err := assignValue(field, row[i]); err != nil {
+			return fmt.Errorf("failed to scan field %q: %w", val.Type().FieldByIndex(index).Name, err)
+		}
+	}
+	return nil
+}
+
+// fieldByIndexAlloc is like FieldByIndex, but the nil pointers of the embedded structs
+// are allocated.
+func fieldByIndexAlloc(val reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && val.Kind() == reflect.Pointer {
+			if val.IsNil() {
+				if !val.CanSet() {
+					return reflect.Value{}, fmt.Errorf("unable to set the nil pointer of unexported embedded struct %s", val.Type().Elem())
+				}
+				val.Set(reflect.New(val.Type().Elem()))
+			}
+			val = val.Elem()
+		}
+		val = val.Field(x)
+	}
+	return val, nil
+}
+
+func assignValue(field reflect.Value, v any) error {
+	if v == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	if field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := assignValue(elem.Elem(), v); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	val := reflect.ValueOf(v)
+	if val.Type().AssignableTo(field.Type()) {
+		field.Set(val)
+		return nil
+	}
+
+	switch {
+	case isNumberKind(val.Kind()) && isNumberKind(field.Kind()):
+		return assignNumber(field, val)
+	case field.Kind() == reflect.String && val.Kind() == reflect.String:
+		field.SetString(val.String())
+		return nil
+	case field.Kind() == reflect.String && val.Kind() == reflect.Slice && val.Type().Elem().Kind() == reflect.Uint8:
+		field.SetString(string(val.Bytes()))
+		return nil
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Uint8 && val.Kind() == reflect.String:
+		field.SetBytes([]byte(val.String()))
+		return nil
+	case field.Kind() == reflect.Bool && val.Kind() == reflect.Bool:
+		field.SetBool(val.Bool())
+		return nil
+	case isJSONKind(field.Kind()) && val.Kind() == reflect.String:
+		return json.Unmarshal([]byte(val.String()), field.Addr().Interface())
+	}
+
+	return fmt.Errorf("%T is not compatible with %s", v, field.Type())
+}
+
+// assignNumber sets the number into the field, it fails if the number overflows the
+// field, or a float is set into an integer field.
+func assignNumber(field, val reflect.Value) error {
+	overflow := false
+	switch {
+	case isFloatKind(val.Kind()):
+		if !isFloatKind(field.Kind()) {
+			return fmt.Errorf("float %v can not be set into %s", val.Float(), field.Type())
+		}
+		overflow = field.OverflowFloat(val.Float())
+	case isIntKind(val.Kind()):
+		i := val.Int()
+		switch {
+		case isIntKind(field.Kind()):
+			overflow = field.OverflowInt(i)
+		case isUintKind(field.Kind()):
+			overflow = i < 0 || field.OverflowUint(uint64(i))
+		}
+	default: // uint
+		u := val.Uint()
+		switch {
+		case isIntKind(field.Kind()):
+			overflow = u > math.MaxInt64 || field.OverflowInt(int64(u))
+		case isUintKind(field.Kind()):
+			overflow = field.OverflowUint(u)
+		}
+	}
+	if overflow {
+		return fmt.Errorf("%v overflows %s", val.Interface(), field.Type())
+	}
+
+	field.Set(val.Convert(field.Type()))
+	return nil
+}
+
+func isFloatKind(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+// isJSONKind reports whether the field of the kind is parsed as JSON column.
+func isJSONKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		return true
+	default:
+		return false
+	}
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	return isIntKind(kind) || isUintKind(kind) || isFloatKind(kind)
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanRows(t *testing.T) {
+	type Monitor struct {
+		ID      int64     `greptime:"tag;column:id;type:int64"`
+		Host    string    `greptime:"tag;column:host;type:string"`
+		Memory  uint64    `greptime:"field;column:memory;type:uint64"`
+		Cpu     *float64  `greptime:"field;column:cpu;type:float64"`
+		Running bool      `greptime:"field;column:running;type:boolean"`
+		Ts      time.Time `greptime:"timestamp;column:ts;type:timestamp;precision:millisecond"`
+		Ignored string    `greptime:"-"`
+	}
+
+	ts := time.UnixMilli(1700000000000).UTC()
+	columns := []string{"id", "host", "memory", "cpu", "running", "ts", "unknown"}
+	rows := [][]any{
+		{int64(1), "127.0.0.1", int32(10), float64(0.5), true, ts, "x"},
+		{int64(2), "127.0.0.2", int32(20), nil, false, ts, "y"},
+	}
+
+	var monitors []Monitor
+	assert.Nil(t, ScanRows(columns, rows, &monitors))
+	assert.Len(t, monitors, 2)
+	assert.Equal(t, int64(1), monitors[0].ID)
+	assert.Equal(t, "127.0.0.1", monitors[0].Host)
+	assert.Equal(t, uint64(10), monitors[0].Memory)
+	assert.Equal(t, 0.5, *monitors[0].Cpu)
+	assert.True(t, monitors[0].Running)
+	assert.Equal(t, ts, monitors[0].Ts)
+	assert.Nil(t, monitors[1].Cpu)
+	assert.False(t, monitors[1].Running)
+
+	var ptrs []*Monitor
+	assert.Nil(t, ScanRows(columns, rows, &ptrs))
+	assert.Len(t, ptrs, 2)
+	assert.Equal(t, "127.0.0.2", ptrs[1].Host)
+
+	var monitor Monitor
+	assert.Nil(t, ScanRows(columns, rows, &monitor))
+	assert.Equal(t, int64(1), monitor.ID)
+}
+
+func TestScanRowsJSON(t *testing.T) {
+	type Event struct {
+		Attrs map[string]any  `greptime:"field;column:attrs"`
+		Tags  []string        `greptime:"field;column:tags"`
+		Raw   json.RawMessage `greptime:"field;column:raw"`
+	}
+
+	var event Event
+	rows := [][]any{{`{"a":1}`, `["x"]`, `{"b":2}`}}
+	assert.Nil(t, ScanRows([]string{"attrs", "tags", "raw"}, rows, &event))
+	assert.Equal(t, map[string]any{"a": float64(1)}, event.Attrs)
+	assert.Equal(t, []string{"x"}, event.Tags)
+	assert.Equal(t, json.RawMessage(`{"b":2}`), event.Raw)
+
+	assert.NotNil(t, ScanRows([]string{"tags"}, [][]any{{`{`}}, &event))
+}
+
+func TestScanRowsInvalid(t *testing.T) {
+	type Monitor struct {
+		Host string `greptime:"tag;column:host;type:string"`
+	}
+
+	var monitor Monitor
+	assert.NotNil(t, ScanRows([]string{"host"}, [][]any{{1.5}}, &monitor))
+	assert.NotNil(t, ScanRows([]string{"host"}, nil, monitor))
+	assert.NotNil(t, ScanRows([]string{"host"}, nil, &[]int{}))
+}
+
+func TestScanRowsNumber(t *testing.T) {
+	type Monitor struct {
+		Memory uint8   `greptime:"field;column:memory;type:uint8"`
+		Load   int16   `greptime:"field;column:load;type:int16"`
+		Cpu    float32 `greptime:"field;column:cpu;type:float32"`
+	}
+	columns := []string{"memory", "load", "cpu"}
+
+	var monitor Monitor
+	assert.Nil(t, ScanRows(columns, [][]any{{int64(255), uint64(32767), 0.5}}, &monitor))
+	assert.Equal(t, Monitor{Memory: 255, Load: 32767, Cpu: 0.5}, monitor)
+
+	assert.ErrorContains(t, ScanRows(columns, [][]any{{int64(256), 0, 0}}, &monitor), "overflows")
+	assert.ErrorContains(t, ScanRows(columns, [][]any{{int64(-1), 0, 0}}, &monitor), "overflows")
+	assert.ErrorContains(t, ScanRows(columns, [][]any{{0, uint64(32768), 0}}, &monitor), "overflows")
+	assert.ErrorContains(t, ScanRows(columns, [][]any{{0, 0, 1e39}}, &monitor), "overflows")
+	assert.NotNil(t, ScanRows(columns, [][]any{{0, 1.5, 0}}, &monitor))
+}
@@ -17,12 +17,12 @@
 package schema
 
 import (
-	"errors"
 	"fmt"
 	"reflect"
 
 	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
 
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
 	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
 	"github.com/GreptimeTeam/greptimedb-ingester-go/util"
 )
@@ -37,6 +37,8 @@ type Schema struct {
 
 	fields []*gpb.ColumnSchema
 	values []*gpb.Row
+
+	codec *codec
 }
 
 type Tabler interface {
@@ -74,6 +76,21 @@ func Parse(input any) (*table.Table, error) {
 	return schema_.ToTable()
 }
 
+// ParseSchema is like Parse, but only the columns are parsed from the struct tags.
+// input can be a zero value or a nil pointer of the struct, like (*Monitor)(nil).
+func ParseSchema(input any) (*table.Table, error) {
+	if input == nil {
+		return nil, fmt.Errorf("unsupported empty data: %#v", input)
+	}
+
+	schema_, err := parseSchema(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema_.ToTable()
+}
+
 func indirectStruct(input any) (reflect.Type, error) {
 	value := reflect.ValueOf(input)
 	if value.Kind() == reflect.Ptr && value.IsNil() {
@@ -105,77 +122,57 @@ func parseSchema(input any) (*Schema, error) {
 		return nil, err
 	}
 
-	tableName, err := getTableName(typ)
+	codec_, err := codecOf(typ)
 	if err != nil {
 		return nil, err
 	}
 
-	size := len(reflect.VisibleFields(typ))
-	fields := make([]*gpb.ColumnSchema, 0, size)
-	for _, structField := range reflect.VisibleFields(typ) {
-		if !structField.IsExported() {
-			continue
-		}
-
-		field, err := parseField(structField)
-		if err != nil {
-			return nil, err
-		}
-		if field != nil {
-			fields = append(fields, field.ToColumnSchema())
-		}
+	// every table has its own columns, which may be modified
+	fields := make([]*gpb.ColumnSchema, len(codec_.fields))
+	for i, field := range codec_.fields {
+		fields[i] = field.ToColumnSchema()
 	}
-
-	return &Schema{tableName: tableName, fields: fields}, nil
+	return &Schema{tableName: codec_.tableName, fields: fields, codec: codec_}, nil
 }
 
 func (s *Schema) parseValues(input any) error {
 	val := reflect.ValueOf(input)
 	if val.Kind() == reflect.Ptr && val.IsNil() {
-		return errors.New("unable to parse value from nil pointer")
+		return errs.ErrNilPointer
 	}
+	return s.parseValue(reflect.Indirect(val))
+}
 
-	val = reflect.Indirect(val)
-	typ := val.Type()
-
-	if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+func (s *Schema) parseValue(val reflect.Value) error {
+	switch val.Kind() {
+	case reflect.Slice, reflect.Array:
+		if s.values == nil {
+			s.values = make([]*gpb.Row, 0, val.Len())
+		}
 		for i := 0; i < val.Len(); i++ {
-			if err := s.parseValues(val.Index(i).Interface()); err != nil {
+			if err := s.parseValue(val.Index(i)); err != nil {
 				return err
 			}
 		}
 		return nil
-	}
 
-	if typ.Kind() != reflect.Struct {
-		return fmt.Errorf("unsupported type %T of %+v", input, input)
-	}
-
-	visibleFields := reflect.VisibleFields(typ)
-	processingFields := make([]reflect.StructField, 0, len(visibleFields))
-	values := make([]*gpb.Value, 0, len(processingFields))
-
-	for _, structField := range visibleFields {
-		if !structField.IsExported() || structField.Tag.Get(GreptimeFieldTagKey) == IgnoreFiledTag {
-			continue
+	case reflect.Pointer, reflect.Interface:
+		if val.IsNil() {
+			return errs.ErrNilPointer
 		}
-		processingFields = append(processingFields, structField)
-	}
+		return s.parseValue(val.Elem())
 
-	for i, structField := range processingFields {
-		field := s.fields[i]
-		value, err := parseValue(field.Datatype, val.FieldByName(structField.Name))
+	case reflect.Struct:
+		row, err := s.codec.encodeRow(val)
 		if err != nil {
 			return err
 		}
-		values = append(values, value)
-	}
+		s.values = append(s.values, row)
+		return nil
 
-	if s.values == nil {
-		s.values = make([]*gpb.Row, 0)
+	default:
+		return fmt.Errorf("unsupported type %s of %+v", val.Type(), val)
 	}
-	s.values = append(s.values, &gpb.Row{Values: values})
-	return nil
 }
 
 func (s *Schema) ToTable() (*table.Table, error) {
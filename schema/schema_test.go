@@ -17,12 +17,15 @@
 package schema
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"testing"
 	"time"
 
 	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
 	"github.com/GreptimeTeam/greptimedb-ingester-go/table/cell"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -810,59 +813,88 @@ func TestParseWithNilValues(t *testing.T) {
 }
 
 func TestParseWithUnsupportedDatatype(t *testing.T) {
-	{ // field with struct type
-		type T struct{}
-		type Struct struct{ T T }
+	{ // field with channel type
+		type Struct struct{ T chan int }
 
-		tbl, err := Parse(Struct{T: T{}})
+		tbl, err := Parse(Struct{T: make(chan int)})
 		assert.NotNil(t, err)
-		assert.ErrorContains(t, err, fmt.Sprintf("unsupported type %q", "struct"))
+		assert.ErrorContains(t, err, fmt.Sprintf("unsupported type %q", "chan"))
 		assert.Nil(t, tbl)
 	}
 
-	{ // field with slice but not bytes
-		type Struct struct{ T []int32 }
+	{ // field with func type
+		type Struct struct{ T func() }
 
-		tbl, err := Parse(Struct{T: []int32{1, 2, 3}})
+		tbl, err := Parse(Struct{T: func() {}})
 		assert.NotNil(t, err)
-		assert.ErrorContains(t, err, fmt.Sprintf("unsupported type %q", "slice"))
+		assert.ErrorContains(t, err, fmt.Sprintf("unsupported type %q", "func"))
 		assert.Nil(t, tbl)
 	}
+}
 
-	{ // field with array but not bytes
-		type Struct struct{ T [3]int32 }
-
-		tbl, err := Parse(Struct{T: [3]int32{1, 2, 3}})
-		assert.NotNil(t, err)
-		assert.ErrorContains(t, err, fmt.Sprintf("unsupported type %q", "array"))
-		assert.Nil(t, tbl)
+func TestParseJSON(t *testing.T) {
+	type Nested struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
 	}
 
-	{ // field with map type
-		type Struct struct{ T map[string]string }
-
-		tbl, err := Parse(Struct{T: map[string]string{"a": "b"}})
-		assert.NotNil(t, err)
-		assert.ErrorContains(t, err, fmt.Sprintf("unsupported type %q", "map"))
-		assert.Nil(t, tbl)
+	type Event struct {
+		Attrs   map[string]any  `greptime:"field;column:attrs"`
+		Tags    []string        `greptime:"field;column:tags"`
+		Pair    [2]int          `greptime:"field;column:pair"`
+		Nested  Nested          `greptime:"field;column:nested"`
+		Ptr     *Nested         `greptime:"field;column:ptr"`
+		Raw     json.RawMessage `greptime:"field;column:raw"`
+		Text    string          `greptime:"field;column:text;type:json"`
+		Binary  map[string]any  `greptime:"field;column:binary;type:jsonb"`
+		Payload []byte          `greptime:"field;column:payload"`
+		Ts      time.Time       `greptime:"timestamp;column:ts;type:timestamp;precision:millisecond"`
 	}
 
-	{ // field with channel type
-		type Struct struct{ T chan int }
+	tbl, err := Parse([]Event{
+		{
+			Attrs:   map[string]any{"b": 1, "a": true},
+			Tags:    []string{"x", "y"},
+			Pair:    [2]int{1, 2},
+			Nested:  Nested{Name: "n", Count: 3},
+			Ptr:     &Nested{Name: "p"},
+			Raw:     json.RawMessage(`{"raw":null}`),
+			Text:    `{"text":1}`,
+			Binary:  map[string]any{"k": "v"},
+			Payload: []byte("bytes"),
+			Ts:      time.UnixMilli(0),
+		},
+		{Ts: time.UnixMilli(1)},
+	})
+	assert.Nil(t, err)
 
-		tbl, err := Parse(Struct{T: make(chan int)})
-		assert.NotNil(t, err)
-		assert.ErrorContains(t, err, fmt.Sprintf("unsupported type %q", "chan"))
-		assert.Nil(t, tbl)
+	columns := tbl.GetColumnsSchema()
+	for i := 0; i < 7; i++ {
+		assert.Equal(t, gpb.ColumnDataType_JSON, columns[i].Datatype, columns[i].ColumnName)
+		assert.Nil(t, columns[i].DatatypeExtension)
 	}
+	assert.Equal(t, gpb.ColumnDataType_BINARY, columns[7].Datatype)
+	assert.True(t, types.IsJSONBinary(columns[7].DatatypeExtension))
+	assert.Equal(t, gpb.ColumnDataType_BINARY, columns[8].Datatype)
+	assert.False(t, types.IsJSONBinary(columns[8].DatatypeExtension))
+
+	values := tbl.GetRows().Rows[0].Values
+	assert.Equal(t, `{"a":true,"b":1}`, values[0].GetStringValue())
+	assert.Equal(t, `["x","y"]`, values[1].GetStringValue())
+	assert.Equal(t, `[1,2]`, values[2].GetStringValue())
+	assert.Equal(t, `{"name":"n","count":3}`, values[3].GetStringValue())
+	assert.Equal(t, `{"name":"p","count":0}`, values[4].GetStringValue())
+	assert.Equal(t, `{"raw":null}`, values[5].GetStringValue())
+	assert.Equal(t, `{"text":1}`, values[6].GetStringValue())
+	jsonb, err := cell.EncodeJSONB([]byte(`{"k":"v"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, jsonb, values[7].GetBinaryValue())
+	assert.Equal(t, []byte("bytes"), values[8].GetBinaryValue())
 
-	{ // field with func type
-		type Struct struct{ T func() }
-
-		tbl, err := Parse(Struct{T: func() {}})
-		assert.NotNil(t, err)
-		assert.ErrorContains(t, err, fmt.Sprintf("unsupported type %q", "func"))
-		assert.Nil(t, tbl)
+	// the nil map, slice, pointer and json.RawMessage are null
+	values = tbl.GetRows().Rows[1].Values
+	for _, i := range []int{0, 1, 4, 5, 7} {
+		assert.Nil(t, values[i], columns[i].ColumnName)
 	}
 }
 
@@ -1070,3 +1102,138 @@ func TestParseSchemaWithIgnoreFields(t *testing.T) {
 		}
 	}
 }
+
+func TestParseDecimal(t *testing.T) {
+	type Bill struct {
+		Price  string    `greptime:"field;column:price;type:decimal128;precision:38;scale:4"`
+		Total  *big.Int  `greptime:"field;column:total;scale:2"`
+		Amount *big.Rat  `greptime:"field;column:amount;type:decimal128"`
+		Ts     time.Time `greptime:"timestamp;column:ts;type:timestamp;precision:millisecond"`
+	}
+
+	tbl, err := Parse([]Bill{
+		{Price: "123.4567", Total: big.NewInt(-3), Amount: big.NewRat(1, 4), Ts: time.UnixMilli(0)},
+		{Price: "0.1", Ts: time.UnixMilli(1)},
+	})
+	assert.Nil(t, err)
+
+	columns := tbl.GetColumnsSchema()
+	for i, ext := range []*gpb.DecimalTypeExtension{{Precision: 38, Scale: 4}, {Precision: 38, Scale: 2}, {Precision: 38, Scale: 10}} {
+		assert.Equal(t, gpb.ColumnDataType_DECIMAL128, columns[i].Datatype)
+		assert.Equal(t, ext, columns[i].DatatypeExtension.GetDecimalType())
+	}
+
+	rows := tbl.GetRows().Rows
+	assert.Equal(t, &gpb.Decimal128{Lo: 1234567}, rows[0].Values[0].GetDecimal128Value())
+	assert.Equal(t, &gpb.Decimal128{Hi: -1, Lo: -300}, rows[0].Values[1].GetDecimal128Value())
+	assert.Equal(t, &gpb.Decimal128{Lo: 2500000000}, rows[0].Values[2].GetDecimal128Value())
+	assert.Equal(t, &gpb.Decimal128{Lo: 1000}, rows[1].Values[0].GetDecimal128Value())
+	assert.Nil(t, rows[1].Values[1])
+
+	type Invalid struct {
+		Price string `greptime:"field;column:price;type:decimal128;precision:38;scale:40"`
+	}
+	_, err = Parse(Invalid{Price: "1"})
+	assert.NotNil(t, err)
+}
+
+func TestParseInterval(t *testing.T) {
+	type Job struct {
+		Period   int32          `greptime:"field;column:period;type:interval_year_month"`
+		Timeout  time.Duration  `greptime:"field;column:timeout;type:interval_day_time"`
+		Retry    *time.Duration `greptime:"field;column:retry;type:interval"`
+		Schedule types.Interval `greptime:"field;column:schedule"`
+		Ts       time.Time      `greptime:"timestamp;column:ts;type:timestamp;precision:millisecond"`
+	}
+
+	retry := time.Second
+	tbl, err := Parse(Job{
+		Period:   12,
+		Timeout:  25 * time.Hour,
+		Retry:    &retry,
+		Schedule: types.Interval{Months: 1, Days: 2},
+		Ts:       time.UnixMilli(0),
+	})
+	assert.Nil(t, err)
+
+	columns := tbl.GetColumnsSchema()
+	assert.Equal(t, gpb.ColumnDataType_INTERVAL_YEAR_MONTH, columns[0].Datatype)
+	assert.Equal(t, gpb.ColumnDataType_INTERVAL_DAY_TIME, columns[1].Datatype)
+	assert.Equal(t, gpb.ColumnDataType_INTERVAL_MONTH_DAY_NANO, columns[2].Datatype)
+	assert.Equal(t, gpb.ColumnDataType_INTERVAL_MONTH_DAY_NANO, columns[3].Datatype)
+
+	values := tbl.GetRows().Rows[0].Values
+	assert.EqualValues(t, 12, values[0].GetIntervalYearMonthValue())
+	assert.Equal(t, int64(1)<<32|3600000, values[1].GetIntervalDayTimeValue())
+	assert.Equal(t, &gpb.IntervalMonthDayNano{Nanoseconds: int64(time.Second)}, values[2].GetIntervalMonthDayNanoValue())
+	assert.Equal(t, &gpb.IntervalMonthDayNano{Months: 1, Days: 2}, values[3].GetIntervalMonthDayNanoValue())
+
+	type Invalid struct {
+		Period string `greptime:"field;column:period;type:interval_year_month"`
+	}
+	_, err = Parse(Invalid{Period: "1 year"})
+	assert.NotNil(t, err)
+}
+
+func TestParseTime(t *testing.T) {
+	type Shift struct {
+		Start    time.Time      `greptime:"field;column:start;type:time;precision:second"`
+		Duration time.Duration  `greptime:"field;column:duration;type:time_millisecond"`
+		End      *time.Duration `greptime:"field;column:end;type:time;precision:nanosecond"`
+		Seconds  int64          `greptime:"field;column:seconds;type:time_second"`
+		Ts       time.Time      `greptime:"timestamp;column:ts;type:timestamp;precision:millisecond"`
+	}
+
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	end := 17 * time.Hour
+	tbl, err := Parse(Shift{
+		Start:    time.Date(2024, 1, 2, 9, 30, 0, 0, loc),
+		Duration: 8 * time.Hour,
+		End:      &end,
+		Seconds:  60,
+		Ts:       time.UnixMilli(0),
+	})
+	assert.Nil(t, err)
+
+	columns := tbl.GetColumnsSchema()
+	assert.Equal(t, gpb.ColumnDataType_TIME_SECOND, columns[0].Datatype)
+	assert.Equal(t, gpb.ColumnDataType_TIME_MILLISECOND, columns[1].Datatype)
+	assert.Equal(t, gpb.ColumnDataType_TIME_NANOSECOND, columns[2].Datatype)
+	assert.Equal(t, gpb.ColumnDataType_TIME_SECOND, columns[3].Datatype)
+
+	values := tbl.GetRows().Rows[0].Values
+	assert.EqualValues(t, 9*3600+30*60, values[0].GetTimeSecondValue())
+	assert.EqualValues(t, 8*3600*1000, values[1].GetTimeMillisecondValue())
+	assert.EqualValues(t, end.Nanoseconds(), values[2].GetTimeNanosecondValue())
+	assert.EqualValues(t, 60, values[3].GetTimeSecondValue())
+
+	for _, typ := range []string{"time_second", "time_millisecond", "time_microsecond", "time_nanosecond", "time"} {
+		_, err := types.ParseColumnType(typ, "")
+		assert.Nil(t, err)
+	}
+}
+
+func TestParseSchemaOnly(t *testing.T) {
+	type Monitor struct {
+		ID   int64     `greptime:"tag;column:id;type:int64"`
+		Cpu  float64   `greptime:"field;column:cpu;type:float64"`
+		Ts   time.Time `greptime:"timestamp;column:ts;type:timestamp;precision:millisecond"`
+		Skip string    `greptime:"-"`
+	}
+
+	for _, input := range []any{Monitor{}, &Monitor{}, (*Monitor)(nil), []Monitor{}} {
+		tbl, err := ParseSchema(input)
+		assert.Nil(t, err)
+		assert.Zero(t, tbl.RowCount())
+
+		expr, err := tbl.ToCreateTableExpr()
+		assert.Nil(t, err)
+		assert.Equal(t, "monitor", expr.TableName)
+		assert.Equal(t, []string{"id"}, expr.PrimaryKeys)
+		assert.Equal(t, "ts", expr.TimeIndex)
+		assert.Len(t, expr.ColumnDefs, 3)
+	}
+
+	_, err := ParseSchema(nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,280 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package greptime
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/schema"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+var defaultStreamMaxReopens = 1
+
+// StreamGeneration is the statistics of one HandleRequests stream opened by the StreamWriter.
+// A new generation starts when the stream is reopened after a failure or a Flush.
+//
+// If Err is not nil, the rows of the generation may be partially written.
+type StreamGeneration struct {
+	ID           int    // starts from 1
+	Requests     int    // number of requests sent
+	Rows         int    // number of rows sent
+	AffectedRows uint32 // number of rows acknowledged by GreptimeDB
+	Err          error
+}
+
+// StreamOptions is to define how the StreamWriter behaves.
+//
+//   - MaxReopens is how many times a request is resent on a reopened stream
+//     after the stream failed. 0 means no reopen for the failed request, but
+//     the stream is still reopened by the next request.
+//   - OnGenerationClosed is called with every closed generation, including
+//     the ones closed by failures. It is called after the writer is unlocked,
+//     so it may call the methods of the writer.
+type StreamOptions struct {
+	MaxReopens         int
+	OnGenerationClosed func(*StreamGeneration)
+}
+
+// NewStreamOptions helps to init StreamOptions with default values.
+func NewStreamOptions() *StreamOptions {
+	return &StreamOptions{MaxReopens: defaultStreamMaxReopens}
+}
+
+// WithMaxReopens set the MaxReopens field.
+func (o *StreamOptions) WithMaxReopens(reopens int) *StreamOptions {
+	o.MaxReopens = reopens
+	return o
+}
+
+// WithOnGenerationClosed set the OnGenerationClosed field.
+func (o *StreamOptions) WithOnGenerationClosed(callback func(*StreamGeneration)) *StreamOptions {
+	o.OnGenerationClosed = callback
+	return o
+}
+
+// StreamWriter sends data into GreptimeDB via its own HandleRequests stream, which
+// is independent of the other StreamWriters and the stream methods of Client.
+// A StreamWriter is safe for concurrent use by multiple goroutines.
+//
+// The stream is opened lazily, and reopened when it fails. The rows sent on the failed
+// stream may be lost, which is reported by errs.StreamRowsLostError.
+type StreamWriter struct {
+	client *Client
+	opts   *StreamOptions
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu         sync.Mutex
+	stream     gpb.GreptimeDatabase_HandleRequestsClient
+	generation *StreamGeneration
+	nextID     int
+	closed     bool
+
+	// the closed generations to be passed to OnGenerationClosed after unlocking mu
+	closedGenerations []*StreamGeneration
+}
+
+// NewStreamWriter creates a StreamWriter. All the streams of the writer are opened
+// with ctx, cancelling ctx aborts the writer. Call Close when it is no longer needed.
+//
+//	writer := client.NewStreamWriter(context.Background(), greptime.NewStreamOptions())
+//
+//	err := writer.Write(tbl)
+//	err := writer.WriteObject(monitors)
+//
+//	// wait for the rows sent so far to be acknowledged
+//	generation, err := writer.Flush()
+//
+//	generation, err := writer.Close()
+func (c *Client) NewStreamWriter(ctx context.Context, opts *StreamOptions) *StreamWriter {
+	if opts == nil {
+		opts = NewStreamOptions()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	return &StreamWriter{
+		client: c,
+		opts:   opts,
+		ctx:    ctx,
+		cancel: cancel,
+		nextID: 1,
+	}
+}
+
+// Write is like [Client.StreamWrite] to send the data into GreptimeDB via explicit schema.
+func (w *StreamWriter) Write(tables ...*table.Table) error {
+	return w.submit(types.INSERT, tables...)
+}
+
+// Delete is like [Client.StreamDelete] to delete the data from GreptimeDB via explicit schema.
+func (w *StreamWriter) Delete(tables ...*table.Table) error {
+	return w.submit(types.DELETE, tables...)
+}
+
+// WriteObject is like [Write] to send the data into GreptimeDB, but schema is defined in the struct tag.
+func (w *StreamWriter) WriteObject(obj any) error {
+	tbl, err := schema.Parse(obj)
+	if err != nil {
+		return err
+	}
+	return w.submit(types.INSERT, tbl)
+}
+
+// DeleteObject is like [Delete] to delete the data from GreptimeDB, but schema is defined in the struct tag.
+func (w *StreamWriter) DeleteObject(obj any) error {
+	tbl, err := schema.Parse(obj)
+	if err != nil {
+		return err
+	}
+	return w.submit(types.DELETE, tbl)
+}
+
+// Flush closes the current stream and waits for GreptimeDB to acknowledge it. The
+// closed generation is returned, and the next request opens a new stream.
+func (w *StreamWriter) Flush() (*StreamGeneration, error) {
+	w.mu.Lock()
+	defer w.unlock()
+
+	if w.closed {
+		return nil, errs.ErrStreamWriterClosed
+	}
+	return w.closeGeneration()
+}
+
+// Close flushes the current stream and closes the writer.
+func (w *StreamWriter) Close() (*StreamGeneration, error) {
+	w.mu.Lock()
+	defer w.unlock()
+
+	if w.closed {
+		return nil, errs.ErrStreamWriterClosed
+	}
+	w.closed = true
+	defer w.cancel()
+
+	return w.closeGeneration()
+}
+
+func (w *StreamWriter) submit(operation types.Operation, tables ...*table.Table) error {
+	request_, err := w.client.buildRequest(operation, tables...)
+	if err != nil {
+		return err
+	}
+
+	rows := 0
+	for _, tbl := range tables {
+		rows += tbl.RowCount()
+	}
+
+	w.mu.Lock()
+	defer w.unlock()
+
+	if w.closed {
+		return errs.ErrStreamWriterClosed
+	}
+
+	// the rows sent earlier on the failed streams
+	lost, failure := 0, error(nil)
+	for reopens := 0; ; reopens++ {
+		if w.stream == nil {
+			if err := w.open(); err != nil {
+				return lostError(lost, err)
+			}
+		}
+
+		err := w.stream.Send(request_)
+		if err == nil {
+			w.generation.Requests++
+			w.generation.Rows += rows
+			if lost > 0 {
+				return &errs.StreamRowsLostError{Rows: lost, Err: failure}
+			}
+			return nil
+		}
+
+		// the real error of a broken stream is returned by CloseAndRecv
+		generation, closeErr := w.closeGeneration()
+		if closeErr != nil {
+			err = fmt.Errorf("%w: %w", err, closeErr)
+		}
+		lost, failure = lost+generation.Rows, err
+		if reopens >= w.opts.MaxReopens || w.ctx.Err() != nil {
+			return lostError(lost, err)
+		}
+	}
+}
+
+// lostError returns errs.StreamRowsLostError wrapping err if any row is lost, or err.
+func lostError(lost int, err error) error {
+	if lost > 0 {
+		return &errs.StreamRowsLostError{Rows: lost, Err: err}
+	}
+	return err
+}
+
+// unlock unlocks mu, and then calls OnGenerationClosed with the closed generations.
+func (w *StreamWriter) unlock() {
+	generations := w.closedGenerations
+	w.closedGenerations = nil
+	w.mu.Unlock()
+
+	for _, generation := range generations {
+		w.opts.OnGenerationClosed(generation)
+	}
+}
+
+// open must be called with mu held.
+func (w *StreamWriter) open() error {
+	stream, err := w.client.client.HandleRequests(w.ctx)
+	if err != nil {
+		return err
+	}
+
+	w.stream = stream
+	w.generation = &StreamGeneration{ID: w.nextID}
+	w.nextID++
+	return nil
+}
+
+// closeGeneration must be called with mu held.
+func (w *StreamWriter) closeGeneration() (*StreamGeneration, error) {
+	if w.stream == nil {
+		return &StreamGeneration{}, nil
+	}
+
+	stream, generation := w.stream, w.generation
+	w.stream, w.generation = nil, nil
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		generation.Err = err
+	} else {
+		generation.AffectedRows = resp.GetAffectedRows().GetValue()
+	}
+
+	if w.opts.OnGenerationClosed != nil {
+		w.closedGenerations = append(w.closedGenerations, generation)
+	}
+	return generation, err
+}
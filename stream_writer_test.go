@@ -0,0 +1,222 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package greptime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+func TestStreamWriterWriteObj(t *testing.T) {
+	loc, err := time.LoadLocation(timezone)
+	assert.Nil(t, err)
+	ts1 := time.Now().Add(-1 * time.Minute).UnixMilli()
+	time1 := time.UnixMilli(ts1).In(loc)
+	ts2 := time.Now().Add(-2 * time.Minute).UnixMilli()
+	time2 := time.UnixMilli(ts2).In(loc)
+
+	monitors := []monitor{
+		{
+			ID:          randomId(),
+			Host:        "127.0.0.1",
+			Memory:      1,
+			Cpu:         1.0,
+			Temperature: -1,
+			Ts:          time1,
+			Running:     true,
+		},
+		{
+			ID:          randomId(),
+			Host:        "127.0.0.2",
+			Memory:      2,
+			Cpu:         2.0,
+			Temperature: -2,
+			Ts:          time2,
+			Running:     true,
+		},
+	}
+
+	generations := make([]*StreamGeneration, 0)
+	opts := NewStreamOptions().WithOnGenerationClosed(func(generation *StreamGeneration) {
+		generations = append(generations, generation)
+	})
+
+	writer := cli.NewStreamWriter(context.Background(), opts)
+	assert.Nil(t, writer.WriteObject(monitors[0]))
+
+	generation, err := writer.Flush()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, generation.ID)
+	assert.Equal(t, 1, generation.Rows)
+	assert.EqualValues(t, 1, generation.AffectedRows)
+
+	assert.Nil(t, writer.WriteObject(monitors[1]))
+	generation, err = writer.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, generation.ID)
+	assert.EqualValues(t, 1, generation.AffectedRows)
+	assert.Len(t, generations, 2)
+
+	assert.ErrorIs(t, writer.WriteObject(monitors), errs.ErrStreamWriterClosed)
+
+	monitors_, err := db.Query(fmt.Sprintf("select * from %s where id in %s order by host asc", monitorTableName, getMonitorsIds(monitors)))
+	assert.Nil(t, err)
+
+	assert.Equal(t, len(monitors), len(monitors_))
+
+	for i, monitor_ := range monitors_ {
+		assert.Equal(t, monitors[i], monitor_)
+	}
+}
+
+// fakeStream accepts sends requests, and then fails.
+type fakeStream struct {
+	gpb.GreptimeDatabase_HandleRequestsClient
+
+	sends  int
+	rows   int
+	failed bool
+}
+
+func (s *fakeStream) Send(req *gpb.GreptimeRequest) error {
+	if s.sends <= 0 {
+		s.failed = true
+		return io.EOF
+	}
+	s.sends--
+	for _, insert := range req.GetRowInserts().GetInserts() {
+		s.rows += len(insert.GetRows().GetRows())
+	}
+	return nil
+}
+
+func (s *fakeStream) CloseAndRecv() (*gpb.GreptimeResponse, error) {
+	if s.failed {
+		return nil, status.Error(codes.Unavailable, "stream is broken")
+	}
+	affected := &gpb.AffectedRows{Value: uint32(s.rows)}
+	return &gpb.GreptimeResponse{Response: &gpb.GreptimeResponse_AffectedRows{AffectedRows: affected}}, nil
+}
+
+// fakeStreamClient opens the streams in order.
+type fakeStreamClient struct {
+	gpb.GreptimeDatabaseClient
+
+	streams []*fakeStream
+	opened  int
+}
+
+func (c *fakeStreamClient) HandleRequests(context.Context, ...grpc.CallOption) (gpb.GreptimeDatabase_HandleRequestsClient, error) {
+	if c.opened >= len(c.streams) {
+		return nil, status.Error(codes.Unavailable, "unavailable")
+	}
+	c.opened++
+	return c.streams[c.opened-1], nil
+}
+
+func newStreamTable(t *testing.T, rows int) *table.Table {
+	tbl, err := table.New("monitor")
+	assert.Nil(t, err)
+	assert.Nil(t, tbl.AddFieldColumn("cpu", types.FLOAT64))
+	assert.Nil(t, tbl.AddTimestampColumn("ts", types.TIMESTAMP_MILLISECOND))
+	for i := 0; i < rows; i++ {
+		assert.Nil(t, tbl.AddRow(float64(i), int64(i)))
+	}
+	return tbl
+}
+
+func TestStreamWriterReopen(t *testing.T) {
+	fake := &fakeStreamClient{streams: []*fakeStream{{sends: 2}, {sends: 1}, {sends: 1}}}
+	client := &Client{cfg: NewConfig("127.0.0.1").WithDatabase("public"), client: fake}
+
+	var writer *StreamWriter
+	generations := make([]*StreamGeneration, 0)
+	opts := NewStreamOptions().WithOnGenerationClosed(func(generation *StreamGeneration) {
+		generations = append(generations, generation)
+		// the writer is unlocked when the callback is called
+		if generation.Err != nil {
+			_, err := writer.Flush()
+			assert.Nil(t, err)
+		}
+	})
+	writer = client.NewStreamWriter(context.Background(), opts)
+
+	assert.Nil(t, writer.Write(newStreamTable(t, 2)))
+	assert.Nil(t, writer.Write(newStreamTable(t, 3)))
+
+	// the request is resent on the reopened stream, but the rows sent before are lost
+	err := writer.Write(newStreamTable(t, 1))
+	var lostErr *errs.StreamRowsLostError
+	assert.True(t, errors.As(err, &lostErr))
+	assert.Equal(t, 5, lostErr.Rows)
+	assert.Equal(t, codes.Unavailable, status.Code(lostErr.Err))
+	assert.Equal(t, 2, fake.opened)
+
+	// the failed generation, and the reopened one flushed by the callback
+	assert.Len(t, generations, 2)
+	assert.Equal(t, 1, generations[0].ID)
+	assert.Equal(t, 5, generations[0].Rows)
+	assert.NotNil(t, generations[0].Err)
+	assert.Equal(t, 2, generations[1].ID)
+	assert.Equal(t, uint32(1), generations[1].AffectedRows)
+	assert.Nil(t, generations[1].Err)
+
+	assert.Nil(t, writer.Write(newStreamTable(t, 4)))
+	generation, err := writer.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, generation.ID)
+	assert.Equal(t, uint32(4), generation.AffectedRows)
+	assert.Len(t, generations, 3)
+}
+
+func TestStreamWriterMaxReopens(t *testing.T) {
+	fake := &fakeStreamClient{streams: []*fakeStream{{sends: 1}, {}, {}, {sends: 1}}}
+	client := &Client{cfg: NewConfig("127.0.0.1").WithDatabase("public"), client: fake}
+	writer := client.NewStreamWriter(context.Background(), NewStreamOptions().WithMaxReopens(1))
+
+	assert.Nil(t, writer.Write(newStreamTable(t, 2)))
+
+	// the first stream and the reopened one fail
+	err := writer.Write(newStreamTable(t, 1))
+	var lostErr *errs.StreamRowsLostError
+	assert.True(t, errors.As(err, &lostErr))
+	assert.Equal(t, 2, lostErr.Rows)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, 2, fake.opened)
+
+	// nothing is lost if the stream fails before any row is sent on it
+	err = writer.Write(newStreamTable(t, 1))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, fake.opened)
+
+	_, err = writer.Close()
+	assert.Nil(t, err)
+}
@@ -0,0 +1,635 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package table
+
+import (
+	"fmt"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/cell"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+// Builder appends the values column by column without boxing them into any, which
+// is much cheaper than AddRow for the large amount of rows. The table built is the
+// same as the one built by AddRow.
+//
+// The buffers are reused, so the table built is only valid until the next Build or Reset.
+//
+//	builder, err := table.NewBuilder(tbl)
+//	host, cpu, ts := builder.ColumnIndex("host"), builder.ColumnIndex("cpu"), builder.ColumnIndex("ts")
+//
+//	for _, m := range metrics {
+//	    builder.AppendString(host, m.Host)
+//	    builder.AppendFloat64(cpu, m.Cpu)
+//	    builder.AppendTimestamp(ts, m.Ts)
+//	}
+//
+//	built, err := builder.Build()
+//	resp, err := client.Write(ctx, built)
+//	builder.Reset()
+type Builder struct {
+	table   *Table
+	columns []*columnBuffer
+
+	// the buffers of the rows built
+	rows    []gpb.Row
+	rowPtrs []*gpb.Row
+	values  []gpb.Value
+	cells   []*gpb.Value
+}
+
+type columnBuffer struct {
+	schema *gpb.ColumnSchema
+	valid  []bool
+
+	// only one of them is used by the data type of the column
+	ints    []int64
+	uints   []uint64
+	floats  []float64
+	bools   []bool
+	strings []string
+	bytes   [][]byte
+	others  []*gpb.Value
+
+	wrappers any // the slice of the oneof wrappers of gpb.Value, reused by Build
+}
+
+// NewBuilder creates the Builder with the columns of tbl, and the rows of tbl are ignored.
+func NewBuilder(tbl *Table) (*Builder, error) {
+	if tbl.IsColumnEmpty() {
+		return nil, errs.ErrEmptyColumn
+	}
+
+	columns := make([]*columnBuffer, len(tbl.columnsSchema))
+	for i, schema := range tbl.columnsSchema {
+		columns[i] = &columnBuffer{schema: schema}
+	}
+	return &Builder{table: tbl, columns: columns}, nil
+}
+
+// ColumnIndex returns the index of the column used by the Append methods, or -1 if
+// the column does not exist.
+func (b *Builder) ColumnIndex(name string) int {
+	name, err := b.table.sanitate_if_needed(name)
+	if err != nil {
+		return -1
+	}
+
+	for i, column := range b.columns {
+		if column.schema.ColumnName == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (b *Builder) column(col int, accepted func(gpb.ColumnDataType) bool, typeName string) (*columnBuffer, error) {
+	if col < 0 || col >= len(b.columns) {
+		return nil, fmt.Errorf("column index %d is out of range [0, %d)", col, len(b.columns))
+	}
+
+	column := b.columns[col]
+	if accepted != nil && !accepted(column.schema.Datatype) {
+		return nil, fmt.Errorf("%s is not compatible with column %q of %v", typeName, column.schema.ColumnName, column.schema.Datatype)
+	}
+	column.valid = append(column.valid, true)
+	return column, nil
+}
+
+// AppendInt64 appends the value into the column of Integer. The column of DATE,
+// DATETIME, TIMESTAMP and TIME also accepts the integer as it is.
+func (b *Builder) AppendInt64(col int, v int64) error {
+	column, err := b.column(col, isIntColumn, "int64")
+	if err != nil {
+		return err
+	}
+	column.ints = append(column.ints, v)
+	return nil
+}
+
+// AppendUint64 appends the value into the column of Unsigned Integer.
+func (b *Builder) AppendUint64(col int, v uint64) error {
+	column, err := b.column(col, isUintColumn, "uint64")
+	if err != nil {
+		return err
+	}
+	column.uints = append(column.uints, v)
+	return nil
+}
+
+// AppendFloat64 appends the value into the column of Float.
+func (b *Builder) AppendFloat64(col int, v float64) error {
+	column, err := b.column(col, isFloatColumn, "float64")
+	if err != nil {
+		return err
+	}
+	column.floats = append(column.floats, v)
+	return nil
+}
+
+// AppendBool appends the value into the column of BOOLEAN.
+func (b *Builder) AppendBool(col int, v bool) error {
+	column, err := b.column(col, isBoolColumn, "bool")
+	if err != nil {
+		return err
+	}
+	column.bools = append(column.bools, v)
+	return nil
+}
+
+// AppendString appends the value into the column of STRING or JSON.
+func (b *Builder) AppendString(col int, v string) error {
+	column, err := b.column(col, isStringColumn, "string")
+	if err != nil {
+		return err
+	}
+	column.strings = append(column.strings, v)
+	return nil
+}
+
+// AppendBytes appends the value into the column of BINARY. The value of the JSON
+// column in the binary JSONB format is the JSON text, which is encoded into JSONB.
+func (b *Builder) AppendBytes(col int, v []byte) error {
+	if col >= 0 && col < len(b.columns) && types.IsJSONBinary(b.columns[col].schema.DatatypeExtension) {
+		encoded, err := cell.EncodeJSONB(v)
+		if err != nil {
+			return fmt.Errorf("column %q: %w", b.columns[col].schema.ColumnName, err)
+		}
+		v = encoded
+	}
+
+	column, err := b.column(col, isBytesColumn, "[]byte")
+	if err != nil {
+		return err
+	}
+	column.bytes = append(column.bytes, v)
+	return nil
+}
+
+// AppendTimestamp appends the value into the column of DATE, DATETIME, TIMESTAMP, or
+// TIME whose value is the time of day of v.
+func (b *Builder) AppendTimestamp(col int, v time.Time) error {
+	column, err := b.column(col, isTimeColumn, "time.Time")
+	if err != nil {
+		return err
+	}
+	column.ints = append(column.ints, timeValue(column.schema.Datatype, v))
+	return nil
+}
+
+// AppendValue appends the value of any type the same as AddRow. It is for the
+// columns not covered by the other Append methods, like DECIMAL128 and INTERVAL.
+func (b *Builder) AppendValue(col int, v any) error {
+	if v == nil {
+		return b.AppendNull(col)
+	}
+
+	if col < 0 || col >= len(b.columns) {
+		return fmt.Errorf("column index %d is out of range [0, %d)", col, len(b.columns))
+	}
+	schema := b.columns[col].schema
+	val, err := cell.New(v, schema.Datatype).WithExtension(schema.DatatypeExtension).Build()
+	if err != nil {
+		return err
+	}
+
+	column, _ := b.column(col, nil, "")
+	column.appendValue(val)
+	return nil
+}
+
+// AppendNull appends null into the column.
+func (b *Builder) AppendNull(col int) error {
+	column, err := b.column(col, nil, "")
+	if err != nil {
+		return err
+	}
+	column.valid[len(column.valid)-1] = false
+	column.appendZero()
+	return nil
+}
+
+// RowCount returns the number of rows appended so far, which is the number of values
+// of the first column.
+func (b *Builder) RowCount() int {
+	return len(b.columns[0].valid)
+}
+
+// Reset drops the rows appended so far and keeps the buffers for reuse.
+func (b *Builder) Reset() {
+	for _, column := range b.columns {
+		column.valid = column.valid[:0]
+		column.ints = column.ints[:0]
+		column.uints = column.uints[:0]
+		column.floats = column.floats[:0]
+		column.bools = column.bools[:0]
+		column.strings = column.strings[:0]
+		clear(column.bytes)
+		column.bytes = column.bytes[:0]
+		clear(column.others)
+		column.others = column.others[:0]
+	}
+}
+
+// Build builds the table of the rows appended so far. Every column MUST have the
+// same number of values.
+func (b *Builder) Build() (*Table, error) {
+	rowCount := b.RowCount()
+	for _, column := range b.columns {
+		if len(column.valid) != rowCount {
+			return nil, fmt.Errorf("column %q has %d values, but %d rows expected", column.schema.ColumnName, len(column.valid), rowCount)
+		}
+	}
+	if rowCount == 0 {
+		return nil, errs.ErrEmptyTable
+	}
+
+	width := len(b.columns)
+	b.values = resize(b.values, rowCount*width)
+	b.cells = resize(b.cells, rowCount*width)
+	b.rows = resize(b.rows, rowCount)
+	b.rowPtrs = resize(b.rowPtrs, rowCount)
+
+	for i := range b.values {
+		b.values[i] = gpb.Value{}
+		b.cells[i] = &b.values[i]
+	}
+	for i := range b.rows {
+		b.rows[i] = gpb.Row{Values: b.cells[i*width : (i+1)*width : (i+1)*width]}
+		b.rowPtrs[i] = &b.rows[i]
+	}
+	for i, column := range b.columns {
+		column.build(b.cells[i:], width)
+	}
+
+	return &Table{
+		name:            b.table.name,
+		columnsSchema:   b.table.columnsSchema,
+		rows:            &gpb.Rows{Schema: b.table.columnsSchema, Rows: b.rowPtrs},
+		sanitate_needed: b.table.sanitate_needed,
+		builder:         b,
+	}, nil
+}
+
+// ColumnValues is the values of one column appended into the Builder. Only the slice
+// for the data type of the column is set, and the row i is null if Valid[i] is false.
+type ColumnValues struct {
+	Valid   []bool
+	Ints    []int64 // Integer, DATE, DATETIME, TIMESTAMP and TIME
+	Uints   []uint64
+	Floats  []float64
+	Bools   []bool
+	Strings []string
+	Bytes   [][]byte
+	Others  []*gpb.Value // the other data types, like DECIMAL128 and INTERVAL
+}
+
+// ColumnValues returns the values of the column col kept by the Builder which built
+// the table, so the columnar encodings like Arrow can skip the rows. ok is false if
+// the table is not built by a Builder, or any row or column is added after Build.
+func (t *Table) ColumnValues(col int) (values ColumnValues, ok bool) {
+	b := t.builder
+	if b == nil || t.rows == nil || col < 0 || col >= len(b.columns) ||
+		len(b.columns) != len(t.columnsSchema) || len(t.rows.Rows) != b.RowCount() ||
+		len(b.rowPtrs) != len(t.rows.Rows) || len(b.rowPtrs) == 0 || &b.rowPtrs[0] != &t.rows.Rows[0] {
+		return ColumnValues{}, false
+	}
+
+	c := b.columns[col]
+	return ColumnValues{
+		Valid:   c.valid,
+		Ints:    c.ints,
+		Uints:   c.uints,
+		Floats:  c.floats,
+		Bools:   c.bools,
+		Strings: c.strings,
+		Bytes:   c.bytes,
+		Others:  c.others,
+	}, true
+}
+
+// nullValue is only a placeholder, the null values are skipped by build.
+var nullValue = &gpb.Value{}
+
+func (c *columnBuffer) appendZero() {
+	c.appendValue(nullValue)
+}
+
+// appendValue keeps the built value in the typed buffer of the column.
+func (c *columnBuffer) appendValue(val *gpb.Value) {
+	switch dataType := c.schema.Datatype; {
+	case !isTypedColumn(dataType):
+		c.others = append(c.others, val)
+	case isIntColumn(dataType):
+		c.ints = append(c.ints, intOf(val))
+	case isUintColumn(dataType):
+		c.uints = append(c.uints, uintOf(val))
+	case isFloatColumn(dataType):
+		if v, ok := val.ValueData.(*gpb.Value_F32Value); ok {
+			c.floats = append(c.floats, float64(v.F32Value))
+		} else {
+			c.floats = append(c.floats, val.GetF64Value())
+		}
+	case isBoolColumn(dataType):
+		c.bools = append(c.bools, val.GetBoolValue())
+	case isStringColumn(dataType):
+		c.strings = append(c.strings, val.GetStringValue())
+	case isBytesColumn(dataType):
+		c.bytes = append(c.bytes, val.GetBinaryValue())
+	}
+}
+
+// build sets the values of the column into cells, cells[i*stride] is for the row i.
+func (c *columnBuffer) build(cells []*gpb.Value, stride int) {
+	switch c.schema.Datatype {
+	case gpb.ColumnDataType_INT8:
+		fill(c, cells, stride, c.ints, func(cell *gpb.Value, w *gpb.Value_I8Value, v int64) {
+			w.I8Value = int32(v)
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_INT16:
+		fill(c, cells, stride, c.ints, func(cell *gpb.Value, w *gpb.Value_I16Value, v int64) {
+			w.I16Value = int32(v)
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_INT32:
+		fill(c, cells, stride, c.ints, func(cell *gpb.Value, w *gpb.Value_I32Value, v int64) {
+			w.I32Value = int32(v)
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_INT64:
+		fill(c, cells, stride, c.ints, func(cell *gpb.Value, w *gpb.Value_I64Value, v int64) {
+			w.I64Value = v
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_UINT8:
+		fill(c, cells, stride, c.uints, func(cell *gpb.Value, w *gpb.Value_U8Value, v uint64) {
+			w.U8Value = uint32(v)
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_UINT16:
+		fill(c, cells, stride, c.uints, func(cell *gpb.Value, w *gpb.Value_U16Value, v uint64) {
+			w.U16Value = uint32(v)
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_UINT32:
+		fill(c, cells, stride, c.uints, func(cell *gpb.Value, w *gpb.Value_U32Value, v uint64) {
+			w.U32Value = uint32(v)
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_UINT64:
+		fill(c, cells, stride, c.uints, func(cell *gpb.Value, w *gpb.Value_U64Value, v uint64) {
+			w.U64Value = v
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_FLOAT32:
+		fill(c, cells, stride, c.floats, func(cell *gpb.Value, w *gpb.Value_F32Value, v float64) {
+			w.F32Value = float32(v)
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_FLOAT64:
+		fill(c, cells, stride, c.floats, func(cell *gpb.Value, w *gpb.Value_F64Value, v float64) {
+			w.F64Value = v
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_BOOLEAN:
+		fill(c, cells, stride, c.bools, func(cell *gpb.Value, w *gpb.Value_BoolValue, v bool) {
+			w.BoolValue = v
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_STRING, gpb.ColumnDataType_JSON:
+		fill(c, cells, stride, c.strings, func(cell *gpb.Value, w *gpb.Value_StringValue, v string) {
+			w.StringValue = v
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_BINARY:
+		fill(c, cells, stride, c.bytes, func(cell *gpb.Value, w *gpb.Value_BinaryValue, v []byte) {
+			w.BinaryValue = v
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_DATE:
+		fill(c, cells, stride, c.ints, func(cell *gpb.Value, w *gpb.Value_DateValue, v int64) {
+			w.DateValue = int32(v)
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_DATETIME, gpb.ColumnDataType_TIMESTAMP_MICROSECOND:
+		fill(c, cells, stride, c.ints, func(cell *gpb.Value, w *gpb.Value_TimestampMicrosecondValue, v int64) {
+			w.TimestampMicrosecondValue = v
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_TIMESTAMP_SECOND:
+		fill(c, cells, stride, c.ints, func(cell *gpb.Value, w *gpb.Value_TimestampSecondValue, v int64) {
+			w.TimestampSecondValue = v
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_TIMESTAMP_MILLISECOND:
+		fill(c, cells, stride, c.ints, func(cell *gpb.Value, w *gpb.Value_TimestampMillisecondValue, v int64) {
+			w.TimestampMillisecondValue = v
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_TIMESTAMP_NANOSECOND:
+		fill(c, cells, stride, c.ints, func(cell *gpb.Value, w *gpb.Value_TimestampNanosecondValue, v int64) {
+			w.TimestampNanosecondValue = v
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_TIME_SECOND:
+		fill(c, cells, stride, c.ints, func(cell *gpb.Value, w *gpb.Value_TimeSecondValue, v int64) {
+			w.TimeSecondValue = v
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_TIME_MILLISECOND:
+		fill(c, cells, stride, c.ints, func(cell *gpb.Value, w *gpb.Value_TimeMillisecondValue, v int64) {
+			w.TimeMillisecondValue = v
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_TIME_MICROSECOND:
+		fill(c, cells, stride, c.ints, func(cell *gpb.Value, w *gpb.Value_TimeMicrosecondValue, v int64) {
+			w.TimeMicrosecondValue = v
+			cell.ValueData = w
+		})
+	case gpb.ColumnDataType_TIME_NANOSECOND:
+		fill(c, cells, stride, c.ints, func(cell *gpb.Value, w *gpb.Value_TimeNanosecondValue, v int64) {
+			w.TimeNanosecondValue = v
+			cell.ValueData = w
+		})
+	default:
+		for i, val := range c.others {
+			if c.valid[i] {
+				cells[i*stride] = val
+			}
+		}
+	}
+}
+
+// fill sets the valid values into cells via the oneof wrappers, which are allocated
+// at once and reused by the next build.
+func fill[W any, V any](c *columnBuffer, cells []*gpb.Value, stride int, values []V, set func(*gpb.Value, *W, V)) {
+	wrappers, _ := c.wrappers.([]W)
+	wrappers = resize(wrappers, len(values))
+	c.wrappers = wrappers
+
+	for i, v := range values {
+		if c.valid[i] {
+			set(cells[i*stride], &wrappers[i], v)
+		}
+	}
+}
+
+func resize[T any](buf []T, n int) []T {
+	if cap(buf) < n {
+		return make([]T, n)
+	}
+	return buf[:n]
+}
+
+func isTypedColumn(dataType gpb.ColumnDataType) bool {
+	return isIntColumn(dataType) || isUintColumn(dataType) || isFloatColumn(dataType) ||
+		isBoolColumn(dataType) || isStringColumn(dataType) || isBytesColumn(dataType)
+}
+
+func isIntColumn(dataType gpb.ColumnDataType) bool {
+	switch dataType {
+	case gpb.ColumnDataType_INT8, gpb.ColumnDataType_INT16, gpb.ColumnDataType_INT32, gpb.ColumnDataType_INT64:
+		return true
+	default:
+		return isTimeColumn(dataType)
+	}
+}
+
+func isTimeColumn(dataType gpb.ColumnDataType) bool {
+	switch dataType {
+	case gpb.ColumnDataType_DATE, gpb.ColumnDataType_DATETIME,
+		gpb.ColumnDataType_TIMESTAMP_SECOND, gpb.ColumnDataType_TIMESTAMP_MILLISECOND,
+		gpb.ColumnDataType_TIMESTAMP_MICROSECOND, gpb.ColumnDataType_TIMESTAMP_NANOSECOND,
+		gpb.ColumnDataType_TIME_SECOND, gpb.ColumnDataType_TIME_MILLISECOND,
+		gpb.ColumnDataType_TIME_MICROSECOND, gpb.ColumnDataType_TIME_NANOSECOND:
+		return true
+	default:
+		return false
+	}
+}
+
+func isUintColumn(dataType gpb.ColumnDataType) bool {
+	switch dataType {
+	case gpb.ColumnDataType_UINT8, gpb.ColumnDataType_UINT16, gpb.ColumnDataType_UINT32, gpb.ColumnDataType_UINT64:
+		return true
+	default:
+		return false
+	}
+}
+
+func isFloatColumn(dataType gpb.ColumnDataType) bool {
+	return dataType == gpb.ColumnDataType_FLOAT32 || dataType == gpb.ColumnDataType_FLOAT64
+}
+
+func isBoolColumn(dataType gpb.ColumnDataType) bool {
+	return dataType == gpb.ColumnDataType_BOOLEAN
+}
+
+func isStringColumn(dataType gpb.ColumnDataType) bool {
+	return dataType == gpb.ColumnDataType_STRING || dataType == gpb.ColumnDataType_JSON
+}
+
+func isBytesColumn(dataType gpb.ColumnDataType) bool {
+	return dataType == gpb.ColumnDataType_BINARY
+}
+
+// timeValue converts t the same as the cell package.
+func timeValue(dataType gpb.ColumnDataType, t time.Time) int64 {
+	switch dataType {
+	case gpb.ColumnDataType_DATE:
+		return int64(int32(t.Unix()) / cell.ONE_DAY_IN_SECONDS)
+	case gpb.ColumnDataType_TIMESTAMP_SECOND:
+		return t.Unix()
+	case gpb.ColumnDataType_TIMESTAMP_MILLISECOND:
+		return t.UnixMilli()
+	case gpb.ColumnDataType_DATETIME, gpb.ColumnDataType_TIMESTAMP_MICROSECOND:
+		return t.UnixMicro()
+	case gpb.ColumnDataType_TIMESTAMP_NANOSECOND:
+		return t.UnixNano()
+	}
+
+	hour, minute, second := t.Clock()
+	timeOfDay := time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute +
+		time.Duration(second)*time.Second + time.Duration(t.Nanosecond())
+	switch dataType {
+	case gpb.ColumnDataType_TIME_SECOND:
+		return int64(timeOfDay / time.Second)
+	case gpb.ColumnDataType_TIME_MILLISECOND:
+		return int64(timeOfDay / time.Millisecond)
+	case gpb.ColumnDataType_TIME_MICROSECOND:
+		return int64(timeOfDay / time.Microsecond)
+	default:
+		return int64(timeOfDay)
+	}
+}
+
+// intOf returns the integer of the value built by the cell package.
+func intOf(val *gpb.Value) int64 {
+	switch v := val.ValueData.(type) {
+	case *gpb.Value_I8Value:
+		return int64(v.I8Value)
+	case *gpb.Value_I16Value:
+		return int64(v.I16Value)
+	case *gpb.Value_I32Value:
+		return int64(v.I32Value)
+	case *gpb.Value_I64Value:
+		return v.I64Value
+	case *gpb.Value_DateValue:
+		return int64(v.DateValue)
+	case *gpb.Value_DatetimeValue:
+		return v.DatetimeValue
+	case *gpb.Value_TimestampSecondValue:
+		return v.TimestampSecondValue
+	case *gpb.Value_TimestampMillisecondValue:
+		return v.TimestampMillisecondValue
+	case *gpb.Value_TimestampMicrosecondValue:
+		return v.TimestampMicrosecondValue
+	case *gpb.Value_TimestampNanosecondValue:
+		return v.TimestampNanosecondValue
+	case *gpb.Value_TimeSecondValue:
+		return v.TimeSecondValue
+	case *gpb.Value_TimeMillisecondValue:
+		return v.TimeMillisecondValue
+	case *gpb.Value_TimeMicrosecondValue:
+		return v.TimeMicrosecondValue
+	case *gpb.Value_TimeNanosecondValue:
+		return v.TimeNanosecondValue
+	default:
+		return 0
+	}
+}
+
+// uintOf returns the unsigned integer of the value built by the cell package.
+func uintOf(val *gpb.Value) uint64 {
+	switch v := val.ValueData.(type) {
+	case *gpb.Value_U8Value:
+		return uint64(v.U8Value)
+	case *gpb.Value_U16Value:
+		return uint64(v.U16Value)
+	case *gpb.Value_U32Value:
+		return uint64(v.U32Value)
+	case *gpb.Value_U64Value:
+		return v.U64Value
+	default:
+		return 0
+	}
+}
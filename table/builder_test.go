@@ -0,0 +1,248 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package table
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+func newBuilderTable(t testing.TB) *Table {
+	tbl, err := New("monitor")
+	assert.Nil(t, err)
+	assert.Nil(t, tbl.AddTagColumn("host", types.STRING))
+	assert.Nil(t, tbl.AddFieldColumn("id", types.INT16))
+	assert.Nil(t, tbl.AddFieldColumn("memory", types.UINT32))
+	assert.Nil(t, tbl.AddFieldColumn("cpu", types.FLOAT32))
+	assert.Nil(t, tbl.AddFieldColumn("running", types.BOOLEAN))
+	assert.Nil(t, tbl.AddFieldColumn("payload", types.BINARY))
+	assert.Nil(t, tbl.AddFieldColumn("price", types.DECIMAL128, WithDecimal(10, 2)))
+	assert.Nil(t, tbl.AddFieldColumn("date", types.DATE))
+	assert.Nil(t, tbl.AddFieldColumn("start", types.TIME_SECOND))
+	assert.Nil(t, tbl.AddTimestampColumn("ts", types.TIMESTAMP_MILLISECOND))
+	return tbl
+}
+
+func TestBuilder(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	expected := newBuilderTable(t)
+	assert.Nil(t, expected.AddRow("127.0.0.1", 1, uint32(2), 0.5, true, []byte("a"), "1.25", ts, ts, ts))
+	assert.Nil(t, expected.AddRow("127.0.0.2", nil, nil, nil, nil, nil, nil, nil, nil, ts.Add(time.Second)))
+	assert.Nil(t, expected.AddRow("127.0.0.3", int16(-1), uint32(3), float32(1.5), false, []byte{}, 2, 19724, 60, int64(1)))
+	expectedReq, err := expected.ToInsertRequest()
+	assert.Nil(t, err)
+
+	builder, err := NewBuilder(newBuilderTable(t))
+	assert.Nil(t, err)
+	host, id, memory, cpu, running := builder.ColumnIndex("host"), builder.ColumnIndex("id"),
+		builder.ColumnIndex("memory"), builder.ColumnIndex("cpu"), builder.ColumnIndex("running")
+	payload, price, date, start, tsCol := builder.ColumnIndex("payload"), builder.ColumnIndex("price"),
+		builder.ColumnIndex("date"), builder.ColumnIndex("start"), builder.ColumnIndex("ts")
+	assert.Equal(t, -1, builder.ColumnIndex("unknown"))
+
+	for i := 0; i < 2; i++ { // the second round is built from the reused buffers
+		assert.Nil(t, builder.AppendString(host, "127.0.0.1"))
+		assert.Nil(t, builder.AppendInt64(id, 1))
+		assert.Nil(t, builder.AppendUint64(memory, 2))
+		assert.Nil(t, builder.AppendFloat64(cpu, 0.5))
+		assert.Nil(t, builder.AppendBool(running, true))
+		assert.Nil(t, builder.AppendBytes(payload, []byte("a")))
+		assert.Nil(t, builder.AppendValue(price, "1.25"))
+		assert.Nil(t, builder.AppendTimestamp(date, ts))
+		assert.Nil(t, builder.AppendTimestamp(start, ts))
+		assert.Nil(t, builder.AppendTimestamp(tsCol, ts))
+
+		assert.Nil(t, builder.AppendString(host, "127.0.0.2"))
+		for _, col := range []int{id, memory, cpu, running, payload, price, date, start} {
+			assert.Nil(t, builder.AppendNull(col))
+		}
+		assert.Nil(t, builder.AppendTimestamp(tsCol, ts.Add(time.Second)))
+
+		assert.Nil(t, builder.AppendValue(host, "127.0.0.3"))
+		assert.Nil(t, builder.AppendValue(id, int16(-1)))
+		assert.Nil(t, builder.AppendValue(memory, uint32(3)))
+		assert.Nil(t, builder.AppendValue(cpu, float32(1.5)))
+		assert.Nil(t, builder.AppendValue(running, false))
+		assert.Nil(t, builder.AppendValue(payload, []byte{}))
+		assert.Nil(t, builder.AppendValue(price, 2))
+		assert.Nil(t, builder.AppendInt64(date, 19724))
+		assert.Nil(t, builder.AppendInt64(start, 60))
+		assert.Nil(t, builder.AppendInt64(tsCol, 1))
+
+		assert.Equal(t, 3, builder.RowCount())
+		built, err := builder.Build()
+		assert.Nil(t, err)
+		req, err := built.ToInsertRequest()
+		assert.Nil(t, err)
+		assert.True(t, proto.Equal(expectedReq, req), "%v\n%v", expectedReq, req)
+
+		builder.Reset()
+		assert.Equal(t, 0, builder.RowCount())
+	}
+}
+
+func TestBuilderColumnValues(t *testing.T) {
+	tbl := newBuilderTable(t)
+	assert.Nil(t, tbl.AddRow("127.0.0.1", 1, uint32(2), 0.5, true, []byte("a"), "1.25", nil, nil, int64(1)))
+	_, ok := tbl.ColumnValues(0)
+	assert.False(t, ok)
+
+	builder, err := NewBuilder(newBuilderTable(t))
+	assert.Nil(t, err)
+	host, price := builder.ColumnIndex("host"), builder.ColumnIndex("price")
+	for col := 0; col < len(tbl.GetColumnsSchema()); col++ {
+		assert.Nil(t, builder.AppendNull(col))
+	}
+	for col, v := range []any{"127.0.0.2", 1, uint32(2), 0.5, true, []byte("a"), "1.25", 1, 1, int64(1)} {
+		assert.Nil(t, builder.AppendValue(col, v))
+	}
+
+	built, err := builder.Build()
+	assert.Nil(t, err)
+	values, ok := built.ColumnValues(host)
+	assert.True(t, ok)
+	assert.Equal(t, []bool{false, true}, values.Valid)
+	assert.Equal(t, []string{"", "127.0.0.2"}, values.Strings)
+	values, ok = built.ColumnValues(price)
+	assert.True(t, ok)
+	assert.Len(t, values.Others, 2)
+	_, ok = built.ColumnValues(len(tbl.GetColumnsSchema()))
+	assert.False(t, ok)
+
+	// the rows added after Build are not in the buffers
+	assert.Nil(t, built.AddRow("127.0.0.3", nil, nil, nil, nil, nil, nil, nil, nil, int64(2)))
+	_, ok = built.ColumnValues(host)
+	assert.False(t, ok)
+}
+
+func TestBuilderInvalid(t *testing.T) {
+	_, err := NewBuilder(&Table{})
+	assert.ErrorIs(t, err, errs.ErrEmptyColumn)
+
+	builder, err := NewBuilder(newBuilderTable(t))
+	assert.Nil(t, err)
+
+	_, err = builder.Build()
+	assert.ErrorIs(t, err, errs.ErrEmptyTable)
+
+	assert.NotNil(t, builder.AppendInt64(builder.ColumnIndex("host"), 1))
+	assert.NotNil(t, builder.AppendString(builder.ColumnIndex("id"), "1"))
+	assert.NotNil(t, builder.AppendTimestamp(builder.ColumnIndex("cpu"), time.Now()))
+	assert.NotNil(t, builder.AppendValue(builder.ColumnIndex("price"), "abc"))
+	assert.NotNil(t, builder.AppendBool(-1, true))
+	assert.NotNil(t, builder.AppendNull(100))
+
+	// the columns have different number of values
+	assert.Nil(t, builder.AppendString(builder.ColumnIndex("host"), "127.0.0.1"))
+	_, err = builder.Build()
+	assert.NotNil(t, err)
+}
+
+func TestBuilderJSONBinary(t *testing.T) {
+	newTable := func() *Table {
+		tbl, err := New("events")
+		assert.Nil(t, err)
+		assert.Nil(t, tbl.AddFieldColumn("attrs", types.JSONB))
+		assert.Nil(t, tbl.AddTimestampColumn("ts", types.TIMESTAMP_MILLISECOND))
+		return tbl
+	}
+
+	expected := newTable()
+	assert.Nil(t, expected.AddRow(`{"a":1}`, int64(1)))
+
+	builder, err := NewBuilder(newTable())
+	assert.Nil(t, err)
+	assert.NotNil(t, builder.AppendBytes(0, []byte("invalid")))
+	assert.Nil(t, builder.AppendBytes(0, []byte(`{"a":1}`)))
+	assert.Nil(t, builder.AppendInt64(1, 1))
+
+	tbl, err := builder.Build()
+	assert.Nil(t, err)
+	assert.True(t, proto.Equal(expected.GetRows(), tbl.GetRows()))
+}
+
+const benchmarkRows = 1000
+
+func newBenchmarkTable(b *testing.B) *Table {
+	tbl, err := New("monitor")
+	assert.Nil(b, err)
+	assert.Nil(b, tbl.AddTagColumn("host", types.STRING))
+	assert.Nil(b, tbl.AddFieldColumn("id", types.INT64))
+	assert.Nil(b, tbl.AddFieldColumn("memory", types.UINT64))
+	assert.Nil(b, tbl.AddFieldColumn("cpu", types.FLOAT64))
+	assert.Nil(b, tbl.AddFieldColumn("running", types.BOOLEAN))
+	assert.Nil(b, tbl.AddFieldColumn("payload", types.BINARY))
+	assert.Nil(b, tbl.AddTimestampColumn("ts", types.TIMESTAMP_MILLISECOND))
+	return tbl
+}
+
+func BenchmarkAddRow(b *testing.B) {
+	tbl := newBenchmarkTable(b)
+	columns := tbl.GetColumnsSchema()
+	ts := time.Now()
+	payload := []byte("payload")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tbl.WithColumnsSchema(columns).WithRows(nil)
+		for j := 0; j < benchmarkRows; j++ {
+			_ = tbl.AddRow("127.0.0.1", j, uint64(j), float64(j), true, payload, ts)
+		}
+		if _, err := tbl.ToInsertRequest(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBuilder(b *testing.B) {
+	builder, err := NewBuilder(newBenchmarkTable(b))
+	if err != nil {
+		b.Fatal(err)
+	}
+	ts := time.Now()
+	payload := []byte("payload")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		builder.Reset()
+		for j := 0; j < benchmarkRows; j++ {
+			_ = builder.AppendString(0, "127.0.0.1")
+			_ = builder.AppendInt64(1, int64(j))
+			_ = builder.AppendUint64(2, uint64(j))
+			_ = builder.AppendFloat64(3, float64(j))
+			_ = builder.AppendBool(4, true)
+			_ = builder.AppendBytes(5, payload)
+			_ = builder.AppendTimestamp(6, ts)
+		}
+		tbl, err := builder.Build()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := tbl.ToInsertRequest(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
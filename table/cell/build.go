@@ -431,15 +431,39 @@ func BuildTimestampNanosecond(v any) (*gpb.Value, error) {
 	return &gpb.Value{ValueData: &gpb.Value_TimestampNanosecondValue{TimestampNanosecondValue: val}}, nil
 }
 
-func BuildTimeSecond(v any) (*gpb.Value, error) {
+// getTimeOfDay returns the time elapsed since midnight of time.Time in its location, or
+// time.Duration itself. The integer is returned as it is.
+func getTimeOfDay(v any) (*time.Duration, *int64, error) {
+	switch t := v.(type) {
+	case time.Duration:
+		return &t, nil, nil
+	case *time.Duration:
+		return t, nil, nil
+	}
+
 	t, i, err := getTimeOrInteger(v)
+	if err != nil {
+		return nil, nil, fmt.Errorf(formatter+" Time, Duration or Integer", v, v)
+	}
+	if t == nil {
+		return nil, i, nil
+	}
+
+	hour, minute, second := t.Clock()
+	d := time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute +
+		time.Duration(second)*time.Second + time.Duration(t.Nanosecond())
+	return &d, nil, nil
+}
+
+func BuildTimeSecond(v any) (*gpb.Value, error) {
+	d, i, err := getTimeOfDay(v)
 	if err != nil {
 		return nil, err
 	}
 
 	var val int64
-	if t != nil {
-		val = t.Unix()
+	if d != nil {
+		val = int64(*d / time.Second)
 	} else {
 		val = *i
 	}
@@ -448,14 +472,14 @@ func BuildTimeSecond(v any) (*gpb.Value, error) {
 }
 
 func BuildTimeMillisecond(v any) (*gpb.Value, error) {
-	t, i, err := getTimeOrInteger(v)
+	d, i, err := getTimeOfDay(v)
 	if err != nil {
 		return nil, err
 	}
 
 	var val int64
-	if t != nil {
-		val = t.UnixMilli()
+	if d != nil {
+		val = int64(*d / time.Millisecond)
 	} else {
 		val = *i
 	}
@@ -464,14 +488,14 @@ func BuildTimeMillisecond(v any) (*gpb.Value, error) {
 }
 
 func BuildTimeMicrosecond(v any) (*gpb.Value, error) {
-	t, i, err := getTimeOrInteger(v)
+	d, i, err := getTimeOfDay(v)
 	if err != nil {
 		return nil, err
 	}
 
 	var val int64
-	if t != nil {
-		val = t.UnixMicro()
+	if d != nil {
+		val = int64(*d / time.Microsecond)
 	} else {
 		val = *i
 	}
@@ -480,14 +504,14 @@ func BuildTimeMicrosecond(v any) (*gpb.Value, error) {
 }
 
 func BuildTimeNanosecond(v any) (*gpb.Value, error) {
-	t, i, err := getTimeOrInteger(v)
+	d, i, err := getTimeOfDay(v)
 	if err != nil {
 		return nil, err
 	}
 
 	var val int64
-	if t != nil {
-		val = t.UnixNano()
+	if d != nil {
+		val = int64(*d / time.Nanosecond)
 	} else {
 		val = *i
 	}
@@ -512,3 +536,26 @@ func BuildJSON(v any) (*gpb.Value, error) {
 
 	return &gpb.Value{ValueData: &gpb.Value_StringValue{StringValue: val}}, nil
 }
+
+// BuildJSONB is like BuildJSON, but the value is encoded in the binary JSONB format.
+func BuildJSONB(v any) (*gpb.Value, error) {
+	var text []byte
+	switch t := v.(type) {
+	case string:
+		text = []byte(t)
+	case *string:
+		text = []byte(*t)
+	default:
+		jsonData, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		text = jsonData
+	}
+
+	val, err := EncodeJSONB(text)
+	if err != nil {
+		return nil, err
+	}
+	return &gpb.Value{ValueData: &gpb.Value_BinaryValue{BinaryValue: val}}, nil
+}
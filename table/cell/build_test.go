@@ -0,0 +1,56 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cell
+
+import (
+	"testing"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildTime(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*60*60)
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 6007008, loc)
+	d := 3*time.Hour + 4*time.Minute + 5*time.Second + 6007008*time.Nanosecond
+
+	for _, v := range []any{ts, &ts, d, &d} {
+		val, err := New(v, gpb.ColumnDataType_TIME_SECOND).Build()
+		assert.Nil(t, err)
+		assert.EqualValues(t, 11045, val.GetTimeSecondValue())
+
+		val, err = New(v, gpb.ColumnDataType_TIME_MILLISECOND).Build()
+		assert.Nil(t, err)
+		assert.EqualValues(t, 11045006, val.GetTimeMillisecondValue())
+
+		val, err = New(v, gpb.ColumnDataType_TIME_MICROSECOND).Build()
+		assert.Nil(t, err)
+		assert.EqualValues(t, 11045006007, val.GetTimeMicrosecondValue())
+
+		val, err = New(v, gpb.ColumnDataType_TIME_NANOSECOND).Build()
+		assert.Nil(t, err)
+		assert.EqualValues(t, 11045006007008, val.GetTimeNanosecondValue())
+	}
+
+	val, err := New(int32(42), gpb.ColumnDataType_TIME_SECOND).Build()
+	assert.Nil(t, err)
+	assert.EqualValues(t, 42, val.GetTimeSecondValue())
+
+	_, err = New("03:04:05", gpb.ColumnDataType_TIME_SECOND).Build()
+	assert.NotNil(t, err)
+}
@@ -20,18 +20,35 @@ import (
 	"fmt"
 
 	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
 )
 
 type Cell struct {
-	Val      any
-	DataType gpb.ColumnDataType
+	Val       any
+	DataType  gpb.ColumnDataType
+	Extension *gpb.ColumnDataTypeExtension
 }
 
 func New(v any, dataType gpb.ColumnDataType) Cell {
 	return Cell{Val: v, DataType: dataType}
 }
 
+// WithExtension sets the datatype extension of the column, like the precision
+// and scale of DECIMAL128.
+func (c Cell) WithExtension(ext *gpb.ColumnDataTypeExtension) Cell {
+	c.Extension = ext
+	return c
+}
+
+// Build builds the value of the column, the custom types are unwrapped by Unwrap first.
 func (c Cell) Build() (*gpb.Value, error) {
+	val, err := Unwrap(c.Val, c.DataType)
+	if err != nil {
+		return nil, err
+	}
+	c.Val = val
+
 	if c.Val == nil {
 		return &gpb.Value{}, nil
 	}
@@ -51,6 +68,9 @@ func (c Cell) Build() (*gpb.Value, error) {
 		return BuildBool(c.Val)
 
 	case gpb.ColumnDataType_BINARY:
+		if types.IsJSONBinary(c.Extension) {
+			return BuildJSONB(c.Val)
+		}
 		return BuildBytes(c.Val)
 
 	case gpb.ColumnDataType_STRING:
@@ -79,14 +99,15 @@ func (c Cell) Build() (*gpb.Value, error) {
 	case gpb.ColumnDataType_TIME_NANOSECOND:
 		return BuildTimeNanosecond(c.Val)
 
-	case gpb.ColumnDataType_INTERVAL_YEAR_MONTH,
-		gpb.ColumnDataType_INTERVAL_DAY_TIME,
-		gpb.ColumnDataType_INTERVAL_MONTH_DAY_NANO:
-		return nil, fmt.Errorf("INTERVAL not implemented yet for %#v", c.Val)
+	case gpb.ColumnDataType_INTERVAL_YEAR_MONTH:
+		return BuildIntervalYearMonth(c.Val)
+	case gpb.ColumnDataType_INTERVAL_DAY_TIME:
+		return BuildIntervalDayTime(c.Val)
+	case gpb.ColumnDataType_INTERVAL_MONTH_DAY_NANO:
+		return BuildIntervalMonthDayNano(c.Val)
 
-	// TODO(yuanbohan): support decimal 128
 	case gpb.ColumnDataType_DECIMAL128:
-		return nil, fmt.Errorf("DECIMAL 128 not supported for %#v", c.Val)
+		return BuildDecimal128(c.Val, c.Extension)
 
 	case gpb.ColumnDataType_JSON:
 		return BuildJSON(c.Val)
@@ -0,0 +1,136 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cell
+
+import (
+	"database/sql/driver"
+	"encoding"
+	"fmt"
+	"reflect"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+// Unwrap returns the value of the custom types to be built for the column of datatype t:
+//
+//   - types.Valuer returns GreptimeValue
+//   - driver.Valuer returns Value, like sql.NullString and sql.Null[T], except the decimal
+//     types of the DECIMAL128 column, which are built by BuildDecimal128
+//   - encoding.TextMarshaler and fmt.Stringer return the text for the STRING column, like
+//     net.IP, netip.Addr and the enums
+//
+// The nil pointers of them are nil, and the other values are returned as is.
+func Unwrap(v any, t gpb.ColumnDataType) (any, error) {
+	if valuer, ok := v.(types.Valuer); ok {
+		if isNilPointer(v) {
+			return nil, nil
+		}
+		v, _ = valuer.GreptimeValue()
+	}
+
+	if valuer, ok := v.(driver.Valuer); ok && !isDecimalValue(v, t) {
+		if isNilPointer(v) {
+			return nil, nil
+		}
+		val, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		v = val
+	}
+
+	if t != gpb.ColumnDataType_STRING {
+		return v, nil
+	}
+	switch val := v.(type) {
+	case string, *string, nil:
+		return v, nil
+	case encoding.TextMarshaler:
+		if isNilPointer(v) {
+			return nil, nil
+		}
+		text, err := val.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return string(text), nil
+	case fmt.Stringer:
+		if isNilPointer(v) {
+			return nil, nil
+		}
+		return val.String(), nil
+	}
+	return v, nil
+}
+
+// IsCustomType reports whether the value of typ is unwrapped by Unwrap for the column of
+// datatype t.
+func IsCustomType(typ reflect.Type, t gpb.ColumnDataType) bool {
+	if typ.Implements(valuerType) {
+		return true
+	}
+	if typ.Implements(driverValuerType) && !(t == gpb.ColumnDataType_DECIMAL128 && typ.Implements(decimalCoefficientType)) {
+		return true
+	}
+	return t == gpb.ColumnDataType_STRING && (typ.Implements(textMarshalerType) || typ.Implements(stringerType))
+}
+
+// CustomType infers the datatype of the custom type from its zero value, v is the pointer
+// to it like new(T):
+//
+//   - types.Valuer is the column type returned by GreptimeValue
+//   - the other driver.Valuer is inferred from Value, or STRING
+//
+// It is used by schema.Parse and the encoders generated by greptime-gen for the fields
+// without the type in the tag.
+func CustomType(v any) (gpb.ColumnDataType, error) {
+	if valuer, ok := v.(types.Valuer); ok {
+		_, type_ := valuer.GreptimeValue()
+		return types.ConvertType(type_)
+	}
+
+	valuer, ok := v.(driver.Valuer)
+	if !ok {
+		return 0, fmt.Errorf("type %T implements neither types.Valuer nor driver.Valuer", v)
+	}
+	if val, err := valuer.Value(); err == nil && val != nil {
+		if type_, err := types.InferColumnType(val); err == nil {
+			return types.ConvertType(type_)
+		}
+	}
+	return gpb.ColumnDataType_STRING, nil
+}
+
+var (
+	valuerType             = reflect.TypeOf((*types.Valuer)(nil)).Elem()
+	driverValuerType       = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	decimalCoefficientType = reflect.TypeOf((*decimalCoefficient)(nil)).Elem()
+	textMarshalerType      = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType           = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+func isDecimalValue(v any, t gpb.ColumnDataType) bool {
+	_, ok := v.(decimalCoefficient)
+	return ok && t == gpb.ColumnDataType_DECIMAL128
+}
+
+func isNilPointer(v any) bool {
+	val := reflect.ValueOf(v)
+	return val.Kind() == reflect.Pointer && val.IsNil()
+}
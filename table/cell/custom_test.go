@@ -0,0 +1,100 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cell
+
+import (
+	"database/sql"
+	"errors"
+	"net"
+	"net/netip"
+	"testing"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+type level int
+
+func (l level) String() string {
+	return [...]string{"debug", "info", "warn"}[l]
+}
+
+type userID struct {
+	id int64
+}
+
+func (u *userID) GreptimeValue() (any, types.ColumnType) {
+	return u.id, types.INT64
+}
+
+type failedValuer struct{}
+
+func (failedValuer) Value() (any, error) {
+	return nil, errors.New("failed")
+}
+
+func TestCustomType(t *testing.T) {
+	datatype, err := CustomType(new(userID))
+	assert.Nil(t, err)
+	assert.Equal(t, gpb.ColumnDataType_INT64, datatype)
+
+	datatype, err = CustomType(new(sql.NullInt32))
+	assert.Nil(t, err)
+	assert.Equal(t, gpb.ColumnDataType_STRING, datatype)
+
+	_, err = CustomType(new(level))
+	assert.NotNil(t, err)
+}
+
+func TestBuildCustomValue(t *testing.T) {
+	val, err := New(&userID{id: 7}, gpb.ColumnDataType_INT64).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(7), val.GetI64Value())
+
+	val, err = New((*userID)(nil), gpb.ColumnDataType_INT64).Build()
+	assert.Nil(t, err)
+	assert.Nil(t, val.GetValueData())
+
+	val, err = New(sql.NullString{String: "a", Valid: true}, gpb.ColumnDataType_STRING).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "a", val.GetStringValue())
+
+	val, err = New(sql.NullInt32{Int32: 1}, gpb.ColumnDataType_INT32).Build()
+	assert.Nil(t, err)
+	assert.Nil(t, val.GetValueData())
+
+	val, err = New(sql.Null[float64]{V: 1.5, Valid: true}, gpb.ColumnDataType_FLOAT64).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, 1.5, val.GetF64Value())
+
+	_, err = New(failedValuer{}, gpb.ColumnDataType_STRING).Build()
+	assert.ErrorContains(t, err, "failed")
+
+	val, err = New(level(1), gpb.ColumnDataType_STRING).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "info", val.GetStringValue())
+
+	val, err = New(net.ParseIP("127.0.0.1"), gpb.ColumnDataType_STRING).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "127.0.0.1", val.GetStringValue())
+
+	val, err = New(netip.MustParseAddr("::1"), gpb.ColumnDataType_STRING).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "::1", val.GetStringValue())
+}
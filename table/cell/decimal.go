@@ -0,0 +1,204 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cell
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+// decimalCoefficient is implemented by the decimal types like github.com/shopspring/decimal,
+// whose value is Coefficient * 10 ^ Exponent.
+type decimalCoefficient interface {
+	Coefficient() *big.Int
+	Exponent() int32
+}
+
+var (
+	ten      = big.NewInt(10)
+	uint64Of = new(big.Int).Lsh(big.NewInt(1), 64)
+	int128Of = new(big.Int).Lsh(big.NewInt(1), 128)
+)
+
+// BuildDecimal128 converts v into the unscaled 128-bit integer of the decimal with the
+// precision and scale of ext. The supported values are:
+//
+//   - Integer, Unsigned Integer and Float, and the pointers of them
+//   - string like "123.4567" or "1.2e-3"
+//   - *big.Int, *big.Rat and *big.Float
+//   - the decimal types with Coefficient() and Exponent() methods, like github.com/shopspring/decimal
+//   - the types implementing encoding.TextMarshaler or fmt.Stringer, like github.com/cockroachdb/apd
+//   - *gpb.Decimal128, which is already unscaled
+//
+// The digits beyond the scale are rounded half away from zero, and an error is returned
+// if the value does not fit in the precision.
+func BuildDecimal128(v any, ext *gpb.ColumnDataTypeExtension) (*gpb.Value, error) {
+	if d, ok := v.(*gpb.Decimal128); ok {
+		return &gpb.Value{ValueData: &gpb.Value_Decimal128Value{Decimal128Value: d}}, nil
+	}
+
+	precision, scale := types.DecimalPrecisionScale(ext)
+	unscaled, err := unscaledDecimal(v, scale)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := new(big.Int).Exp(ten, big.NewInt(int64(precision)), nil)
+	if new(big.Int).Abs(unscaled).Cmp(limit) >= 0 {
+		return nil, fmt.Errorf("value %#v overflows decimal(%d, %d)", v, precision, scale)
+	}
+
+	return &gpb.Value{ValueData: &gpb.Value_Decimal128Value{Decimal128Value: ToDecimal128(unscaled)}}, nil
+}
+
+// ToDecimal128 splits the unscaled integer into the high and low 64 bits of its two's
+// complement representation. The integer MUST fit in 128 bits.
+func ToDecimal128(unscaled *big.Int) *gpb.Decimal128 {
+	u := new(big.Int).Set(unscaled)
+	if u.Sign() < 0 {
+		u.Add(u, int128Of)
+	}
+
+	lo := new(big.Int).Mod(u, uint64Of).Uint64()
+	hi := new(big.Int).Rsh(u, 64).Uint64()
+	return &gpb.Decimal128{Hi: int64(hi), Lo: int64(lo)}
+}
+
+// FromDecimal128 is the reverse of ToDecimal128.
+func FromDecimal128(d *gpb.Decimal128) *big.Int {
+	v := new(big.Int).Lsh(big.NewInt(d.Hi), 64)
+	return v.Add(v, new(big.Int).SetUint64(uint64(d.Lo)))
+}
+
+func unscaledDecimal(v any, scale int32) (*big.Int, error) {
+	if d, ok := v.(decimalCoefficient); ok {
+		coefficient, exponent := d.Coefficient(), d.Exponent()+scale
+		if exponent >= 0 {
+			return new(big.Int).Mul(coefficient, pow10(exponent)), nil
+		}
+		return roundRat(new(big.Rat).SetFrac(coefficient, pow10(-exponent))), nil
+	}
+
+	r, err := decimalRat(v)
+	if err != nil {
+		return nil, err
+	}
+	return roundRat(r.Mul(r, new(big.Rat).SetInt(pow10(scale)))), nil
+}
+
+func decimalRat(v any) (*big.Rat, error) {
+	switch t := v.(type) {
+	case *big.Int:
+		return new(big.Rat).SetInt(t), nil
+	case big.Int:
+		return new(big.Rat).SetInt(&t), nil
+	case *big.Rat:
+		return new(big.Rat).Set(t), nil
+	case big.Rat:
+		return new(big.Rat).Set(&t), nil
+	case *big.Float:
+		if t.IsInf() {
+			return nil, fmt.Errorf("value %v is not a valid decimal", t)
+		}
+		r, _ := t.Rat(nil)
+		return r, nil
+	case big.Float:
+		return decimalRat(&t)
+	case float32:
+		return floatRat(float64(t), 32)
+	case *float32:
+		return floatRat(float64(*t), 32)
+	case float64:
+		return floatRat(t, 64)
+	case *float64:
+		return floatRat(*t, 64)
+	case string:
+		return stringRat(t)
+	case *string:
+		return stringRat(*t)
+	case encoding.TextMarshaler:
+		text, err := t.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return stringRat(string(text))
+	case fmt.Stringer:
+		return stringRat(t.String())
+	}
+
+	int32Pointer, int64Pointer, uint32Pointer, uint64Pointer, err := getIntPointer(v)
+	if err != nil {
+		return nil, fmt.Errorf(formatter+" Decimal", v, v)
+	}
+	if uint64Pointer != nil {
+		return new(big.Rat).SetUint64(*uint64Pointer), nil
+	}
+	if uint32Pointer != nil {
+		return new(big.Rat).SetUint64(uint64(*uint32Pointer)), nil
+	}
+	return new(big.Rat).SetInt64(getInt64Value(int32Pointer, int64Pointer, nil, nil)), nil
+}
+
+// floatRat uses the shortest decimal representation of the float, so 0.1 is exactly 0.1.
+func floatRat(f float64, bitSize int) (*big.Rat, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("value %v is not a valid decimal", f)
+	}
+	return stringRat(strconv.FormatFloat(f, 'g', -1, bitSize))
+}
+
+func stringRat(s string) (*big.Rat, error) {
+	s = strings.TrimSpace(s)
+	// big.Rat also accepts fractions like "1/3", which are not decimals
+	if strings.Contains(s, "/") {
+		return nil, fmt.Errorf("value %q is not a valid decimal", s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("value %q is not a valid decimal", s)
+	}
+	return r, nil
+}
+
+// roundRat rounds the rational number half away from zero.
+func roundRat(r *big.Rat) *big.Int {
+	quo, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if rem.Sign() == 0 {
+		return quo
+	}
+
+	if rem.Abs(rem).Lsh(rem, 1).Cmp(r.Denom()) >= 0 {
+		if r.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return quo
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(ten, big.NewInt(int64(n)), nil)
+}
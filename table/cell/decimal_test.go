@@ -0,0 +1,109 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cell
+
+import (
+	"math/big"
+	"testing"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+// coefficientDecimal mimics github.com/shopspring/decimal
+type coefficientDecimal struct {
+	coefficient int64
+	exponent    int32
+}
+
+func (d coefficientDecimal) Coefficient() *big.Int { return big.NewInt(d.coefficient) }
+func (d coefficientDecimal) Exponent() int32       { return d.exponent }
+
+type stringDecimal string
+
+func (d stringDecimal) String() string { return string(d) }
+
+func unscaledOf(t *testing.T, v any, precision, scale int32) string {
+	ext, err := types.DecimalExtension(precision, scale)
+	assert.Nil(t, err)
+
+	val, err := New(v, gpb.ColumnDataType_DECIMAL128).WithExtension(ext).Build()
+	if !assert.Nil(t, err) {
+		return ""
+	}
+	return FromDecimal128(val.GetDecimal128Value()).String()
+}
+
+func TestBuildDecimal128(t *testing.T) {
+	s := "-0.5"
+	f := 0.1
+
+	assert.Equal(t, "1234567", unscaledOf(t, "123.4567", 38, 4))
+	assert.Equal(t, "1235", unscaledOf(t, "123.45", 10, 1))
+	assert.Equal(t, "-1235", unscaledOf(t, "-123.45", 10, 1))
+	assert.Equal(t, "-5000", unscaledOf(t, &s, 38, 4))
+	assert.Equal(t, "12000", unscaledOf(t, "1.2e1", 38, 3))
+	assert.Equal(t, "42000", unscaledOf(t, 42, 38, 3))
+	assert.Equal(t, "18446744073709551615", unscaledOf(t, uint64(18446744073709551615), 38, 0))
+	assert.Equal(t, "1000", unscaledOf(t, f, 38, 4))
+	assert.Equal(t, "1000", unscaledOf(t, &f, 38, 4))
+	assert.Equal(t, "-7000", unscaledOf(t, big.NewInt(-7), 38, 3))
+	assert.Equal(t, "3333", unscaledOf(t, big.NewRat(1, 3), 38, 4))
+	assert.Equal(t, "2500", unscaledOf(t, big.NewFloat(0.25), 38, 4))
+	assert.Equal(t, "12345", unscaledOf(t, coefficientDecimal{coefficient: 12345, exponent: -2}, 38, 2))
+	assert.Equal(t, "123", unscaledOf(t, coefficientDecimal{coefficient: 12345, exponent: -2}, 38, 0))
+	assert.Equal(t, "1234500", unscaledOf(t, coefficientDecimal{coefficient: 12345, exponent: 2}, 38, 0))
+	assert.Equal(t, "990", unscaledOf(t, stringDecimal("9.9"), 38, 2))
+
+	max := "99999999999999999999999999999999999999"
+	assert.Equal(t, max, unscaledOf(t, max, 38, 0))
+	assert.Equal(t, "-"+max, unscaledOf(t, "-"+max, 38, 0))
+
+	// the default is decimal(38, 10)
+	val, err := New("1.5", gpb.ColumnDataType_DECIMAL128).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "15000000000", FromDecimal128(val.GetDecimal128Value()).String())
+}
+
+func TestBuildDecimal128Invalid(t *testing.T) {
+	ext, err := types.DecimalExtension(5, 2)
+	assert.Nil(t, err)
+
+	for _, v := range []any{"1000", "-1000", "abc", "1/3", true, []byte("1")} {
+		_, err := New(v, gpb.ColumnDataType_DECIMAL128).WithExtension(ext).Build()
+		assert.NotNil(t, err, "%#v", v)
+	}
+
+	_, err = types.DecimalExtension(39, 0)
+	assert.NotNil(t, err)
+	_, err = types.DecimalExtension(10, 11)
+	assert.NotNil(t, err)
+}
+
+func TestToDecimal128(t *testing.T) {
+	assert.Equal(t, &gpb.Decimal128{Hi: 0, Lo: 1}, ToDecimal128(big.NewInt(1)))
+	assert.Equal(t, &gpb.Decimal128{Hi: -1, Lo: -1}, ToDecimal128(big.NewInt(-1)))
+	assert.Equal(t, &gpb.Decimal128{Hi: 0, Lo: -1}, ToDecimal128(new(big.Int).SetUint64(1<<64-1)))
+	assert.Equal(t, &gpb.Decimal128{Hi: -1, Lo: 0}, ToDecimal128(new(big.Int).Neg(uint64Of)))
+
+	for _, s := range []string{"0", "1", "-1", "18446744073709551616", "-99999999999999999999999999999999999999"} {
+		v, _ := new(big.Int).SetString(s, 10)
+		assert.Equal(t, s, FromDecimal128(ToDecimal128(v)).String())
+	}
+}
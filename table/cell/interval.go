@@ -0,0 +1,115 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cell
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+const nanosecondsPerDay = int64(24 * time.Hour)
+
+func getInterval(v any) (*types.Interval, error) {
+	switch t := v.(type) {
+	case types.Interval:
+		return &t, nil
+	case *types.Interval:
+		return t, nil
+	case time.Duration:
+		val := types.IntervalOf(t)
+		return &val, nil
+	case *time.Duration:
+		val := types.IntervalOf(*t)
+		return &val, nil
+	case *gpb.IntervalMonthDayNano:
+		return &types.Interval{Months: t.Months, Days: t.Days, Nanoseconds: t.Nanoseconds}, nil
+	default:
+		return nil, fmt.Errorf(formatter+" types.Interval or time.Duration", t, v)
+	}
+}
+
+// BuildIntervalYearMonth accepts the number of months, or the Interval only with Months.
+func BuildIntervalYearMonth(v any) (*gpb.Value, error) {
+	var val int32
+	if interval, err := getInterval(v); err == nil {
+		if interval.Days != 0 || interval.Nanoseconds != 0 {
+			return nil, fmt.Errorf("interval %v can not be converted into INTERVAL_YEAR_MONTH exactly", interval)
+		}
+		val = interval.Months
+	} else {
+		int32Pointer, int64Pointer, uint32Pointer, uint64Pointer, err := getIntPointer(v)
+		if err != nil {
+			return nil, fmt.Errorf(formatter+" Interval or Integer", v, v)
+		}
+		if uint64Pointer != nil && *uint64Pointer > math.MaxInt32 {
+			return nil, fmt.Errorf("%d months overflows INTERVAL_YEAR_MONTH", *uint64Pointer)
+		}
+		months := getInt64Value(int32Pointer, int64Pointer, uint32Pointer, uint64Pointer)
+		if months != int64(int32(months)) {
+			return nil, fmt.Errorf("%d months overflows INTERVAL_YEAR_MONTH", months)
+		}
+		val = int32(months)
+	}
+
+	return &gpb.Value{ValueData: &gpb.Value_IntervalYearMonthValue{IntervalYearMonthValue: val}}, nil
+}
+
+// BuildIntervalDayTime accepts the Interval or time.Duration without Months, whose
+// precision is millisecond, or the integer of days in the high 32 bits and milliseconds
+// in the low 32 bits.
+func BuildIntervalDayTime(v any) (*gpb.Value, error) {
+	var val int64
+	if interval, err := getInterval(v); err == nil {
+		if interval.Months != 0 {
+			return nil, fmt.Errorf("interval %v can not be converted into INTERVAL_DAY_TIME exactly", interval)
+		}
+
+		days := int64(interval.Days) + interval.Nanoseconds/nanosecondsPerDay
+		milliseconds := interval.Nanoseconds % nanosecondsPerDay / int64(time.Millisecond)
+		if days != int64(int32(days)) {
+			return nil, fmt.Errorf("interval %v overflows INTERVAL_DAY_TIME", interval)
+		}
+		val = days<<32 | int64(uint32(int32(milliseconds)))
+	} else {
+		int32Pointer, int64Pointer, uint32Pointer, uint64Pointer, err := getIntPointer(v)
+		if err != nil {
+			return nil, fmt.Errorf(formatter+" Interval, time.Duration or Integer", v, v)
+		}
+		val = getInt64Value(int32Pointer, int64Pointer, uint32Pointer, uint64Pointer)
+	}
+
+	return &gpb.Value{ValueData: &gpb.Value_IntervalDayTimeValue{IntervalDayTimeValue: val}}, nil
+}
+
+// BuildIntervalMonthDayNano accepts the Interval or time.Duration.
+func BuildIntervalMonthDayNano(v any) (*gpb.Value, error) {
+	interval, err := getInterval(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gpb.Value{ValueData: &gpb.Value_IntervalMonthDayNanoValue{IntervalMonthDayNanoValue: &gpb.IntervalMonthDayNano{
+		Months:      interval.Months,
+		Days:        interval.Days,
+		Nanoseconds: interval.Nanoseconds,
+	}}}, nil
+}
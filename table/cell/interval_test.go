@@ -0,0 +1,86 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cell
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+func TestBuildIntervalYearMonth(t *testing.T) {
+	val, err := New(14, gpb.ColumnDataType_INTERVAL_YEAR_MONTH).Build()
+	assert.Nil(t, err)
+	assert.EqualValues(t, 14, val.GetIntervalYearMonthValue())
+
+	val, err = New(types.Interval{Months: -3}, gpb.ColumnDataType_INTERVAL_YEAR_MONTH).Build()
+	assert.Nil(t, err)
+	assert.EqualValues(t, -3, val.GetIntervalYearMonthValue())
+
+	_, err = New(types.Interval{Months: 1, Days: 1}, gpb.ColumnDataType_INTERVAL_YEAR_MONTH).Build()
+	assert.NotNil(t, err)
+	_, err = New(time.Hour, gpb.ColumnDataType_INTERVAL_YEAR_MONTH).Build()
+	assert.NotNil(t, err)
+	_, err = New("1 month", gpb.ColumnDataType_INTERVAL_YEAR_MONTH).Build()
+	assert.NotNil(t, err)
+
+	val, err = New(int64(math.MinInt32), gpb.ColumnDataType_INTERVAL_YEAR_MONTH).Build()
+	assert.Nil(t, err)
+	assert.EqualValues(t, math.MinInt32, val.GetIntervalYearMonthValue())
+	_, err = New(int64(math.MaxInt32)+1, gpb.ColumnDataType_INTERVAL_YEAR_MONTH).Build()
+	assert.ErrorContains(t, err, "overflows")
+	_, err = New(uint64(math.MaxUint64), gpb.ColumnDataType_INTERVAL_YEAR_MONTH).Build()
+	assert.ErrorContains(t, err, "overflows")
+}
+
+func TestBuildIntervalDayTime(t *testing.T) {
+	d := 49*time.Hour + 1500*time.Millisecond
+	val, err := New(&d, gpb.ColumnDataType_INTERVAL_DAY_TIME).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2)<<32|3601500, val.GetIntervalDayTimeValue())
+
+	val, err = New(types.Interval{Days: -1, Nanoseconds: -int64(5 * time.Millisecond)}, gpb.ColumnDataType_INTERVAL_DAY_TIME).Build()
+	assert.Nil(t, err)
+	v := val.GetIntervalDayTimeValue()
+	assert.EqualValues(t, -1, int32(v>>32))
+	assert.EqualValues(t, -5, int32(v))
+
+	val, err = New(int64(1)<<32, gpb.ColumnDataType_INTERVAL_DAY_TIME).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1)<<32, val.GetIntervalDayTimeValue())
+
+	_, err = New(types.Interval{Months: 1}, gpb.ColumnDataType_INTERVAL_DAY_TIME).Build()
+	assert.NotNil(t, err)
+}
+
+func TestBuildIntervalMonthDayNano(t *testing.T) {
+	val, err := New(types.Interval{Months: 1, Days: 2, Nanoseconds: 3}, gpb.ColumnDataType_INTERVAL_MONTH_DAY_NANO).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, &gpb.IntervalMonthDayNano{Months: 1, Days: 2, Nanoseconds: 3}, val.GetIntervalMonthDayNanoValue())
+
+	val, err = New(90*time.Minute, gpb.ColumnDataType_INTERVAL_MONTH_DAY_NANO).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, &gpb.IntervalMonthDayNano{Nanoseconds: int64(90 * time.Minute)}, val.GetIntervalMonthDayNanoValue())
+
+	_, err = New(1, gpb.ColumnDataType_INTERVAL_MONTH_DAY_NANO).Build()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cell
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// The binary JSONB format of GreptimeDB. Every container starts with a header of the
+// container type and the number of its elements, followed by the entries of the
+// elements, which are the type and the length of the data, followed by the data.
+const (
+	jsonbArrayContainer  uint32 = 0x80000000
+	jsonbObjectContainer uint32 = 0x40000000
+	jsonbScalarContainer uint32 = 0x20000000
+
+	jsonbNullEntry      uint32 = 0x00000000
+	jsonbStringEntry    uint32 = 0x10000000
+	jsonbNumberEntry    uint32 = 0x20000000
+	jsonbFalseEntry     uint32 = 0x30000000
+	jsonbTrueEntry      uint32 = 0x40000000
+	jsonbContainerEntry uint32 = 0x50000000
+
+	jsonbMaxLength = 0x0FFFFFFF
+
+	jsonbNumberZero  byte = 0x00
+	jsonbNumberInt   byte = 0x40
+	jsonbNumberUint  byte = 0x50
+	jsonbNumberFloat byte = 0x60
+)
+
+// EncodeJSONB encodes the JSON text into the binary JSONB format.
+func EncodeJSONB(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid JSON %q: unexpected data after the value", data)
+	}
+
+	entry, encoded, err := encodeJSONBValue(v)
+	if err != nil {
+		return nil, err
+	}
+	if entry == jsonbContainerEntry {
+		return encoded, nil
+	}
+
+	buf := make([]byte, 0, 8+len(encoded))
+	buf = binary.BigEndian.AppendUint32(buf, jsonbScalarContainer|1)
+	buf = binary.BigEndian.AppendUint32(buf, entry|uint32(len(encoded)))
+	return append(buf, encoded...), nil
+}
+
+// encodeJSONBValue returns the entry type and the data of the value.
+func encodeJSONBValue(v any) (uint32, []byte, error) {
+	switch t := v.(type) {
+	case nil:
+		return jsonbNullEntry, nil, nil
+	case bool:
+		if t {
+			return jsonbTrueEntry, nil, nil
+		}
+		return jsonbFalseEntry, nil, nil
+	case string:
+		return jsonbStringEntry, []byte(t), nil
+	case json.Number:
+		data, err := encodeJSONBNumber(t)
+		return jsonbNumberEntry, data, err
+	case []any:
+		data, err := encodeJSONBArray(t)
+		return jsonbContainerEntry, data, err
+	case map[string]any:
+		data, err := encodeJSONBObject(t)
+		return jsonbContainerEntry, data, err
+	default:
+		return 0, nil, fmt.Errorf("unsupported JSON value %#v", v)
+	}
+}
+
+func encodeJSONBNumber(n json.Number) ([]byte, error) {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		switch {
+		case i == 0:
+			return []byte{jsonbNumberZero}, nil
+		case i >= math.MinInt8 && i <= math.MaxInt8:
+			return []byte{jsonbNumberInt | 1, byte(int8(i))}, nil
+		case i >= math.MinInt16 && i <= math.MaxInt16:
+			return binary.BigEndian.AppendUint16([]byte{jsonbNumberInt | 2}, uint16(int16(i))), nil
+		case i >= math.MinInt32 && i <= math.MaxInt32:
+			return binary.BigEndian.AppendUint32([]byte{jsonbNumberInt | 4}, uint32(int32(i))), nil
+		default:
+			return binary.BigEndian.AppendUint64([]byte{jsonbNumberInt | 8}, uint64(i)), nil
+		}
+	}
+
+	// only the integers beyond int64 are here
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return binary.BigEndian.AppendUint64([]byte{jsonbNumberUint | 8}, u), nil
+	}
+
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON number %q", n)
+	}
+	return binary.BigEndian.AppendUint64([]byte{jsonbNumberFloat}, math.Float64bits(f)), nil
+}
+
+func encodeJSONBArray(items []any) ([]byte, error) {
+	entries := make([]uint32, 0, len(items))
+	data := make([][]byte, 0, len(items))
+	for _, item := range items {
+		entry, encoded, err := encodeJSONBValue(item)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+		data = append(data, encoded)
+	}
+	return jsonbContainer(jsonbArrayContainer, len(items), entries, data)
+}
+
+// encodeJSONBObject puts all the keys ahead of the values, and the keys are sorted.
+func encodeJSONBObject(object map[string]any) ([]byte, error) {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := make([]uint32, 0, 2*len(keys))
+	data := make([][]byte, 0, 2*len(keys))
+	for _, key := range keys {
+		entries = append(entries, jsonbStringEntry)
+		data = append(data, []byte(key))
+	}
+	for _, key := range keys {
+		entry, encoded, err := encodeJSONBValue(object[key])
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+		data = append(data, encoded)
+	}
+	return jsonbContainer(jsonbObjectContainer, len(keys), entries, data)
+}
+
+func jsonbContainer(container uint32, length int, entries []uint32, data [][]byte) ([]byte, error) {
+	if length > jsonbMaxLength {
+		return nil, fmt.Errorf("JSON container with %d elements is too large", length)
+	}
+
+	size := 4 + 4*len(entries)
+	for _, d := range data {
+		if len(d) > jsonbMaxLength {
+			return nil, fmt.Errorf("JSON value with %d bytes is too large", len(d))
+		}
+		size += len(d)
+	}
+
+	buf := make([]byte, 0, size)
+	buf = binary.BigEndian.AppendUint32(buf, container|uint32(length))
+	for i, entry := range entries {
+		buf = binary.BigEndian.AppendUint32(buf, entry|uint32(len(data[i])))
+	}
+	for _, d := range data {
+		buf = append(buf, d...)
+	}
+	return buf, nil
+}
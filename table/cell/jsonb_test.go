@@ -0,0 +1,87 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cell
+
+import (
+	"testing"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+func TestEncodeJSONB(t *testing.T) {
+	cases := map[string][]byte{
+		`true`: {0x20, 0, 0, 1, 0x40, 0, 0, 0},
+		`null`: {0x20, 0, 0, 1, 0, 0, 0, 0},
+		`0`:    {0x20, 0, 0, 1, 0x20, 0, 0, 1, 0x00},
+		`1`:    {0x20, 0, 0, 1, 0x20, 0, 0, 2, 0x41, 1},
+		`-300`: {0x20, 0, 0, 1, 0x20, 0, 0, 3, 0x42, 0xfe, 0xd4},
+		`"ab"`: {0x20, 0, 0, 1, 0x10, 0, 0, 2, 'a', 'b'},
+		`1.5`:  {0x20, 0, 0, 1, 0x20, 0, 0, 9, 0x60, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0},
+		`[null,"a"]`: {
+			0x80, 0, 0, 2,
+			0, 0, 0, 0,
+			0x10, 0, 0, 1,
+			'a',
+		},
+		`{"b":1,"a":[]}`: {
+			0x40, 0, 0, 2,
+			0x10, 0, 0, 1, // key a
+			0x10, 0, 0, 1, // key b
+			0x50, 0, 0, 4, // value of a
+			0x20, 0, 0, 2, // value of b
+			'a', 'b',
+			0x80, 0, 0, 0,
+			0x41, 1,
+		},
+		`18446744073709551615`: {0x20, 0, 0, 1, 0x20, 0, 0, 9, 0x58, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	}
+
+	for text, expected := range cases {
+		encoded, err := EncodeJSONB([]byte(text))
+		assert.Nil(t, err, text)
+		assert.Equal(t, expected, encoded, text)
+	}
+
+	for _, text := range []string{``, `{`, `{} {}`, `[1,]`} {
+		_, err := EncodeJSONB([]byte(text))
+		assert.NotNil(t, err, text)
+	}
+}
+
+func TestBuildJSONB(t *testing.T) {
+	ext := types.JSONBinaryExtension()
+
+	val, err := New(map[string]any{"a": 1}, gpb.ColumnDataType_BINARY).WithExtension(ext).Build()
+	assert.Nil(t, err)
+	expected, _ := EncodeJSONB([]byte(`{"a":1}`))
+	assert.Equal(t, expected, val.GetBinaryValue())
+
+	val, err = New(`{"a":1}`, gpb.ColumnDataType_BINARY).WithExtension(ext).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, val.GetBinaryValue())
+
+	_, err = New(`{"a":`, gpb.ColumnDataType_BINARY).WithExtension(ext).Build()
+	assert.NotNil(t, err)
+
+	// the binary column without extension is not JSON
+	val, err = New([]byte(`{"a":1}`), gpb.ColumnDataType_BINARY).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte(`{"a":1}`), val.GetBinaryValue())
+}
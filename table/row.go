@@ -0,0 +1,170 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package table
+
+import (
+	"fmt"
+	"sort"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/cell"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+// Row sets the values of a row by column names, and the columns not set are null.
+// Call Table.NewRow() to create it, and Add() to add it into the table.
+//
+//	err := tbl.NewRow().
+//	    Set("host", "127.0.0.1").
+//	    Set("cpu", 0.5).
+//	    Set("ts", time.Now()).
+//	    Add()
+type Row struct {
+	table  *Table
+	names  []string
+	values []any
+}
+
+// NewRow creates an empty row of the table.
+func (t *Table) NewRow() *Row {
+	return &Row{table: t}
+}
+
+// Set sets the value of the column. If the column is set more than once, the last
+// value wins. The errors, like the unknown column, are returned by Add().
+func (r *Row) Set(column string, v any) *Row {
+	r.names = append(r.names, column)
+	r.values = append(r.values, v)
+	return r
+}
+
+// Add adds the row into the table. The table is not changed if it fails.
+func (r *Row) Add() error {
+	return r.table.addNamedRow(r.names, r.values)
+}
+
+// AddRowMap is like AddRow(), but the values are matched to the columns by names, and
+// the columns not in values are null. The unknown columns are rejected, unless
+// WithAutoAddColumns(true) is set.
+//
+//	err := tbl.AddRowMap(map[string]any{"host": "127.0.0.1", "ts": time.Now()})
+func (t *Table) AddRowMap(values map[string]any) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	// the columns auto added are in the order of names
+	sort.Strings(names)
+
+	inputs := make([]any, len(names))
+	for i, name := range names {
+		inputs[i] = values[name]
+	}
+	return t.addNamedRow(names, inputs)
+}
+
+func (t *Table) addNamedRow(names []string, inputs []any) error {
+	if t.IsColumnEmpty() && !t.autoAddColumns {
+		return errs.ErrEmptyColumn
+	}
+
+	indexes := make(map[string]int, len(t.columnsSchema))
+	for i, column := range t.columnsSchema {
+		indexes[column.ColumnName] = i
+	}
+
+	var added []*gpb.ColumnSchema
+	values := make([]*gpb.Value, len(t.columnsSchema))
+	for i, name := range names {
+		name, err := t.sanitate_if_needed(name)
+		if err != nil {
+			return err
+		}
+
+		idx, ok := indexes[name]
+		if !ok {
+			if !t.autoAddColumns {
+				return fmt.Errorf("%w %q", errs.ErrUnknownColumn, name)
+			}
+			if inputs[i] == nil {
+				continue
+			}
+			column, err := inferColumn(name, inputs[i])
+			if err != nil {
+				return err
+			}
+			idx = len(values)
+			indexes[name] = idx
+			added = append(added, column)
+			values = append(values, nil)
+		}
+
+		var column *gpb.ColumnSchema
+		if idx < len(t.columnsSchema) {
+			column = t.columnsSchema[idx]
+		} else {
+			column = added[idx-len(t.columnsSchema)]
+		}
+		val, err := cell.New(inputs[i], column.Datatype).WithExtension(column.DatatypeExtension).Build()
+		if err != nil {
+			return fmt.Errorf("failed to set column %q: %w", name, err)
+		}
+		values[idx] = val
+	}
+
+	for i, val := range values {
+		if val == nil {
+			values[i] = &gpb.Value{}
+		}
+	}
+
+	// add the row before the columns, so the table is not changed if it fails
+	if err := t.addRow(&gpb.Row{Values: values}); err != nil {
+		return err
+	}
+
+	if len(added) > 0 {
+		t.columnsSchema = append(t.columnsSchema, added...)
+		rows := t.rows.Rows
+		for _, row := range rows[:len(rows)-1] {
+			for range added {
+				row.Values = append(row.Values, &gpb.Value{})
+			}
+		}
+		t.rows.Schema = t.columnsSchema
+	}
+	return nil
+}
+
+// inferColumn creates the field column of the type inferred from v.
+func inferColumn(name string, v any) (*gpb.ColumnSchema, error) {
+	type_, err := types.InferColumnType(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add column %q: %w", name, err)
+	}
+	dataType, err := types.ConvertType(type_)
+	if err != nil {
+		return nil, err
+	}
+	return &gpb.ColumnSchema{
+		ColumnName:   name,
+		SemanticType: gpb.SemanticType_FIELD,
+		Datatype:     dataType,
+	}, nil
+}
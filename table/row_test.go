@@ -0,0 +1,121 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package table
+
+import (
+	"testing"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+func newRowTable(t *testing.T) *Table {
+	tbl, err := New("monitor")
+	assert.Nil(t, err)
+	assert.Nil(t, tbl.AddTagColumn("host", types.STRING))
+	assert.Nil(t, tbl.AddFieldColumn("cpu", types.FLOAT64))
+	assert.Nil(t, tbl.AddFieldColumn("memory", types.UINT64))
+	assert.Nil(t, tbl.AddTimestampColumn("ts", types.TIMESTAMP_MILLISECOND))
+	return tbl
+}
+
+func TestAddRowMap(t *testing.T) {
+	ts := time.Now()
+	tbl := newRowTable(t)
+
+	assert.Nil(t, tbl.AddRowMap(map[string]any{"ts": ts, "Host": "127.0.0.1", "cpu": 0.5}))
+	assert.Nil(t, tbl.NewRow().Set("memory", 1).Set("ts", ts).Set("memory", 2).Add())
+
+	rows := tbl.GetRows().Rows
+	assert.Len(t, rows, 2)
+
+	first := rows[0].Values
+	assert.Len(t, first, 4)
+	assert.Equal(t, "127.0.0.1", first[0].GetStringValue())
+	assert.Equal(t, 0.5, first[1].GetF64Value())
+	assert.Nil(t, first[2].GetValueData())
+	assert.Equal(t, ts.UnixMilli(), first[3].GetTimestampMillisecondValue())
+
+	second := rows[1].Values
+	assert.Nil(t, second[0].GetValueData())
+	assert.Nil(t, second[1].GetValueData())
+	assert.Equal(t, uint64(2), second[2].GetU64Value())
+}
+
+func TestAddRowMapInvalid(t *testing.T) {
+	tbl := newRowTable(t)
+
+	err := tbl.AddRowMap(map[string]any{"host": "127.0.0.1", "disk": 1})
+	assert.ErrorIs(t, err, errs.ErrUnknownColumn)
+	assert.ErrorContains(t, err, `"disk"`)
+
+	assert.NotNil(t, tbl.NewRow().Set("cpu", "high").Add())
+	assert.True(t, tbl.IsRowEmpty())
+
+	empty, err := New("monitor")
+	assert.Nil(t, err)
+	assert.ErrorIs(t, empty.AddRowMap(map[string]any{"cpu": 1.0}), errs.ErrEmptyColumn)
+}
+
+func TestAddRowMapAutoAddColumns(t *testing.T) {
+	ts := time.Now()
+	tbl := newRowTable(t).WithAutoAddColumns(true)
+
+	assert.Nil(t, tbl.AddRowMap(map[string]any{"host": "127.0.0.1", "ts": ts}))
+	assert.Nil(t, tbl.AddRowMap(map[string]any{
+		"host":    "127.0.0.2",
+		"ts":      ts,
+		"running": true,
+		"disk":    int32(3),
+		"labels":  map[string]any{"region": "us"},
+		"unknown": nil,
+	}))
+
+	// a row can not be added if any value is invalid, and no column is added
+	assert.NotNil(t, tbl.AddRowMap(map[string]any{"ts": ts, "load": 1.0, "ch": make(chan int)}))
+
+	columns := tbl.GetColumnsSchema()
+	assert.Len(t, columns, 7)
+	assert.Equal(t, "disk", columns[4].ColumnName)
+	assert.Equal(t, gpb.ColumnDataType_INT32, columns[4].Datatype)
+	assert.Equal(t, gpb.SemanticType_FIELD, columns[4].SemanticType)
+	assert.Equal(t, "labels", columns[5].ColumnName)
+	assert.Equal(t, gpb.ColumnDataType_JSON, columns[5].Datatype)
+	assert.Equal(t, "running", columns[6].ColumnName)
+	assert.Equal(t, gpb.ColumnDataType_BOOLEAN, columns[6].Datatype)
+
+	rows := tbl.GetRows()
+	assert.Len(t, rows.Schema, 7)
+	assert.Len(t, rows.Rows[0].Values, 7)
+	assert.Nil(t, rows.Rows[0].Values[6].GetValueData())
+
+	second := rows.Rows[1].Values
+	assert.Len(t, second, 7)
+	assert.Equal(t, int32(3), second[4].GetI32Value())
+	assert.Equal(t, `{"region":"us"}`, second[5].GetStringValue())
+	assert.True(t, second[6].GetBoolValue())
+
+	// all the columns can be auto added into the empty table
+	empty, err := New("monitor")
+	assert.Nil(t, err)
+	assert.Nil(t, empty.WithAutoAddColumns(true).NewRow().Set("cpu", 1.0).Add())
+	assert.Equal(t, gpb.ColumnDataType_FLOAT64, empty.GetColumnsSchema()[0].Datatype)
+}
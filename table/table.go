@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
 	"github.com/GreptimeTeam/greptimedb-ingester-go/table/cell"
@@ -30,7 +31,7 @@ import (
 // Table is a struct that holds the table name, columns, and rows.
 // Call New() to create a new table.
 // then Call AddTagColumn(), AddFieldColumn() or AddTimestampColumn() to add columns.
-// then Call AddRow() to add rows.
+// then Call AddRow() to add rows, or AddRowMap() and NewRow() to add rows by column names.
 //
 // NOTE: column counts MUST match the number of inputs in AddRow()
 type Table struct {
@@ -42,14 +43,62 @@ type Table struct {
 	// sanitate_needed indicates if sanitate table and column name to snake and lower case
 	// Default is true.
 	sanitate_needed bool
+
+	// autoAddColumns indicates if AddRowMap() and Row add the unknown columns as fields.
+	// Default is false.
+	autoAddColumns bool
+
+	// builder is the Builder which built the table, see ColumnValues.
+	builder *Builder
 }
 
 func New(name string) (*Table, error) {
 	return &Table{name: name, sanitate_needed: true}, nil
 }
 
-func (t *Table) addColumn(name string, semanticType gpb.SemanticType, dataType gpb.ColumnDataType) error {
-	name, err := t.sanitate_if_needed(name)
+// ColumnOption customizes the column added by AddTagColumn(), AddFieldColumn() or AddTimestampColumn().
+type ColumnOption func(column *gpb.ColumnSchema) error
+
+// WithDecimal sets the precision and scale of the DECIMAL128 column. The default is
+// decimal(38, 10).
+//
+//	tbl.AddFieldColumn("price", types.DECIMAL128, table.WithDecimal(38, 4))
+func WithDecimal(precision, scale int32) ColumnOption {
+	return func(column *gpb.ColumnSchema) error {
+		if column.Datatype != gpb.ColumnDataType_DECIMAL128 {
+			return fmt.Errorf("column %q of %v does not support decimal precision and scale", column.ColumnName, column.Datatype)
+		}
+
+		ext, err := types.DecimalExtension(precision, scale)
+		if err != nil {
+			return err
+		}
+		column.DatatypeExtension = ext
+		return nil
+	}
+}
+
+// WithJSONBinary encodes the values of the JSON column in the binary JSONB format,
+// which is the same as the column type JSONB.
+func WithJSONBinary() ColumnOption {
+	return func(column *gpb.ColumnSchema) error {
+		if column.Datatype != gpb.ColumnDataType_JSON && !types.IsJSONBinary(column.DatatypeExtension) {
+			return fmt.Errorf("column %q of %v does not support JSON binary encoding", column.ColumnName, column.Datatype)
+		}
+
+		column.Datatype = gpb.ColumnDataType_BINARY
+		column.DatatypeExtension = types.JSONBinaryExtension()
+		return nil
+	}
+}
+
+func (t *Table) addColumn(name string, semanticType gpb.SemanticType, type_ types.ColumnType, opts ...ColumnOption) error {
+	dataType, err := types.ConvertType(type_)
+	if err != nil {
+		return err
+	}
+
+	name, err = t.sanitate_if_needed(name)
 	if err != nil {
 		return err
 	}
@@ -63,6 +112,16 @@ func (t *Table) addColumn(name string, semanticType gpb.SemanticType, dataType g
 		SemanticType: semanticType,
 		Datatype:     dataType,
 	}
+	if dataType == gpb.ColumnDataType_DECIMAL128 {
+		column.DatatypeExtension, _ = types.DecimalExtension(types.DefaultDecimalPrecision, types.DefaultDecimalScale)
+	} else if type_ == types.JSONB {
+		column.DatatypeExtension = types.JSONBinaryExtension()
+	}
+	for _, opt := range opts {
+		if err := opt(column); err != nil {
+			return err
+		}
+	}
 	t.columnsSchema = append(t.columnsSchema, column)
 
 	return nil
@@ -72,39 +131,24 @@ func (t *Table) addColumn(name string, semanticType gpb.SemanticType, dataType g
 // [Data Model].
 //
 // [Data Model]: https://docs.greptime.com/user-guide/concepts/data-model
-func (t *Table) AddTagColumn(name string, type_ types.ColumnType) error {
-	typ, err := types.ConvertType(type_)
-	if err != nil {
-		return err
-	}
-
-	return t.addColumn(name, gpb.SemanticType_TAG, typ)
+func (t *Table) AddTagColumn(name string, type_ types.ColumnType, opts ...ColumnOption) error {
+	return t.addColumn(name, gpb.SemanticType_TAG, type_, opts...)
 }
 
 // AddFieldColumn helps to add the field column. You can find details in
 // [Data Model].
 //
 // [Data Model]: https://docs.greptime.com/user-guide/concepts/data-model
-func (t *Table) AddFieldColumn(name string, type_ types.ColumnType) error {
-	typ, err := types.ConvertType(type_)
-	if err != nil {
-		return err
-	}
-
-	return t.addColumn(name, gpb.SemanticType_FIELD, typ)
+func (t *Table) AddFieldColumn(name string, type_ types.ColumnType, opts ...ColumnOption) error {
+	return t.addColumn(name, gpb.SemanticType_FIELD, type_, opts...)
 }
 
 // AddTimestampColumn helps to add the timestamp column. A table can only
 // have one timestamp column. You can find details in [Data Model].
 //
 // [Data Model]: https://docs.greptime.com/user-guide/concepts/data-model
-func (t *Table) AddTimestampColumn(name string, type_ types.ColumnType) error {
-	typ, err := types.ConvertType(type_)
-	if err != nil {
-		return err
-	}
-
-	return t.addColumn(name, gpb.SemanticType_TIMESTAMP, typ)
+func (t *Table) AddTimestampColumn(name string, type_ types.ColumnType, opts ...ColumnOption) error {
+	return t.addColumn(name, gpb.SemanticType_TIMESTAMP, type_, opts...)
 }
 
 func (t *Table) addRow(row *gpb.Row) error {
@@ -149,8 +193,8 @@ func (t *Table) AddRow(inputs ...any) error {
 	}
 
 	for i, input := range inputs {
-		dataType := t.columnsSchema[i].Datatype
-		val, err := cell.New(input, dataType).Build()
+		column := t.columnsSchema[i]
+		val, err := cell.New(input, column.Datatype).WithExtension(column.DatatypeExtension).Build()
 		if err != nil {
 			return err
 		}
@@ -178,6 +222,15 @@ func (t *Table) WithSanitate(sanitate_needed bool) *Table {
 	return t
 }
 
+// WithAutoAddColumns to change how AddRowMap() and Row treat the unknown columns. Default
+// is false, and the unknown columns are rejected. If true, they are added as field columns
+// with the types inferred by types.InferColumnType(), and the rows already added are
+// filled with null for them.
+func (t *Table) WithAutoAddColumns(autoAdd bool) *Table {
+	t.autoAddColumns = autoAdd
+	return t
+}
+
 func (t *Table) WithColumnsSchema(columnsSchema []*gpb.ColumnSchema) *Table {
 	t.columnsSchema = columnsSchema
 	return t
@@ -192,6 +245,12 @@ func (t *Table) GetName() (string, error) {
 	return t.sanitate_if_needed(t.name)
 }
 
+// GetColumnsSchema returns the columns defined by AddTagColumn(), AddFieldColumn(),
+// AddTimestampColumn() or WithColumnsSchema().
+func (t *Table) GetColumnsSchema() []*gpb.ColumnSchema {
+	return t.columnsSchema
+}
+
 func (t *Table) GetRows() *gpb.Rows {
 	if t.rows != nil && t.rows.Schema == nil {
 		t.rows.Schema = t.columnsSchema
@@ -199,6 +258,89 @@ func (t *Table) GetRows() *gpb.Rows {
 	return t.rows
 }
 
+// RowCount returns the number of rows added so far.
+func (t *Table) RowCount() int {
+	if t.rows == nil {
+		return 0
+	}
+	return len(t.rows.Rows)
+}
+
+// Merge appends the rows of other into t. Columns are matched by name, so the
+// column order of other does not matter. Columns only known by other are appended
+// to the schema of t, and the rows already in t are filled with null for them.
+//
+// NOTE: the same column MUST have the same semantic type and data type in both tables.
+func (t *Table) Merge(other *Table) error {
+	if other == nil || other.IsRowEmpty() {
+		return nil
+	}
+
+	indexes := make(map[string]int, len(t.columnsSchema))
+	for i, column := range t.columnsSchema {
+		indexes[column.ColumnName] = i
+	}
+
+	// check all the columns before t is modified, so t is unchanged on conflicts
+	mapping := make([]int, len(other.columnsSchema))
+	added := make([]*gpb.ColumnSchema, 0)
+	for i, column := range other.columnsSchema {
+		idx, ok := indexes[column.ColumnName]
+		if !ok {
+			idx = len(t.columnsSchema) + len(added)
+			indexes[column.ColumnName] = idx
+			added = append(added, column)
+			mapping[i] = idx
+			continue
+		}
+
+		var existing *gpb.ColumnSchema
+		if idx < len(t.columnsSchema) {
+			existing = t.columnsSchema[idx]
+		} else {
+			existing = added[idx-len(t.columnsSchema)]
+		}
+		if existing.SemanticType != column.SemanticType || existing.Datatype != column.Datatype ||
+			!proto.Equal(existing.DatatypeExtension, column.DatatypeExtension) {
+			return fmt.Errorf("column %q conflicts: %v %v vs %v %v", column.ColumnName,
+				existing.SemanticType, existing.Datatype, column.SemanticType, column.Datatype)
+		}
+		mapping[i] = idx
+	}
+
+	t.columnsSchema = append(t.columnsSchema, added...)
+	if len(added) > 0 && t.rows != nil {
+		for _, row := range t.rows.Rows {
+			for range added {
+				row.Values = append(row.Values, &gpb.Value{})
+			}
+		}
+	}
+
+	width := len(t.columnsSchema)
+	for _, row := range other.rows.Rows {
+		values := make([]*gpb.Value, width)
+		for i, val := range row.Values {
+			if i < len(mapping) {
+				values[mapping[i]] = val
+			}
+		}
+		for i, val := range values {
+			if val == nil {
+				values[i] = &gpb.Value{}
+			}
+		}
+		if err := t.addRow(&gpb.Row{Values: values}); err != nil {
+			return err
+		}
+	}
+
+	if t.rows != nil {
+		t.rows.Schema = t.columnsSchema
+	}
+	return nil
+}
+
 func (t *Table) sanitate_if_needed(name string) (string, error) {
 	if t.sanitate_needed {
 		return util.SanitateName(name)
@@ -237,3 +379,54 @@ func (t *Table) ToDeleteRequest() (*gpb.RowDeleteRequest, error) {
 		Rows:      t.GetRows(),
 	}, nil
 }
+
+// ToColumnDefs converts the columns into the column definitions of DDL.
+// Only the timestamp column is not nullable.
+func (t *Table) ToColumnDefs() []*gpb.ColumnDef {
+	defs := make([]*gpb.ColumnDef, 0, len(t.columnsSchema))
+	for _, column := range t.columnsSchema {
+		defs = append(defs, &gpb.ColumnDef{
+			Name:              column.ColumnName,
+			DataType:          column.Datatype,
+			IsNullable:        column.SemanticType != gpb.SemanticType_TIMESTAMP,
+			SemanticType:      column.SemanticType,
+			DatatypeExtension: column.DatatypeExtension,
+			Options:           column.Options,
+		})
+	}
+	return defs
+}
+
+// ToCreateTableExpr converts the columns into the expr to create the table. The tag
+// columns are the primary keys, and there MUST be exactly one timestamp column.
+func (t *Table) ToCreateTableExpr() (*gpb.CreateTableExpr, error) {
+	if t.IsColumnEmpty() {
+		return nil, errs.ErrEmptyColumn
+	}
+
+	name, err := t.GetName()
+	if err != nil {
+		return nil, err
+	}
+
+	expr := &gpb.CreateTableExpr{
+		TableName:  name,
+		ColumnDefs: t.ToColumnDefs(),
+	}
+	for _, column := range t.columnsSchema {
+		switch column.SemanticType {
+		case gpb.SemanticType_TAG:
+			expr.PrimaryKeys = append(expr.PrimaryKeys, column.ColumnName)
+		case gpb.SemanticType_TIMESTAMP:
+			if expr.TimeIndex != "" {
+				return nil, errs.ErrMultipleTimeIndex
+			}
+			expr.TimeIndex = column.ColumnName
+		}
+	}
+
+	if expr.TimeIndex == "" {
+		return nil, errs.ErrEmptyTimeIndex
+	}
+	return expr, nil
+}
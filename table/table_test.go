@@ -0,0 +1,208 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package table
+
+import (
+	"testing"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/greptimedb-ingester-go/errs"
+	"github.com/GreptimeTeam/greptimedb-ingester-go/table/types"
+)
+
+func TestMerge(t *testing.T) {
+	ts := time.Now()
+
+	tbl1, err := New("monitor")
+	assert.Nil(t, err)
+	assert.Nil(t, tbl1.AddTagColumn("host", types.STRING))
+	assert.Nil(t, tbl1.AddFieldColumn("cpu", types.FLOAT64))
+	assert.Nil(t, tbl1.AddTimestampColumn("ts", types.TIMESTAMP_MILLISECOND))
+	assert.Nil(t, tbl1.AddRow("127.0.0.1", 1.0, ts))
+
+	// different column order, and a new column
+	tbl2, err := New("monitor")
+	assert.Nil(t, err)
+	assert.Nil(t, tbl2.AddTimestampColumn("ts", types.TIMESTAMP_MILLISECOND))
+	assert.Nil(t, tbl2.AddFieldColumn("memory", types.UINT64))
+	assert.Nil(t, tbl2.AddTagColumn("host", types.STRING))
+	assert.Nil(t, tbl2.AddRow(ts, uint64(2), "127.0.0.2"))
+
+	merged, err := New("monitor")
+	assert.Nil(t, err)
+	assert.Nil(t, merged.Merge(tbl1))
+	assert.Nil(t, merged.Merge(tbl2))
+
+	assert.Equal(t, 2, merged.RowCount())
+	assert.Len(t, merged.GetColumnsSchema(), 4)
+	assert.Equal(t, "memory", merged.GetColumnsSchema()[3].ColumnName)
+
+	rows := merged.GetRows()
+	assert.Len(t, rows.Schema, 4)
+
+	first := rows.Rows[0].Values
+	assert.Len(t, first, 4)
+	assert.Equal(t, "127.0.0.1", first[0].GetStringValue())
+	assert.Equal(t, 1.0, first[1].GetF64Value())
+	assert.Nil(t, first[3].GetValueData())
+
+	second := rows.Rows[1].Values
+	assert.Len(t, second, 4)
+	assert.Equal(t, "127.0.0.2", second[0].GetStringValue())
+	assert.Nil(t, second[1].GetValueData())
+	assert.Equal(t, ts.UnixMilli(), second[2].GetTimestampMillisecondValue())
+	assert.Equal(t, uint64(2), second[3].GetU64Value())
+
+	// the source tables are not modified
+	assert.Len(t, tbl1.GetRows().Rows[0].Values, 3)
+}
+
+func TestMergeConflict(t *testing.T) {
+	tbl1, err := New("monitor")
+	assert.Nil(t, err)
+	assert.Nil(t, tbl1.AddFieldColumn("cpu", types.FLOAT64))
+	assert.Nil(t, tbl1.AddRow(1.0))
+
+	tbl2, err := New("monitor")
+	assert.Nil(t, err)
+	assert.Nil(t, tbl2.AddFieldColumn("cpu", types.INT64))
+	assert.Nil(t, tbl2.AddRow(1))
+
+	merged, err := New("monitor")
+	assert.Nil(t, err)
+	assert.Nil(t, merged.Merge(tbl1))
+	assert.NotNil(t, merged.Merge(tbl2))
+
+	assert.Equal(t, gpb.ColumnDataType_FLOAT64, merged.GetColumnsSchema()[0].Datatype)
+}
+
+func TestMergeConflictUnchanged(t *testing.T) {
+	tbl, err := New("monitor")
+	assert.Nil(t, err)
+	assert.Nil(t, tbl.AddFieldColumn("cpu", types.FLOAT64))
+	assert.Nil(t, tbl.AddRow(1.0))
+
+	// the first column is new, and the second one conflicts
+	other, err := New("monitor")
+	assert.Nil(t, err)
+	assert.Nil(t, other.AddFieldColumn("memory", types.UINT64))
+	assert.Nil(t, other.AddFieldColumn("cpu", types.INT64))
+	assert.Nil(t, other.AddRow(uint64(1), 1))
+
+	assert.NotNil(t, tbl.Merge(other))
+	assert.Len(t, tbl.GetColumnsSchema(), 1)
+	assert.Equal(t, 1, tbl.RowCount())
+	assert.Len(t, tbl.GetRows().Rows[0].Values, 1)
+
+	// the table is still usable
+	merged, err := New("monitor")
+	assert.Nil(t, err)
+	assert.Nil(t, merged.AddFieldColumn("cpu", types.FLOAT64))
+	assert.Nil(t, merged.AddRow(2.0))
+	assert.Nil(t, tbl.Merge(merged))
+	assert.Equal(t, 2, tbl.RowCount())
+}
+
+func TestDecimalColumn(t *testing.T) {
+	tbl, err := New("bill")
+	assert.Nil(t, err)
+	assert.Nil(t, tbl.AddFieldColumn("price", types.DECIMAL128, WithDecimal(38, 4)))
+	assert.Nil(t, tbl.AddFieldColumn("total", types.DECIMAL128))
+	assert.NotNil(t, tbl.AddFieldColumn("cpu", types.FLOAT64, WithDecimal(38, 4)))
+	assert.NotNil(t, tbl.AddFieldColumn("amount", types.DECIMAL128, WithDecimal(40, 4)))
+	assert.Nil(t, tbl.AddRow("123.4567", 1))
+
+	columns := tbl.GetColumnsSchema()
+	assert.Len(t, columns, 2)
+	assert.Equal(t, &gpb.DecimalTypeExtension{Precision: 38, Scale: 4}, columns[0].DatatypeExtension.GetDecimalType())
+	assert.Equal(t, &gpb.DecimalTypeExtension{Precision: 38, Scale: 10}, columns[1].DatatypeExtension.GetDecimalType())
+
+	values := tbl.GetRows().Rows[0].Values
+	assert.Equal(t, &gpb.Decimal128{Lo: 1234567}, values[0].GetDecimal128Value())
+	assert.Equal(t, &gpb.Decimal128{Lo: 10000000000}, values[1].GetDecimal128Value())
+
+	defs := tbl.ToColumnDefs()
+	assert.Equal(t, columns[0].DatatypeExtension, defs[0].DatatypeExtension)
+
+	// the same column with different precision and scale conflicts
+	other, err := New("bill")
+	assert.Nil(t, err)
+	assert.Nil(t, other.AddFieldColumn("price", types.DECIMAL128, WithDecimal(10, 2)))
+	assert.Nil(t, other.AddRow("1.5"))
+	assert.NotNil(t, tbl.Merge(other))
+}
+
+func TestJSONBinaryColumn(t *testing.T) {
+	tbl, err := New("event")
+	assert.Nil(t, err)
+	assert.Nil(t, tbl.AddFieldColumn("attrs", types.JSONB))
+	assert.Nil(t, tbl.AddFieldColumn("labels", types.JSON, WithJSONBinary()))
+	assert.Nil(t, tbl.AddFieldColumn("text", types.JSON))
+	assert.NotNil(t, tbl.AddFieldColumn("bytes", types.BINARY, WithJSONBinary()))
+	assert.Nil(t, tbl.AddRow(map[string]any{"a": 1}, []string{"x"}, map[string]any{"a": 1}))
+
+	columns := tbl.GetColumnsSchema()
+	assert.Len(t, columns, 3)
+	for _, column := range columns[:2] {
+		assert.Equal(t, gpb.ColumnDataType_BINARY, column.Datatype)
+		assert.Equal(t, gpb.JsonTypeExtension_JSON_BINARY, column.DatatypeExtension.GetJsonType())
+	}
+	assert.Equal(t, gpb.ColumnDataType_JSON, columns[2].Datatype)
+	assert.Nil(t, columns[2].DatatypeExtension)
+
+	values := tbl.GetRows().Rows[0].Values
+	assert.NotEmpty(t, values[0].GetBinaryValue())
+	assert.NotEmpty(t, values[1].GetBinaryValue())
+	assert.Equal(t, `{"a":1}`, values[2].GetStringValue())
+}
+
+func TestToCreateTableExpr(t *testing.T) {
+	tbl, err := New("Monitor")
+	assert.Nil(t, err)
+
+	_, err = tbl.ToCreateTableExpr()
+	assert.ErrorIs(t, err, errs.ErrEmptyColumn)
+
+	assert.Nil(t, tbl.AddTagColumn("host", types.STRING))
+	assert.Nil(t, tbl.AddTagColumn("region", types.STRING))
+	assert.Nil(t, tbl.AddFieldColumn("cpu", types.FLOAT64))
+
+	_, err = tbl.ToCreateTableExpr()
+	assert.ErrorIs(t, err, errs.ErrEmptyTimeIndex)
+
+	assert.Nil(t, tbl.AddTimestampColumn("ts", types.TIMESTAMP_MILLISECOND))
+	expr, err := tbl.ToCreateTableExpr()
+	assert.Nil(t, err)
+	assert.Equal(t, "monitor", expr.TableName)
+	assert.Equal(t, "ts", expr.TimeIndex)
+	assert.Equal(t, []string{"host", "region"}, expr.PrimaryKeys)
+	assert.Len(t, expr.ColumnDefs, 4)
+	assert.Equal(t, &gpb.ColumnDef{
+		Name:         "cpu",
+		DataType:     gpb.ColumnDataType_FLOAT64,
+		IsNullable:   true,
+		SemanticType: gpb.SemanticType_FIELD,
+	}, expr.ColumnDefs[2])
+	assert.False(t, expr.ColumnDefs[3].IsNullable)
+
+	assert.Nil(t, tbl.AddTimestampColumn("ts2", types.TIMESTAMP_MILLISECOND))
+	_, err = tbl.ToCreateTableExpr()
+	assert.ErrorIs(t, err, errs.ErrMultipleTimeIndex)
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"fmt"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+)
+
+// The precision is the total number of digits, and the scale is the number of
+// digits after the decimal point. The defaults are the same as DECIMAL in GreptimeDB.
+const (
+	MaxDecimalPrecision     int32 = 38
+	DefaultDecimalPrecision int32 = 38
+	DefaultDecimalScale     int32 = 10
+)
+
+// DecimalExtension builds the datatype extension of the DECIMAL128 column.
+func DecimalExtension(precision, scale int32) (*gpb.ColumnDataTypeExtension, error) {
+	if precision < 1 || precision > MaxDecimalPrecision {
+		return nil, fmt.Errorf("decimal precision %d is out of range [1, %d]", precision, MaxDecimalPrecision)
+	}
+	if scale < 0 || scale > precision {
+		return nil, fmt.Errorf("decimal scale %d is out of range [0, %d]", scale, precision)
+	}
+
+	return &gpb.ColumnDataTypeExtension{
+		TypeExt: &gpb.ColumnDataTypeExtension_DecimalType{
+			DecimalType: &gpb.DecimalTypeExtension{Precision: precision, Scale: scale},
+		},
+	}, nil
+}
+
+// DecimalPrecisionScale returns the precision and scale of the extension, or the
+// defaults if the extension is not for decimal.
+func DecimalPrecisionScale(ext *gpb.ColumnDataTypeExtension) (int32, int32) {
+	if decimal := ext.GetDecimalType(); decimal != nil {
+		return decimal.Precision, decimal.Scale
+	}
+	return DefaultDecimalPrecision, DefaultDecimalScale
+}
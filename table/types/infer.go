@@ -0,0 +1,70 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// InferColumnType infers the column type from the Go value. Integers and floats keep
+// their width, time.Time is TIMESTAMP_MILLISECOND, and maps and slices other than
+// []byte are JSON. The column type of Valuer is its own, and nil can not be inferred.
+func InferColumnType(v any) (ColumnType, error) {
+	if valuer, ok := v.(Valuer); ok {
+		_, type_ := valuer.GreptimeValue()
+		return type_, nil
+	}
+
+	switch v.(type) {
+	case bool, *bool:
+		return BOOLEAN, nil
+	case int8, *int8:
+		return INT8, nil
+	case int16, *int16:
+		return INT16, nil
+	case int32, *int32:
+		return INT32, nil
+	case int, int64, *int, *int64:
+		return INT64, nil
+	case uint8, *uint8:
+		return UINT8, nil
+	case uint16, *uint16:
+		return UINT16, nil
+	case uint32, *uint32:
+		return UINT32, nil
+	case uint, uint64, *uint, *uint64:
+		return UINT64, nil
+	case float32, *float32:
+		return FLOAT32, nil
+	case float64, *float64:
+		return FLOAT64, nil
+	case string, *string:
+		return STRING, nil
+	case []byte, *[]byte:
+		return BINARY, nil
+	case time.Time, *time.Time:
+		return TIMESTAMP_MILLISECOND, nil
+	case Interval, *Interval:
+		return INTERVAL_MONTH_DAY_NANO, nil
+	case map[string]any, []any, json.RawMessage:
+		return JSON, nil
+	default:
+		return 0, fmt.Errorf("failed to infer the column type of %T", v)
+	}
+}
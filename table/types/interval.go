@@ -0,0 +1,42 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// Interval is the value of the INTERVAL columns. Months and Days are calendar based,
+// so they can not be converted into time.Duration exactly.
+//
+//	// 1 month 2 days 3 hours
+//	types.Interval{Months: 1, Days: 2, Nanoseconds: int64(3 * time.Hour)}
+type Interval struct {
+	Months      int32
+	Days        int32
+	Nanoseconds int64
+}
+
+// IntervalOf converts the duration into the Interval without Months and Days.
+func IntervalOf(d time.Duration) Interval {
+	return Interval{Nanoseconds: int64(d)}
+}
+
+func (i Interval) String() string {
+	return fmt.Sprintf("%d months %d days %s", i.Months, i.Days, time.Duration(i.Nanoseconds))
+}
@@ -0,0 +1,35 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+)
+
+// JSONBinaryExtension marks the BINARY column as the JSON column, whose values are
+// encoded in the binary JSONB format of GreptimeDB.
+func JSONBinaryExtension() *gpb.ColumnDataTypeExtension {
+	return &gpb.ColumnDataTypeExtension{
+		TypeExt: &gpb.ColumnDataTypeExtension_JsonType{JsonType: gpb.JsonTypeExtension_JSON_BINARY},
+	}
+}
+
+// IsJSONBinary reports whether the extension is from JSONBinaryExtension.
+func IsJSONBinary(ext *gpb.ColumnDataTypeExtension) bool {
+	_, ok := ext.GetTypeExt().(*gpb.ColumnDataTypeExtension_JsonType)
+	return ok
+}
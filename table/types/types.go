@@ -51,6 +51,22 @@ func ParseTimestampPrecision(precision string) gpb.ColumnDataType {
 	}
 }
 
+// ParseTimePrecision is like ParseTimestampPrecision, but for the TIME column.
+func ParseTimePrecision(precision string) gpb.ColumnDataType {
+	switch strings.ToLower(precision) {
+	case SECOND.String():
+		return gpb.ColumnDataType_TIME_SECOND
+	case MILLISECOND.String():
+		return gpb.ColumnDataType_TIME_MILLISECOND
+	case MICROSECOND.String():
+		return gpb.ColumnDataType_TIME_MICROSECOND
+	case NANOSECOND.String():
+		return gpb.ColumnDataType_TIME_NANOSECOND
+	default:
+		return gpb.ColumnDataType_TIME_MILLISECOND
+	}
+}
+
 type ColumnType int
 
 // DO NOT CHANGE THE ORDER OF THESE CONSTANTS
@@ -59,33 +75,33 @@ type ColumnType int
 //
 // ColumnType has richer types than ColumnDataType in protocol buffer
 const (
-	BOOLEAN               ColumnType = 0
-	INT8                  ColumnType = 1
-	INT16                 ColumnType = 2
-	INT32                 ColumnType = 3
-	INT64                 ColumnType = 4
-	UINT8                 ColumnType = 5
-	UINT16                ColumnType = 6
-	UINT32                ColumnType = 7
-	UINT64                ColumnType = 8
-	FLOAT32               ColumnType = 9
-	FLOAT64               ColumnType = 10
-	BINARY                ColumnType = 11
-	STRING                ColumnType = 12
-	DATE                  ColumnType = 13
-	DATETIME              ColumnType = 14
-	TIMESTAMP_SECOND      ColumnType = 15
-	TIMESTAMP_MILLISECOND ColumnType = 16
-	TIMESTAMP_MICROSECOND ColumnType = 17
-	TIMESTAMP_NANOSECOND  ColumnType = 18
-	// TIME_SECOND             ColumnType = 19
-	// TIME_MILLISECOND        ColumnType = 20
-	// TIME_MICROSECOND        ColumnType = 21
-	// TIME_NANOSECOND         ColumnType = 22
-	// INTERVAL_YEAR_MONTH     ColumnType = 23
-	// INTERVAL_DAY_TIME       ColumnType = 24
-	// INTERVAL_MONTH_DAY_NANO ColumnType = 25
-	// DECIMAL128              ColumnType = 30
+	BOOLEAN                 ColumnType = 0
+	INT8                    ColumnType = 1
+	INT16                   ColumnType = 2
+	INT32                   ColumnType = 3
+	INT64                   ColumnType = 4
+	UINT8                   ColumnType = 5
+	UINT16                  ColumnType = 6
+	UINT32                  ColumnType = 7
+	UINT64                  ColumnType = 8
+	FLOAT32                 ColumnType = 9
+	FLOAT64                 ColumnType = 10
+	BINARY                  ColumnType = 11
+	STRING                  ColumnType = 12
+	DATE                    ColumnType = 13
+	DATETIME                ColumnType = 14
+	TIMESTAMP_SECOND        ColumnType = 15
+	TIMESTAMP_MILLISECOND   ColumnType = 16
+	TIMESTAMP_MICROSECOND   ColumnType = 17
+	TIMESTAMP_NANOSECOND    ColumnType = 18
+	TIME_SECOND             ColumnType = 19
+	TIME_MILLISECOND        ColumnType = 20
+	TIME_MICROSECOND        ColumnType = 21
+	TIME_NANOSECOND         ColumnType = 22
+	INTERVAL_YEAR_MONTH     ColumnType = 23
+	INTERVAL_DAY_TIME       ColumnType = 24
+	INTERVAL_MONTH_DAY_NANO ColumnType = 25
+	DECIMAL128              ColumnType = 30
 
 	JSON ColumnType = 31
 
@@ -96,6 +112,9 @@ const (
 	TIMESTAMP ColumnType = 104
 	BYTES     ColumnType = 105 // eq BINARY
 	BOOL      ColumnType = 106 // eq BOOLEAN
+	INTERVAL  ColumnType = 107 // eq INTERVAL_MONTH_DAY_NANO
+	TIME      ColumnType = 108 // eq TIME_MILLISECOND
+	JSONB     ColumnType = 109 // JSON in binary encoding
 )
 
 func (type_ ColumnType) String() string {
@@ -150,8 +169,30 @@ func (type_ ColumnType) String() string {
 		return "TIMESTAMP_MICROSECOND"
 	case TIMESTAMP_NANOSECOND:
 		return "TIMESTAMP_NANOSECOND"
+	case TIME:
+		return "TIME"
+	case TIME_SECOND:
+		return "TIME_SECOND"
+	case TIME_MILLISECOND:
+		return "TIME_MILLISECOND"
+	case TIME_MICROSECOND:
+		return "TIME_MICROSECOND"
+	case TIME_NANOSECOND:
+		return "TIME_NANOSECOND"
+	case INTERVAL:
+		return "INTERVAL"
+	case INTERVAL_YEAR_MONTH:
+		return "INTERVAL_YEAR_MONTH"
+	case INTERVAL_DAY_TIME:
+		return "INTERVAL_DAY_TIME"
+	case INTERVAL_MONTH_DAY_NANO:
+		return "INTERVAL_MONTH_DAY_NANO"
+	case DECIMAL128:
+		return "DECIMAL128"
 	case JSON:
 		return "JSON"
+	case JSONB:
+		return "JSONB"
 	default:
 		return "UNKNOWN"
 	}
@@ -199,8 +240,28 @@ func ParseColumnType(type_, precision string) (gpb.ColumnDataType, error) {
 		return gpb.ColumnDataType_TIMESTAMP_MICROSECOND, nil
 	case TIMESTAMP_NANOSECOND.String():
 		return gpb.ColumnDataType_TIMESTAMP_NANOSECOND, nil
+	case TIME.String():
+		return ParseTimePrecision(precision), nil
+	case TIME_SECOND.String():
+		return gpb.ColumnDataType_TIME_SECOND, nil
+	case TIME_MILLISECOND.String():
+		return gpb.ColumnDataType_TIME_MILLISECOND, nil
+	case TIME_MICROSECOND.String():
+		return gpb.ColumnDataType_TIME_MICROSECOND, nil
+	case TIME_NANOSECOND.String():
+		return gpb.ColumnDataType_TIME_NANOSECOND, nil
+	case INTERVAL_YEAR_MONTH.String():
+		return gpb.ColumnDataType_INTERVAL_YEAR_MONTH, nil
+	case INTERVAL_DAY_TIME.String():
+		return gpb.ColumnDataType_INTERVAL_DAY_TIME, nil
+	case INTERVAL_MONTH_DAY_NANO.String(), INTERVAL.String():
+		return gpb.ColumnDataType_INTERVAL_MONTH_DAY_NANO, nil
+	case DECIMAL128.String():
+		return gpb.ColumnDataType_DECIMAL128, nil
 	case JSON.String():
 		return gpb.ColumnDataType_JSON, nil
+	case JSONB.String():
+		return gpb.ColumnDataType_BINARY, nil
 	default:
 		return 0, fmt.Errorf("parse: unsupported column type %q", type_)
 	}
@@ -246,8 +307,26 @@ func ConvertType(type_ ColumnType) (gpb.ColumnDataType, error) {
 		return gpb.ColumnDataType_TIMESTAMP_MICROSECOND, nil
 	case TIMESTAMP_NANOSECOND:
 		return gpb.ColumnDataType_TIMESTAMP_NANOSECOND, nil
+	case TIME_SECOND:
+		return gpb.ColumnDataType_TIME_SECOND, nil
+	case TIME_MILLISECOND, TIME:
+		return gpb.ColumnDataType_TIME_MILLISECOND, nil
+	case TIME_MICROSECOND:
+		return gpb.ColumnDataType_TIME_MICROSECOND, nil
+	case TIME_NANOSECOND:
+		return gpb.ColumnDataType_TIME_NANOSECOND, nil
+	case INTERVAL_YEAR_MONTH:
+		return gpb.ColumnDataType_INTERVAL_YEAR_MONTH, nil
+	case INTERVAL_DAY_TIME:
+		return gpb.ColumnDataType_INTERVAL_DAY_TIME, nil
+	case INTERVAL_MONTH_DAY_NANO, INTERVAL:
+		return gpb.ColumnDataType_INTERVAL_MONTH_DAY_NANO, nil
+	case DECIMAL128:
+		return gpb.ColumnDataType_DECIMAL128, nil
 	case JSON:
 		return gpb.ColumnDataType_JSON, nil
+	case JSONB:
+		return gpb.ColumnDataType_BINARY, nil
 	default:
 		return 0, fmt.Errorf("convert: unsupported column type %q", type_.String())
 	}
@@ -0,0 +1,31 @@
+/*
+ * Copyright 2024 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+// Valuer is implemented by the custom types to be written into GreptimeDB, like the IDs
+// and the enums of the domain. GreptimeValue returns the value to be written, and the
+// column type used if the type of the column is not set, e.g. in the struct tag. The
+// column type MUST NOT depend on the receiver, which may be the zero value.
+//
+//	type Level int
+//
+//	func (l Level) GreptimeValue() (any, types.ColumnType) {
+//		return levelNames[l], types.STRING
+//	}
+type Valuer interface {
+	GreptimeValue() (any, ColumnType)
+}